@@ -20,6 +20,7 @@ limitations under the License.
 package batcher
 
 import (
+	"context"
 	"errors"
 	"time"
 )
@@ -46,6 +47,17 @@ func (m *mutex) Unlock() {
 	<-m.lock
 }
 
+// LockContext acquires the lock unless ctx is done first, returning a
+// bool indicating if the lock was acquired.
+func (m *mutex) LockContext(ctx context.Context) bool {
+	select {
+	case m.lock <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (m *mutex) TryLock() bool {
 	select {
 	case m.lock <- struct{}{}:
@@ -80,6 +92,20 @@ type Batcher interface {
 	IsDisposed() bool
 }
 
+// ContextBatcher is a Batcher whose Puts can be abandoned and whose
+// metrics can be inspected.  The Batchers returned by New and
+// NewBatcher implement it.
+type ContextBatcher interface {
+	Batcher
+
+	// PutContext adds items to the batcher.  If the batcher blocks on
+	// overflow, this gives up and returns ctx.Err() once ctx is done.
+	PutContext(context.Context, interface{}) error
+
+	// Stats returns a snapshot of the batcher's metrics
+	Stats() Stats
+}
+
 // ErrDisposed is the error returned for a disposed Batcher
 var ErrDisposed = errors.New("batcher: disposed")
 
@@ -96,6 +122,7 @@ type basicBatcher struct {
 	batchChan      chan []interface{}
 	availableBytes uint
 	lock           *mutex
+	pressure       *pressure
 }
 
 // New creates a new Batcher using the provided arguments.
@@ -109,7 +136,10 @@ type basicBatcher struct {
 // thread places two items in the batcher, Get will guarantee the first
 // item is returned before the second, whether before the second in the same
 // batch, or in an earlier batch.
-func New(maxTime time.Duration, maxItems, maxBytes, queueLen uint, calculate CalculateBytes) (Batcher, error) {
+// Options such as WithOverflowPolicy and WithObserver may be provided to
+// control what happens when the queue of completed batches is full.
+func New(maxTime time.Duration, maxItems, maxBytes, queueLen uint, calculate CalculateBytes,
+	options ...Option) (Batcher, error) {
 	// 按字节长度取数据时，必须提供计算长度的方法
 	if maxBytes > 0 && calculate == nil {
 		return nil, errors.New("batcher: must provide CalculateBytes function")
@@ -123,13 +153,22 @@ func New(maxTime time.Duration, maxItems, maxBytes, queueLen uint, calculate Cal
 		items:          make([]interface{}, 0, maxItems),
 		batchChan:      make(chan []interface{}, queueLen),
 		lock:           newMutex(),
+		pressure:       newPressure(mergeOptions(options...)),
 	}, nil
 }
 
 // Put adds items to the batcher.
 // 往队列里加入一条数据
 func (b *basicBatcher) Put(item interface{}) error {
-	b.lock.Lock()
+	return b.PutContext(context.Background(), item)
+}
+
+// PutContext adds items to the batcher, giving up once ctx is done if
+// the batcher blocks on overflow.
+func (b *basicBatcher) PutContext(ctx context.Context, item interface{}) error {
+	if !b.lock.LockContext(ctx) {
+		return ctx.Err()
+	}
 	if b.disposed { // 判断队列是否可用
 		b.lock.Unlock()
 		return ErrDisposed
@@ -137,16 +176,24 @@ func (b *basicBatcher) Put(item interface{}) error {
 
 	// 给当前的数组附加数据
 	b.items = append(b.items, item)
+	var size uint
 	if b.calculateBytes != nil { // 计算总长度
-		b.availableBytes += b.calculateBytes(item)
+		size = b.calculateBytes(item)
+		b.availableBytes += size
 	}
-	if b.ready() {
+	if reason, ok := b.ready(); ok {
 		// To guarantee ordering this MUST be in the lock, otherwise multiple
 		// flush calls could be blocked at the same time, in which case
 		// there's no guarantee each batch is placed into the channel in
 		// the proper order
 		// 已经满足条件，将数据存入输出队列，以备get
-		b.flush()
+		if err := b.flush(ctx, reason); err != nil {
+			// The batch could not be queued, so the item is not accepted
+			b.items = b.items[:len(b.items)-1]
+			b.availableBytes -= size
+			b.lock.Unlock()
+			return err
+		}
 	}
 
 	b.lock.Unlock()
@@ -202,6 +249,7 @@ func (b *basicBatcher) Get() ([]interface{}, error) {
 				items := b.items
 				b.items = make([]interface{}, 0, b.maxItems)
 				b.availableBytes = 0
+				b.pressure.completed(FlushTime, len(items))
 				b.lock.Unlock()
 				return items, nil
 			} else {
@@ -233,9 +281,9 @@ func (b *basicBatcher) Flush() error {
 		b.lock.Unlock()
 		return ErrDisposed
 	}
-	b.flush()
+	err := b.flush(context.Background(), FlushManual)
 	b.lock.Unlock()
-	return nil
+	return err
 }
 
 // Dispose will dispose of the batcher. Any calls to Put or Flush
@@ -278,27 +326,36 @@ func (b *basicBatcher) IsDisposed() bool {
 	return disposed
 }
 
+// Stats returns a snapshot of the batcher's metrics
+func (b *basicBatcher) Stats() Stats {
+	return b.pressure.snapshot(len(b.batchChan))
+}
+
 // flush adds the batch currently being built to the queue of completed batches.
+// If the batch could not be queued it is left in place and an error returned.
 // flush is not threadsafe, so should be synchronized externally.
 // flush 将数组输出到缓冲区
-func (b *basicBatcher) flush() {
-	b.batchChan <- b.items
+func (b *basicBatcher) flush(ctx context.Context, reason FlushReason) error {
+	if err := enqueue(b.pressure, ctx, b.batchChan, b.items, reason); err != nil {
+		return err
+	}
 	// 重新初始化
 	b.items = make([]interface{}, 0, b.maxItems)
 	b.availableBytes = 0
+	return nil
 }
 
 // ready 判断是否已经满足出列的条件
-func (b *basicBatcher) ready() bool {
+func (b *basicBatcher) ready() (FlushReason, bool) {
 	// 按数量判断
 	if b.maxItems != 0 && uint(len(b.items)) >= b.maxItems {
-		return true
+		return FlushItems, true
 	}
 	// 按字节数判断
 	if b.maxBytes != 0 && b.availableBytes >= b.maxBytes {
-		return true
+		return FlushBytes, true
 	}
-	return false
+	return 0, false
 }
 
 func (b *basicBatcher) drainBatchChan() {
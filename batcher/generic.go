@@ -0,0 +1,367 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// GenericBatcher accumulates items of type T into batches.  Batches are
+// completed by the same maxTime/maxItems/maxBytes triggers as the
+// Batcher returned by NewBatcher.  Batches can either be pulled with Get,
+// which honors context cancellation, or pushed to a Handler with Handle.
+// The type is named GenericBatcher as Batcher is taken by the untyped
+// interface.
+type GenericBatcher[T any] struct {
+	items     []T
+	batchChan chan []T
+	lock      *mutex
+	disposed  bool
+	arrayLen  uint
+	option    Option
+	pressure  *pressure
+}
+
+// Handler processes a single batch in push mode.  Returning an error
+// causes the batch to be retried according to the HandlerConfig.
+type Handler[T any] func(ctx context.Context, items []T) error
+
+// DeadLetter receives a batch that could not be processed along with
+// the last error returned for it.
+type DeadLetter[T any] func(items []T, err error)
+
+// Backoff returns how long to wait before the given retry attempt,
+// where the first retry is attempt 1.
+type Backoff func(attempt int) time.Duration
+
+// HandlerConfig configures how batches are delivered to a Handler.
+type HandlerConfig[T any] struct {
+	// Concurrency is the number of batches that may be handled at once.
+	// Batches handled concurrently may complete out of order.  Values
+	// below 1 are treated as 1.
+	Concurrency int
+	// MaxRetries is the number of times a failed batch is retried
+	// before it is given to DeadLetter.
+	MaxRetries int
+	// Backoff determines the delay between retries.  If nil, retries
+	// happen immediately.
+	Backoff Backoff
+	// DeadLetter, if not nil, receives every batch that still fails
+	// after MaxRetries retries or whose retries are cut short by the
+	// context being canceled.
+	DeadLetter DeadLetter[T]
+}
+
+// ExponentialBackoff returns a Backoff that starts at base and doubles
+// with every attempt, never exceeding max.
+func ExponentialBackoff(base, max time.Duration) Backoff {
+	return func(attempt int) time.Duration {
+		d := base
+		for i := 1; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+// NewGenericBatcher creates a GenericBatcher with room for queueLen
+// completed batches.  The provided options are combined, so
+// NewGenericBatcher[T](10, WithMaxItems(100), WithMaxTime(time.Second))
+// completes a batch at 100 items or after a second, whichever is first.
+func NewGenericBatcher[T any](queueLen uint, options ...Option) (*GenericBatcher[T], error) {
+	option := mergeOptions(options...)
+	if option.maxBytes > 0 && option.calculateBytes == nil {
+		return nil, errors.New("batcher: must provide CalculateBytes function")
+	}
+
+	return newGenericBatcher[T](queueLen, option), nil
+}
+
+// newGenericBatcher creates a GenericBatcher from already merged and
+// validated options.
+func newGenericBatcher[T any](queueLen uint, option Option) *GenericBatcher[T] {
+	var arrayLen uint = 1024
+	if option.maxItems > 0 {
+		arrayLen = option.maxItems
+	}
+	return &GenericBatcher[T]{
+		option:    option,
+		items:     make([]T, 0, arrayLen),
+		batchChan: make(chan []T, queueLen),
+		lock:      newMutex(),
+		arrayLen:  arrayLen,
+		pressure:  newPressure(option),
+	}
+}
+
+// mergeOptions combines options, with later non-zero fields taking
+// precedence.
+func mergeOptions(options ...Option) Option {
+	var merged Option
+	for _, o := range options {
+		if o.maxTime != 0 {
+			merged.maxTime = o.maxTime
+		}
+		if o.maxItems != 0 {
+			merged.maxItems = o.maxItems
+		}
+		if o.maxBytes != 0 {
+			merged.maxBytes = o.maxBytes
+		}
+		if o.calculateBytes != nil {
+			merged.calculateBytes = o.calculateBytes
+		}
+		if o.overflow != OverflowBlock {
+			merged.overflow = o.overflow
+		}
+		if o.observer != nil {
+			merged.observer = o.observer
+		}
+	}
+	return merged
+}
+
+// Put adds an item to the batcher.  If the item completes a batch and
+// the queue of completed batches is full, the batcher's OverflowPolicy
+// decides what happens.
+func (b *GenericBatcher[T]) Put(item T) error {
+	return b.PutContext(context.Background(), item)
+}
+
+// PutContext adds an item to the batcher, giving up once ctx is done if
+// the batcher blocks on overflow.
+func (b *GenericBatcher[T]) PutContext(ctx context.Context, item T) error {
+	if !b.lock.LockContext(ctx) {
+		return ctx.Err()
+	}
+	if b.disposed {
+		b.lock.Unlock()
+		return ErrDisposed
+	}
+
+	b.items = append(b.items, item)
+	var size uint
+	if b.option.calculateBytes != nil {
+		size = b.option.calculateBytes(item)
+		b.option.availableBytes += size
+	}
+	if reason, ok := b.ready(); ok {
+		if err := b.flush(ctx, reason); err != nil {
+			var zero T
+			b.items[len(b.items)-1] = zero
+			b.items = b.items[:len(b.items)-1]
+			b.option.availableBytes -= size
+			b.lock.Unlock()
+			return err
+		}
+	}
+	b.lock.Unlock()
+	return nil
+}
+
+// Get retrieves a batch from the batcher.  This call will block until
+// one of the conditions for a complete batch is reached, the batcher
+// is disposed or ctx is done, in which case ctx.Err() is returned.
+func (b *GenericBatcher[T]) Get(ctx context.Context) ([]T, error) {
+	var timeout <-chan time.Time
+	if b.option.maxTime > 0 {
+		timer := time.NewTimer(b.option.maxTime)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case items, ok := <-b.batchChan:
+		if !ok {
+			return nil, ErrDisposed
+		}
+		return items, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout:
+		// A Put blocked on a full queue holds the lock until a batch is
+		// taken, so wait for whichever comes first rather than the lock
+		// alone.
+		select {
+		case b.lock.lock <- struct{}{}:
+		case items, ok := <-b.batchChan:
+			if !ok {
+				return nil, ErrDisposed
+			}
+			return items, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		select {
+		case items, ok := <-b.batchChan:
+			b.lock.Unlock()
+			if !ok {
+				return nil, ErrDisposed
+			}
+			return items, nil
+		default:
+		}
+
+		items := b.items
+		b.items = make([]T, 0, b.arrayLen)
+		b.option.availableBytes = 0
+		b.pressure.completed(FlushTime, len(items))
+		b.lock.Unlock()
+		return items, nil
+	}
+}
+
+// Handle runs the batcher in push mode, calling handler for every
+// non-empty batch until ctx is done or the batcher is disposed.  A
+// failed batch is retried according to config and then given to
+// config.DeadLetter.  Handle blocks until all in-flight batches have
+// been handled, returning ctx.Err() if ctx ended the run and nil if the
+// batcher was disposed.
+func (b *GenericBatcher[T]) Handle(ctx context.Context, handler Handler[T], config HandlerConfig[T]) error {
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrency)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				items, err := b.Get(ctx)
+				if err != nil {
+					if err != ErrDisposed {
+						errs <- err
+					}
+					return
+				}
+				if len(items) > 0 {
+					b.deliver(ctx, handler, config, items)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// deliver hands a single batch to handler, retrying as configured.
+func (b *GenericBatcher[T]) deliver(ctx context.Context, handler Handler[T], config HandlerConfig[T], items []T) {
+	err := handler(ctx, items)
+	for attempt := 1; err != nil && attempt <= config.MaxRetries; attempt++ {
+		var delay time.Duration
+		if config.Backoff != nil {
+			delay = config.Backoff(attempt)
+		}
+		if !sleep(ctx, delay) {
+			break
+		}
+		err = handler(ctx, items)
+	}
+
+	if err != nil && config.DeadLetter != nil {
+		config.DeadLetter(items, err)
+	}
+}
+
+// sleep waits for d to elapse, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Flush forcibly completes the batch currently being built.
+func (b *GenericBatcher[T]) Flush() error {
+	b.lock.Lock()
+	if b.disposed {
+		b.lock.Unlock()
+		return ErrDisposed
+	}
+	err := b.flush(context.Background(), FlushManual)
+	b.lock.Unlock()
+	return err
+}
+
+// Dispose will dispose of the batcher.  Any calls to Put or Flush will
+// return ErrDisposed, calls to Get will return an error iff there are
+// no more ready batches.  Any items not yet retrieved by Get or Handle
+// may or may not be retrievable after calling this.
+func (b *GenericBatcher[T]) Dispose() {
+	// Drain batches until the lock is free so a Put blocked on a full
+	// queue can finish and release it.
+	for {
+		select {
+		case b.lock.lock <- struct{}{}:
+			if !b.disposed {
+				b.disposed = true
+				b.items = nil
+				b.drainBatchChan()
+				close(b.batchChan)
+			}
+			b.lock.Unlock()
+			return
+		case <-b.batchChan:
+		}
+	}
+}
+
+// IsDisposed will determine if the batcher is disposed
+func (b *GenericBatcher[T]) IsDisposed() bool {
+	b.lock.Lock()
+	disposed := b.disposed
+	b.lock.Unlock()
+	return disposed
+}
+
+// Stats returns a snapshot of the batcher's metrics
+func (b *GenericBatcher[T]) Stats() Stats {
+	return b.pressure.snapshot(len(b.batchChan))
+}
+
+// flush leaves the batch in place and returns an error if it could not
+// be queued.  flush is not threadsafe, so should be synchronized
+// externally.
+func (b *GenericBatcher[T]) flush(ctx context.Context, reason FlushReason) error {
+	if err := enqueue(b.pressure, ctx, b.batchChan, b.items, reason); err != nil {
+		return err
+	}
+	b.items = make([]T, 0, b.arrayLen)
+	b.option.availableBytes = 0
+	return nil
+}
+
+func (b *GenericBatcher[T]) ready() (FlushReason, bool) {
+	if b.option.maxItems != 0 && uint(len(b.items)) >= b.option.maxItems {
+		return FlushItems, true
+	}
+	if b.option.maxBytes != 0 && b.option.availableBytes >= b.option.maxBytes {
+		return FlushBytes, true
+	}
+	return 0, false
+}
+
+func (b *GenericBatcher[T]) drainBatchChan() {
+	for {
+		select {
+		case <-b.batchChan:
+		default:
+			return
+		}
+	}
+}
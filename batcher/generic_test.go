@@ -0,0 +1,239 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericNoCalculateBytes(t *testing.T) {
+	_, err := NewGenericBatcher[string](5, WithMaxBytes(100, nil))
+	assert.Error(t, err)
+}
+
+func TestGenericMaxItems(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[int](10, WithMaxItems(100))
+	assert.Nil(err)
+
+	for i := 0; i < 1000; i++ {
+		assert.Nil(b.Put(i))
+	}
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Len(batch, 100)
+	assert.Equal(0, batch[0])
+	assert.Equal(99, batch[99])
+}
+
+func TestGenericCombinedOptions(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10,
+		WithMaxItems(1000),
+		WithMaxBytes(10, func(str interface{}) uint {
+			return uint(len(str.(string)))
+		}),
+		WithMaxTime(time.Hour),
+	)
+	assert.Nil(err)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(b.Put("ab"))
+	}
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Len(batch, 5)
+}
+
+func TestGenericMaxTime(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxTime(50*time.Millisecond))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a"))
+	assert.Nil(b.Put("b"))
+
+	start := time.Now()
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal([]string{"a", "b"}, batch)
+	assert.True(time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestGenericGetContext(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxItems(10))
+	assert.Nil(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	batch, err := b.Get(ctx)
+	assert.Nil(batch)
+	assert.Equal(context.DeadlineExceeded, err)
+}
+
+func TestGenericDispose(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxItems(2))
+	assert.Nil(err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		b.Dispose()
+	}()
+
+	_, err = b.Get(context.Background())
+	assert.Equal(ErrDisposed, err)
+	assert.True(b.IsDisposed())
+	assert.Equal(ErrDisposed, b.Put("a"))
+	assert.Equal(ErrDisposed, b.Flush())
+	b.Dispose()
+}
+
+func TestGenericFlush(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxItems(10))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a"))
+	assert.Nil(b.Flush())
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal([]string{"a"}, batch)
+}
+
+func TestGenericHandle(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[int](100, WithMaxItems(10))
+	assert.Nil(err)
+
+	var lock sync.Mutex
+	seen := make([]int, 0, 100)
+	var calls, inFlight, maxInFlight int32
+
+	done := make(chan error)
+	go func() {
+		done <- b.Handle(context.Background(), func(ctx context.Context, items []int) error {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+			atomic.AddInt32(&calls, 1)
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			seen = append(seen, items...)
+			lock.Unlock()
+			return nil
+		}, HandlerConfig[int]{Concurrency: 4})
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.Nil(b.Put(i))
+	}
+
+	assert.Eventually(func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(seen) == 100
+	}, time.Second, time.Millisecond)
+
+	b.Dispose()
+	assert.Nil(<-done)
+	assert.Equal(int32(10), atomic.LoadInt32(&calls))
+	assert.True(atomic.LoadInt32(&maxInFlight) > 1)
+	assert.True(atomic.LoadInt32(&maxInFlight) <= 4)
+}
+
+func TestGenericHandleRetries(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxItems(1))
+	assert.Nil(err)
+
+	failure := errors.New("failure")
+	attempts := map[string]int{}
+	dead := make(chan []string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+	go func() {
+		done <- b.Handle(ctx, func(ctx context.Context, items []string) error {
+			attempts[items[0]]++
+			if items[0] == "ok" && attempts[items[0]] < 3 {
+				return failure
+			}
+			if items[0] == "bad" {
+				return failure
+			}
+			return nil
+		}, HandlerConfig[string]{
+			MaxRetries: 3,
+			Backoff:    ExponentialBackoff(time.Millisecond, 4*time.Millisecond),
+			DeadLetter: func(items []string, err error) {
+				assert.Equal(failure, err)
+				dead <- items
+			},
+		})
+	}()
+
+	assert.Nil(b.Put("ok"))
+	assert.Nil(b.Put("bad"))
+
+	assert.Equal([]string{"bad"}, <-dead)
+	cancel()
+	assert.Equal(context.Canceled, <-done)
+	assert.Equal(3, attempts["ok"])
+	assert.Equal(4, attempts["bad"])
+}
+
+func TestGenericHandleCanceledRetry(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewGenericBatcher[string](10, WithMaxItems(1))
+	assert.Nil(err)
+
+	failure := errors.New("failure")
+	ctx, cancel := context.WithCancel(context.Background())
+	dead := make(chan []string, 1)
+
+	done := make(chan error)
+	go func() {
+		done <- b.Handle(ctx, func(ctx context.Context, items []string) error {
+			cancel()
+			return failure
+		}, HandlerConfig[string]{
+			MaxRetries: 100,
+			Backoff:    ExponentialBackoff(time.Hour, time.Hour),
+			DeadLetter: func(items []string, err error) {
+				dead <- items
+			},
+		})
+	}()
+
+	assert.Nil(b.Put("a"))
+	assert.Equal([]string{"a"}, <-dead)
+	assert.Equal(context.Canceled, <-done)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert := assert.New(t)
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	assert.Equal(10*time.Millisecond, backoff(1))
+	assert.Equal(20*time.Millisecond, backoff(2))
+	assert.Equal(40*time.Millisecond, backoff(3))
+	assert.Equal(50*time.Millisecond, backoff(4))
+	assert.Equal(50*time.Millisecond, backoff(100))
+}
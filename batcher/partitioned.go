@@ -0,0 +1,389 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// KeyedBatch is a completed batch along with the key of the partition
+// it was built in.
+type KeyedBatch[K comparable, T any] struct {
+	Key   K
+	Items []T
+}
+
+// PartitionConfig holds the limits a PartitionedBatcher applies across
+// all of its partitions.  Zero values disable the respective limit.
+type PartitionConfig struct {
+	// MaxTotalBytes bounds the bytes held by the batcher, counting both
+	// incomplete batches and completed batches not yet taken by Get.
+	// When a Put would exceed it, the largest partitions are flushed
+	// until the incomplete batches fit, after which the Put blocks until
+	// Get has taken enough completed batches.  An item larger than the
+	// bound is only accepted once the batcher holds nothing else.
+	// Requires a CalculateBytes function to be provided with
+	// WithMaxBytes.
+	MaxTotalBytes uint
+	// IdleTimeout is how long a partition may go without a Put before
+	// it is evicted.  Any items in an evicted partition are flushed as
+	// a final batch.
+	IdleTimeout time.Duration
+}
+
+// queuedBatch is a completed batch along with the bytes it counts
+// toward MaxTotalBytes until Get takes it.
+type queuedBatch[K comparable, T any] struct {
+	batch KeyedBatch[K, T]
+	bytes uint
+}
+
+type partition[T any] struct {
+	items   []T
+	bytes   uint
+	lastPut time.Time
+	timer   *time.Timer
+	// generation is bumped every time the partition is flushed, so a
+	// maxTime timer can tell if the batch it was started for is gone.
+	generation uint64
+}
+
+// PartitionedBatcher routes items into separate batches by key, as if
+// running one batcher per key.  Every partition completes its batches
+// according to the same maxItems/maxBytes/maxTime options, where
+// maxTime is measured from the first item added to a batch.  Completed
+// batches from all partitions are queued for Get in the order they
+// complete.
+type PartitionedBatcher[K comparable, T any] struct {
+	option     Option
+	config     PartitionConfig
+	arrayLen   uint
+	partitions map[K]*partition[T]
+	// pendingBytes are held in incomplete batches and queuedBytes in
+	// completed batches waiting for Get.  queuedBytes and taken are
+	// guarded by bytesLock rather than lock, as a flush may hold lock
+	// while it waits for Get to make room in the queue.
+	pendingBytes uint
+	bytesLock    sync.Mutex
+	queuedBytes  uint
+	// taken is closed and replaced every time Get takes a batch, waking
+	// any Put waiting for queued bytes to be freed.
+	taken     chan struct{}
+	batchChan chan queuedBatch[K, T]
+	lock      *mutex
+	disposed  bool
+	done      chan struct{}
+}
+
+// NewPartitionedBatcher creates a PartitionedBatcher with room for
+// queueLen completed batches.  The provided options are combined and
+// apply to every partition.
+func NewPartitionedBatcher[K comparable, T any](queueLen uint, config PartitionConfig,
+	options ...Option) (*PartitionedBatcher[K, T], error) {
+
+	option := mergeOptions(options...)
+	if (option.maxBytes > 0 || config.MaxTotalBytes > 0) && option.calculateBytes == nil {
+		return nil, errors.New("batcher: must provide CalculateBytes function")
+	}
+	if option.overflow != OverflowBlock || option.observer != nil {
+		return nil, errors.New("batcher: overflow policies and observers are not supported when partitioning")
+	}
+
+	var arrayLen uint = 16
+	if option.maxItems > 0 {
+		arrayLen = option.maxItems
+	}
+
+	b := &PartitionedBatcher[K, T]{
+		option:     option,
+		config:     config,
+		arrayLen:   arrayLen,
+		partitions: make(map[K]*partition[T]),
+		taken:      make(chan struct{}),
+		batchChan:  make(chan queuedBatch[K, T], queueLen),
+		lock:       newMutex(),
+		done:       make(chan struct{}),
+	}
+
+	if config.IdleTimeout > 0 {
+		go b.evictIdle()
+	}
+	return b, nil
+}
+
+// Put adds item to the batch being built for key.  If this completes
+// a batch and the queue of completed batches is full, Put blocks until
+// there is room.  Put also blocks while adding item would exceed
+// MaxTotalBytes.
+func (b *PartitionedBatcher[K, T]) Put(key K, item T) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return ErrDisposed
+	}
+
+	var size uint
+	if b.option.calculateBytes != nil {
+		size = b.option.calculateBytes(item)
+	}
+	for b.config.MaxTotalBytes > 0 {
+		b.bytesLock.Lock()
+		total := b.pendingBytes + b.queuedBytes
+		taken := b.taken
+		b.bytesLock.Unlock()
+
+		if total == 0 || total+size <= b.config.MaxTotalBytes {
+			break
+		}
+		if b.pendingBytes > 0 && b.pendingBytes+size > b.config.MaxTotalBytes {
+			b.flushLargest()
+			continue
+		}
+
+		// wait for Get to free queued bytes
+		b.lock.Unlock()
+		select {
+		case <-taken:
+		case <-b.done:
+		}
+		b.lock.Lock()
+		if b.disposed {
+			return ErrDisposed
+		}
+	}
+
+	p, ok := b.partitions[key]
+	if !ok {
+		p = &partition[T]{items: make([]T, 0, b.arrayLen)}
+		b.partitions[key] = p
+	}
+
+	p.items = append(p.items, item)
+	p.lastPut = time.Now()
+	p.bytes += size
+	b.pendingBytes += size
+
+	if len(p.items) == 1 && b.option.maxTime > 0 {
+		generation := p.generation
+		p.timer = time.AfterFunc(b.option.maxTime, func() {
+			b.expire(key, p, generation)
+		})
+	}
+
+	if b.ready(p) {
+		b.flush(key, p)
+	}
+	return nil
+}
+
+// Get retrieves the next completed batch from any partition.  This call
+// will block until a batch is complete, the batcher is disposed or ctx
+// is done, in which case ctx.Err() is returned.
+func (b *PartitionedBatcher[K, T]) Get(ctx context.Context) (KeyedBatch[K, T], error) {
+	select {
+	case queued, ok := <-b.batchChan:
+		if !ok {
+			return KeyedBatch[K, T]{}, ErrDisposed
+		}
+		if queued.bytes > 0 {
+			b.release(queued.bytes)
+		}
+		return queued.batch, nil
+	case <-ctx.Done():
+		return KeyedBatch[K, T]{}, ctx.Err()
+	}
+}
+
+// Flush forcibly completes the batch being built for key, if any.
+func (b *PartitionedBatcher[K, T]) Flush(key K) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return ErrDisposed
+	}
+	if p, ok := b.partitions[key]; ok && len(p.items) > 0 {
+		b.flush(key, p)
+	}
+	return nil
+}
+
+// FlushAll forcibly completes the batches being built for every key.
+func (b *PartitionedBatcher[K, T]) FlushAll() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return ErrDisposed
+	}
+	for key, p := range b.partitions {
+		if len(p.items) > 0 {
+			b.flush(key, p)
+		}
+	}
+	return nil
+}
+
+// Partitions returns the number of partitions currently held.
+func (b *PartitionedBatcher[K, T]) Partitions() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.partitions)
+}
+
+// Dispose will dispose of the batcher.  Any calls to Put or Flush will
+// return ErrDisposed, calls to Get will return an error iff there are
+// no more ready batches.  Any items not yet retrieved by Get may or may
+// not be retrievable after calling this.
+func (b *PartitionedBatcher[K, T]) Dispose() {
+	for {
+		if b.lock.TryLock() {
+			if b.disposed {
+				b.lock.Unlock()
+				return
+			}
+			b.disposed = true
+			for _, p := range b.partitions {
+				if p.timer != nil {
+					p.timer.Stop()
+				}
+			}
+			b.partitions = nil
+			b.pendingBytes = 0
+			close(b.done)
+			b.drainBatchChan()
+			close(b.batchChan)
+			b.lock.Unlock()
+			return
+		}
+		b.drainBatchChan()
+	}
+}
+
+// IsDisposed will determine if the batcher is disposed
+func (b *PartitionedBatcher[K, T]) IsDisposed() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.disposed
+}
+
+// expire completes a batch whose maxTime has elapsed, unless that batch
+// has already been flushed.
+func (b *PartitionedBatcher[K, T]) expire(key K, p *partition[T], generation uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.disposed {
+		return
+	}
+	if current, ok := b.partitions[key]; ok && current == p && p.generation == generation {
+		b.flush(key, p)
+	}
+}
+
+// evictIdle periodically removes partitions that have not seen a Put
+// within the idle timeout.
+func (b *PartitionedBatcher[K, T]) evictIdle() {
+	interval := b.config.IdleTimeout / 2
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case now := <-ticker.C:
+			b.lock.Lock()
+			if b.disposed {
+				b.lock.Unlock()
+				return
+			}
+			for key, p := range b.partitions {
+				if now.Sub(p.lastPut) < b.config.IdleTimeout {
+					continue
+				}
+				if len(p.items) > 0 {
+					b.flush(key, p)
+				}
+				delete(b.partitions, key)
+			}
+			b.lock.Unlock()
+		}
+	}
+}
+
+// release frees the bytes of a batch taken off the queue and wakes any
+// Put waiting on MaxTotalBytes.  It must not take lock, which a flush
+// may be holding while it waits on the queue.
+func (b *PartitionedBatcher[K, T]) release(bytes uint) {
+	b.bytesLock.Lock()
+	defer b.bytesLock.Unlock()
+
+	b.queuedBytes -= bytes
+	close(b.taken)
+	b.taken = make(chan struct{})
+}
+
+// flushLargest flushes the partition holding the most bytes.
+func (b *PartitionedBatcher[K, T]) flushLargest() {
+	var (
+		largestKey K
+		largest    *partition[T]
+	)
+	for key, p := range b.partitions {
+		if largest == nil || p.bytes > largest.bytes {
+			largestKey, largest = key, p
+		}
+	}
+	b.flush(largestKey, largest)
+}
+
+// flush is not threadsafe, so should be synchronized externally.
+func (b *PartitionedBatcher[K, T]) flush(key K, p *partition[T]) {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	p.generation++
+
+	// the bytes are counted as queued before the send, so Get can never
+	// release them first
+	b.bytesLock.Lock()
+	b.queuedBytes += p.bytes
+	b.bytesLock.Unlock()
+
+	b.batchChan <- queuedBatch[K, T]{batch: KeyedBatch[K, T]{Key: key, Items: p.items}, bytes: p.bytes}
+	p.items = make([]T, 0, b.arrayLen)
+	b.pendingBytes -= p.bytes
+	p.bytes = 0
+}
+
+func (b *PartitionedBatcher[K, T]) ready(p *partition[T]) bool {
+	if b.option.maxItems != 0 && uint(len(p.items)) >= b.option.maxItems {
+		return true
+	}
+	if b.option.maxBytes != 0 && p.bytes >= b.option.maxBytes {
+		return true
+	}
+	return false
+}
+
+func (b *PartitionedBatcher[K, T]) drainBatchChan() {
+	for {
+		select {
+		case queued := <-b.batchChan:
+			if queued.bytes > 0 {
+				b.release(queued.bytes)
+			}
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package batcher
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func stringBytes(str interface{}) uint {
+	return uint(len(str.(string)))
+}
+
+func TestPartitionedNoCalculateBytes(t *testing.T) {
+	_, err := NewPartitionedBatcher[string, string](5, PartitionConfig{MaxTotalBytes: 10})
+	assert.Error(t, err)
+
+	_, err = NewPartitionedBatcher[string, string](5, PartitionConfig{}, WithMaxBytes(10, nil))
+	assert.Error(t, err)
+}
+
+func TestPartitionedMaxItems(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, int](10, PartitionConfig{}, WithMaxItems(3))
+	assert.Nil(err)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(b.Put("a", i))
+		assert.Nil(b.Put("b", i*10))
+	}
+	assert.Equal(2, b.Partitions())
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(KeyedBatch[string, int]{Key: "a", Items: []int{0, 1, 2}}, batch)
+
+	batch, err = b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(KeyedBatch[string, int]{Key: "b", Items: []int{0, 10, 20}}, batch)
+}
+
+func TestPartitionedMaxBytes(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[int, string](10, PartitionConfig{},
+		WithMaxBytes(5, stringBytes))
+	assert.Nil(err)
+
+	assert.Nil(b.Put(1, "abc"))
+	assert.Nil(b.Put(2, "abc"))
+	assert.Nil(b.Put(1, "de"))
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(1, batch.Key)
+	assert.Equal([]string{"abc", "de"}, batch.Items)
+}
+
+func TestPartitionedMaxTime(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](10, PartitionConfig{},
+		WithMaxItems(100), WithMaxTime(30*time.Millisecond))
+	assert.Nil(err)
+
+	start := time.Now()
+	assert.Nil(b.Put("a", "1"))
+	time.Sleep(10 * time.Millisecond)
+	assert.Nil(b.Put("b", "2"))
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal("a", batch.Key)
+	assert.True(time.Since(start) >= 30*time.Millisecond)
+
+	batch, err = b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal("b", batch.Key)
+	assert.True(time.Since(start) >= 40*time.Millisecond)
+}
+
+func TestPartitionedMaxTotalBytes(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](10, PartitionConfig{MaxTotalBytes: 10},
+		WithMaxBytes(100, stringBytes))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a", "aaaa"))
+	assert.Nil(b.Put("b", "bb"))
+	assert.Nil(b.Put("c", "ccc"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = b.Get(ctx)
+	cancel()
+	assert.Equal(context.DeadlineExceeded, err)
+
+	// pushes the total to 12, so the largest partition must go and the
+	// Put must wait for Get to take it
+	done := make(chan error)
+	go func() {
+		done <- b.Put("b", "bbb")
+	}()
+	select {
+	case <-done:
+		t.Fatal("put should block until queued bytes are taken")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(KeyedBatch[string, string]{Key: "a", Items: []string{"aaaa"}}, batch)
+	assert.Nil(<-done)
+
+	assert.Nil(b.Flush("b"))
+	batch, err = b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(KeyedBatch[string, string]{Key: "b", Items: []string{"bb", "bbb"}}, batch)
+	assert.Equal(3, b.Partitions())
+}
+
+func TestPartitionedFlushAllSingleConsumer(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[int, string](1, PartitionConfig{MaxTotalBytes: 100},
+		WithMaxBytes(100, stringBytes))
+	assert.Nil(err)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(b.Put(i, "a"))
+	}
+
+	// FlushAll holds the lock while it waits on the queue, so the
+	// consumer must be able to take batches without it
+	done := make(chan error)
+	go func() {
+		done <- b.FlushAll()
+	}()
+
+	keys := map[int]bool{}
+	for i := 0; i < 5; i++ {
+		batch, err := b.Get(context.Background())
+		assert.Nil(err)
+		assert.Equal([]string{"a"}, batch.Items)
+		keys[batch.Key] = true
+	}
+	assert.Nil(<-done)
+	assert.Len(keys, 5)
+
+	// every byte was released, so a full sized item fits again
+	assert.Nil(b.Put(0, strings.Repeat("a", 100)))
+}
+
+func TestPartitionedMaxTotalBytesDispose(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](10, PartitionConfig{MaxTotalBytes: 4},
+		WithMaxItems(1), WithMaxBytes(100, stringBytes))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a", "aaaa"))
+	done := make(chan error)
+	go func() {
+		done <- b.Put("a", "a")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	b.Dispose()
+	assert.Equal(ErrDisposed, <-done)
+}
+
+func TestPartitionedIdleEviction(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](10, PartitionConfig{IdleTimeout: 20 * time.Millisecond},
+		WithMaxItems(100))
+	assert.Nil(err)
+	defer b.Dispose()
+
+	assert.Nil(b.Put("a", "1"))
+	assert.Equal(1, b.Partitions())
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(KeyedBatch[string, string]{Key: "a", Items: []string{"1"}}, batch)
+	assert.Eventually(func() bool {
+		return b.Partitions() == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestPartitionedFlush(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](10, PartitionConfig{}, WithMaxItems(100))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a", "1"))
+	assert.Nil(b.Put("b", "2"))
+	assert.Nil(b.Flush("b"))
+	assert.Nil(b.Flush("missing"))
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal("b", batch.Key)
+
+	assert.Nil(b.Put("c", "3"))
+	assert.Nil(b.FlushAll())
+
+	keys := []string{}
+	for i := 0; i < 2; i++ {
+		batch, err := b.Get(context.Background())
+		assert.Nil(err)
+		keys = append(keys, batch.Key)
+	}
+	assert.ElementsMatch([]string{"a", "c"}, keys)
+}
+
+func TestPartitionedDispose(t *testing.T) {
+	assert := assert.New(t)
+	b, err := NewPartitionedBatcher[string, string](1,
+		PartitionConfig{IdleTimeout: time.Millisecond}, WithMaxItems(1), WithMaxTime(time.Millisecond))
+	assert.Nil(err)
+
+	assert.Nil(b.Put("a", "1"))
+	go func() {
+		// blocks as the queue only has room for one batch
+		b.Put("b", "2")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	b.Dispose()
+	assert.True(b.IsDisposed())
+	assert.Equal(ErrDisposed, b.Put("a", "1"))
+	assert.Equal(ErrDisposed, b.Flush("a"))
+	assert.Equal(ErrDisposed, b.FlushAll())
+
+	_, err = b.Get(context.Background())
+	assert.Equal(ErrDisposed, err)
+	b.Dispose()
+}
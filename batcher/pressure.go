@@ -0,0 +1,236 @@
+package batcher
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by Put when the queue of completed batches
+// is full and the batcher uses OverflowFail.
+var ErrQueueFull = errors.New("batcher: queue full")
+
+// OverflowPolicy determines what a batcher does when a batch completes
+// while its queue of completed batches is full.  A batcher created with
+// a queueLen of zero has no queue to overflow, so every policy blocks
+// until a Get takes the batch, as with OverflowBlock.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the Put until a Get makes room.  PutContext
+	// gives up when its context is done.  This is the default.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowFail rejects the Put with ErrQueueFull.  The item is not
+	// added to the batcher.
+	OverflowFail
+	// OverflowDropOldest discards the oldest queued batch to make room.
+	OverflowDropOldest
+	// OverflowDropNewest discards the batch that was just completed.
+	OverflowDropNewest
+)
+
+// FlushReason describes what completed a batch.
+type FlushReason int
+
+const (
+	// FlushTime means maxTime elapsed while waiting in Get.
+	FlushTime FlushReason = iota
+	// FlushItems means the batch reached maxItems.
+	FlushItems
+	// FlushBytes means the batch reached maxBytes.
+	FlushBytes
+	// FlushManual means the batch was completed by a call to Flush.
+	FlushManual
+)
+
+func (r FlushReason) String() string {
+	switch r {
+	case FlushTime:
+		return "time"
+	case FlushItems:
+		return "items"
+	case FlushBytes:
+		return "bytes"
+	case FlushManual:
+		return "manual"
+	}
+	return "unknown"
+}
+
+// BatchSizeBuckets is the number of buckets in Stats.BatchSizes.
+const BatchSizeBuckets = 32
+
+// Stats is a snapshot of a batcher's metrics.
+type Stats struct {
+	// BatchSizes is a histogram of completed batch sizes.  Bucket 0
+	// counts batches of a single item and bucket i counts batches of
+	// more than 1<<(i-1) and at most 1<<i items.  Empty batches are not
+	// counted.
+	BatchSizes [BatchSizeBuckets]uint64
+	// Flushes counts completed batches by the reason they completed,
+	// indexed by FlushReason.
+	Flushes [FlushManual + 1]uint64
+	// QueueDepth is the number of completed batches waiting for Get.
+	QueueDepth int
+	// Blocked is the total time Puts and Flushes have spent waiting on
+	// a full queue.
+	Blocked time.Duration
+	// Rejected counts Puts that failed with ErrQueueFull or because
+	// their context was done while waiting on a full queue.
+	Rejected uint64
+	// DroppedBatches and DroppedItems count what was discarded by the
+	// drop overflow policies.
+	DroppedBatches, DroppedItems uint64
+}
+
+// Observer is notified of batcher events as they happen.  Calls are
+// made while the batcher is locked, so implementations must be fast
+// and must not call back into the batcher.
+type Observer interface {
+	// BatchCompleted is called for every non-empty batch completed.
+	BatchCompleted(reason FlushReason, size int)
+	// Blocked is called after a Put or Flush waited on a full queue.
+	Blocked(d time.Duration)
+	// BatchDropped is called when an overflow policy discards a batch.
+	BatchDropped(size int)
+}
+
+// WithOverflowPolicy sets the OverflowPolicy of a batcher.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return Option{overflow: policy}
+}
+
+// WithObserver registers an Observer with a batcher.
+func WithObserver(observer Observer) Option {
+	return Option{observer: observer}
+}
+
+// pressure applies an OverflowPolicy and collects metrics on behalf of
+// a batcher.  Its stats are guarded separately from the batcher so they
+// can be read while a Put is blocked holding the batcher's lock.
+type pressure struct {
+	policy    OverflowPolicy
+	observer  Observer
+	statsLock sync.Mutex
+	stats     Stats
+}
+
+func newPressure(option Option) *pressure {
+	return &pressure{policy: option.overflow, observer: option.observer}
+}
+
+// enqueue places a completed batch on ch according to the policy.  A
+// nil error means the batch was either queued or dropped by policy;
+// otherwise the caller still owns the batch.  It must be called with
+// the batcher locked.
+func enqueue[T any](p *pressure, ctx context.Context, ch chan []T, items []T, reason FlushReason) error {
+	select {
+	case ch <- items:
+		p.completed(reason, len(items))
+		return nil
+	default:
+	}
+
+	// an unbuffered channel only has room while a Get is parked on it,
+	// which would make the other policies fail, drop or spin on every
+	// batch
+	policy := p.policy
+	if cap(ch) == 0 {
+		policy = OverflowBlock
+	}
+
+	switch policy {
+	case OverflowFail:
+		p.reject()
+		return ErrQueueFull
+	case OverflowDropNewest:
+		p.dropped(len(items))
+		return nil
+	case OverflowDropOldest:
+		for {
+			select {
+			case ch <- items:
+				p.completed(reason, len(items))
+				return nil
+			default:
+			}
+			// Get may win the race for the oldest batch, in which case
+			// there is now room anyway
+			select {
+			case old := <-ch:
+				p.dropped(len(old))
+			default:
+			}
+		}
+	}
+
+	start := time.Now()
+	select {
+	case ch <- items:
+		p.blocked(time.Since(start))
+		p.completed(reason, len(items))
+		return nil
+	case <-ctx.Done():
+		p.blocked(time.Since(start))
+		p.reject()
+		return ctx.Err()
+	}
+}
+
+func (p *pressure) completed(reason FlushReason, size int) {
+	if size == 0 {
+		return
+	}
+
+	bucket := bits.Len(uint(size - 1))
+	if bucket >= BatchSizeBuckets {
+		bucket = BatchSizeBuckets - 1
+	}
+
+	p.statsLock.Lock()
+	p.stats.BatchSizes[bucket]++
+	p.stats.Flushes[reason]++
+	p.statsLock.Unlock()
+
+	if p.observer != nil {
+		p.observer.BatchCompleted(reason, size)
+	}
+}
+
+func (p *pressure) blocked(d time.Duration) {
+	p.statsLock.Lock()
+	p.stats.Blocked += d
+	p.statsLock.Unlock()
+
+	if p.observer != nil {
+		p.observer.Blocked(d)
+	}
+}
+
+func (p *pressure) dropped(size int) {
+	p.statsLock.Lock()
+	p.stats.DroppedBatches++
+	p.stats.DroppedItems += uint64(size)
+	p.statsLock.Unlock()
+
+	if p.observer != nil {
+		p.observer.BatchDropped(size)
+	}
+}
+
+func (p *pressure) reject() {
+	p.statsLock.Lock()
+	p.stats.Rejected++
+	p.statsLock.Unlock()
+}
+
+func (p *pressure) snapshot(queueDepth int) Stats {
+	p.statsLock.Lock()
+	stats := p.stats
+	p.statsLock.Unlock()
+
+	stats.QueueDepth = queueDepth
+	return stats
+}
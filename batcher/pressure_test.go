@@ -0,0 +1,214 @@
+package batcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	sync.Mutex
+	reasons []FlushReason
+	sizes   []int
+	blocked []time.Duration
+	dropped []int
+}
+
+func (o *recordingObserver) BatchCompleted(reason FlushReason, size int) {
+	o.Lock()
+	defer o.Unlock()
+	o.reasons = append(o.reasons, reason)
+	o.sizes = append(o.sizes, size)
+}
+
+func (o *recordingObserver) Blocked(d time.Duration) {
+	o.Lock()
+	defer o.Unlock()
+	o.blocked = append(o.blocked, d)
+}
+
+func (o *recordingObserver) BatchDropped(size int) {
+	o.Lock()
+	defer o.Unlock()
+	o.dropped = append(o.dropped, size)
+}
+
+func TestOverflowFail(t *testing.T) {
+	assert := assert.New(t)
+	b, err := New(0, 2, 0, 1, nil, WithOverflowPolicy(OverflowFail))
+	assert.Nil(err)
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(b.Put(i))
+	}
+	assert.Equal(ErrQueueFull, b.Put(3))
+	assert.Equal(ErrQueueFull, b.Flush())
+
+	batch, err := b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{0, 1}, batch)
+
+	// the rejected item was never added
+	assert.Nil(b.Put(4))
+	batch, err = b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{2, 4}, batch)
+
+	stats := b.(ContextBatcher).Stats()
+	assert.Equal(uint64(2), stats.Rejected)
+	assert.Equal(uint64(2), stats.Flushes[FlushItems])
+	assert.Zero(stats.QueueDepth)
+}
+
+func TestOverflowDropOldest(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBatcher(2, WithMaxItems(1), WithOverflowPolicy(OverflowDropOldest))
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(b.Put(i))
+	}
+
+	stats := b.(ContextBatcher).Stats()
+	assert.Equal(2, stats.QueueDepth)
+	assert.Equal(uint64(3), stats.DroppedBatches)
+	assert.Equal(uint64(3), stats.DroppedItems)
+
+	batch, err := b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{3}, batch)
+	batch, err = b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{4}, batch)
+}
+
+func TestOverflowDropNewest(t *testing.T) {
+	assert := assert.New(t)
+	observer := &recordingObserver{}
+	b, err := NewGenericBatcher[int](2, WithMaxItems(1),
+		WithOverflowPolicy(OverflowDropNewest), WithObserver(observer))
+	assert.Nil(err)
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(b.Put(i))
+	}
+
+	batch, err := b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal([]int{0}, batch)
+	batch, err = b.Get(context.Background())
+	assert.Nil(err)
+	assert.Equal([]int{1}, batch)
+
+	assert.Equal(uint64(3), b.Stats().DroppedBatches)
+	assert.Equal([]int{1, 1, 1}, observer.dropped)
+}
+
+func TestOverflowUnbufferedBlocks(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowFail, OverflowDropOldest, OverflowDropNewest} {
+		assert := assert.New(t)
+		b := NewBatcher(0, WithMaxItems(1), WithOverflowPolicy(policy))
+
+		done := make(chan error)
+		go func() {
+			done <- b.Put(1)
+		}()
+		select {
+		case err := <-done:
+			t.Fatalf("policy %d: put returned %v without a get", policy, err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		batch, err := b.Get()
+		assert.Nil(err)
+		assert.Equal([]interface{}{1}, batch)
+		assert.Nil(<-done)
+
+		stats := b.(ContextBatcher).Stats()
+		assert.Zero(stats.Rejected)
+		assert.Zero(stats.DroppedBatches)
+		b.Dispose()
+	}
+}
+
+func TestOverflowBlockContext(t *testing.T) {
+	assert := assert.New(t)
+	observer := &recordingObserver{}
+	b := NewBatcher(1, WithMaxItems(1), WithObserver(observer))
+
+	assert.Nil(b.Put("a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, b.(ContextBatcher).PutContext(ctx, "b"))
+
+	stats := b.(ContextBatcher).Stats()
+	assert.Equal(uint64(1), stats.Rejected)
+	assert.True(stats.Blocked >= 20*time.Millisecond)
+	assert.Len(observer.blocked, 1)
+
+	// a Put that only waits on the lock also honors its context
+	done := make(chan struct{})
+	go func() {
+		b.Put("c")
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, b.(ContextBatcher).PutContext(ctx, "d"))
+
+	batch, err := b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{"a"}, batch)
+	<-done
+
+	batch, err = b.Get()
+	assert.Nil(err)
+	assert.Equal([]interface{}{"c"}, batch)
+}
+
+func TestFlushReasons(t *testing.T) {
+	assert := assert.New(t)
+	observer := &recordingObserver{}
+	b, err := New(20*time.Millisecond, 3, 5, 10, func(str interface{}) uint {
+		return uint(len(str.(string)))
+	}, WithObserver(observer))
+	assert.Nil(err)
+
+	b.Put("a")
+	b.Put("b")
+	b.Put("c")
+	b.Put("defgh")
+	b.Put("i")
+	b.Flush()
+	b.Put("j")
+	for i := 0; i < 4; i++ {
+		_, err := b.Get()
+		assert.Nil(err)
+	}
+
+	assert.Equal([]FlushReason{FlushItems, FlushBytes, FlushManual, FlushTime}, observer.reasons)
+	assert.Equal([]int{3, 1, 1, 1}, observer.sizes)
+
+	stats := b.(ContextBatcher).Stats()
+	assert.Equal(uint64(1), stats.Flushes[FlushTime])
+	assert.Equal(uint64(1), stats.Flushes[FlushItems])
+	assert.Equal(uint64(1), stats.Flushes[FlushBytes])
+	assert.Equal(uint64(1), stats.Flushes[FlushManual])
+	assert.Equal(uint64(3), stats.BatchSizes[0])
+	assert.Equal(uint64(1), stats.BatchSizes[2])
+
+	assert.Equal("time", FlushTime.String())
+	assert.Equal("manual", FlushManual.String())
+	assert.Equal("unknown", FlushReason(-1).String())
+}
+
+func TestPartitionedRejectsPressureOptions(t *testing.T) {
+	_, err := NewPartitionedBatcher[string, string](1, PartitionConfig{},
+		WithOverflowPolicy(OverflowFail))
+	assert.Error(t, err)
+}
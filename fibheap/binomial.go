@@ -0,0 +1,254 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fibheap
+
+import "fmt"
+
+var _ Heap[int, int] = (*BinomialHeap[int, int])(nil)
+
+// binomialTree is a position in a BinomialHeap.  Nodes move between
+// positions as their priorities change, while the positions keep the
+// shape of the trees.
+type binomialTree[P, V any] struct {
+	node                   *Node[P, V]
+	parent, child, sibling *binomialTree[P, V]
+	degree                 int
+}
+
+// BinomialHeap is a binomial heap: a list of heap-ordered binomial
+// trees of distinct orders, merged like the digits of a binary sum.
+// Enqueue merges a single node tree into the root list, so it, like
+// Min, DequeueMin, DecreaseKey, Delete and Merge, runs in O(lg n) worst
+// case time.
+type BinomialHeap[P, V any] struct {
+	head  *binomialTree[P, V]
+	size  uint
+	less  func(a, b P) bool
+	owner *owner
+}
+
+// NewBinomialHeap creates a new, empty binomial heap in which a node has
+// priority over another when less reports its priority is less than
+// the other's.
+func NewBinomialHeap[P, V any](less func(a, b P) bool) *BinomialHeap[P, V] {
+	return &BinomialHeap[P, V]{less: less, owner: &owner{}}
+}
+
+// Enqueue adds a value with the given priority to the heap and returns
+// its node.
+func (heap *BinomialHeap[P, V]) Enqueue(priority P, value V) *Node[P, V] {
+	node := newNode(heap.owner, priority, value)
+	node.tree = &binomialTree[P, V]{node: node}
+	heap.head = heap.union(heap.head, node.tree)
+	heap.size++
+	return node
+}
+
+// Min returns the node with the least priority in the heap.
+func (heap *BinomialHeap[P, V]) Min() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Trying to get minimum element of empty heap")
+	}
+	min, _ := heap.minRoot()
+	return min.node, nil
+}
+
+// IsEmpty answers: is the heap empty?
+func (heap *BinomialHeap[P, V]) IsEmpty() bool {
+	return heap.size == 0
+}
+
+// Size gives the number of elements in the heap
+func (heap *BinomialHeap[P, V]) Size() uint {
+	return heap.size
+}
+
+// Contains reports whether the node is currently in the heap.
+func (heap *BinomialHeap[P, V]) Contains(node *Node[P, V]) bool {
+	return node.belongsTo(heap.owner)
+}
+
+// DequeueMin removes and returns the node with the least priority in
+// the heap.
+func (heap *BinomialHeap[P, V]) DequeueMin() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Cannot dequeue minimum of empty heap")
+	}
+
+	min, prev := heap.minRoot()
+	heap.removeRoot(min, prev)
+	return min.node, nil
+}
+
+// DecreaseKey gives the node a new priority, which must be less than
+// its current one, and returns the node if successfully set.
+func (heap *BinomialHeap[P, V]) DecreaseKey(node *Node[P, V], newPriority P) (*Node[P, V], error) {
+	if err := checkNode[P, V](heap, node, "decrease key"); err != nil {
+		return nil, err
+	}
+
+	if !heap.less(newPriority, node.Priority) {
+		return nil, fmt.Errorf("The given new priority: %v, is larger than or equal to the old: %v",
+			newPriority, node.Priority)
+	}
+
+	node.Priority = newPriority
+	heap.siftUp(node.tree, false)
+	return node, nil
+}
+
+// Delete removes the node from the heap.
+func (heap *BinomialHeap[P, V]) Delete(node *Node[P, V]) error {
+	if err := checkNode[P, V](heap, node, "delete"); err != nil {
+		return err
+	}
+
+	root := heap.siftUp(node.tree, true)
+	var prev *binomialTree[P, V]
+	for t := heap.head; t != root; t = t.sibling {
+		prev = t
+	}
+	heap.removeRoot(root, prev)
+	return nil
+}
+
+// Merge moves all of the nodes of other, which must also be a
+// BinomialHeap, into this heap, leaving other empty but still usable.
+// Nodes of other become nodes of this heap.  Both heaps must order
+// priorities with the same comparator.
+func (heap *BinomialHeap[P, V]) Merge(other Heap[P, V]) error {
+	if other == nil {
+		return NilError("The heap to merge is nil. Cannot merge")
+	}
+	o, ok := other.(*BinomialHeap[P, V])
+	if !ok {
+		return IncompatibleHeapError("Cannot merge a BinomialHeap with another kind of heap")
+	}
+	if !adopt(heap.owner, o.owner) {
+		return nil
+	}
+
+	heap.head = heap.union(heap.head, o.head)
+	heap.size += o.size
+
+	o.owner = &owner{}
+	o.head = nil
+	o.size = 0
+	return nil
+}
+
+// minRoot returns the root with the least priority and the root before
+// it in the list, if any.
+func (heap *BinomialHeap[P, V]) minRoot() (min, prev *binomialTree[P, V]) {
+	min = heap.head
+	for p, t := heap.head, heap.head.sibling; t != nil; p, t = t, t.sibling {
+		if heap.less(t.node.Priority, min.node.Priority) {
+			min, prev = t, p
+		}
+	}
+	return min, prev
+}
+
+// removeRoot takes a root out of the list and merges its children back
+// into the heap.
+func (heap *BinomialHeap[P, V]) removeRoot(root, prev *binomialTree[P, V]) {
+	if prev == nil {
+		heap.head = root.sibling
+	} else {
+		prev.sibling = root.sibling
+	}
+
+	// children are kept in decreasing order, roots in increasing order
+	var children *binomialTree[P, V]
+	for child := root.child; child != nil; {
+		next := child.sibling
+		child.parent = nil
+		child.sibling = children
+		children = child
+		child = next
+	}
+
+	heap.head = heap.union(heap.head, children)
+	heap.size--
+	root.node.release()
+}
+
+// siftUp moves a node towards the root of its tree while it is less
+// than its parent, or all the way if force is set, and returns the
+// position it ends up in.
+func (heap *BinomialHeap[P, V]) siftUp(t *binomialTree[P, V], force bool) *binomialTree[P, V] {
+	for t.parent != nil && (force || heap.less(t.node.Priority, t.parent.node.Priority)) {
+		parent := t.parent
+		t.node, parent.node = parent.node, t.node
+		t.node.tree = t
+		parent.node.tree = parent
+		t = parent
+	}
+	return t
+}
+
+// union merges two root lists, linking trees of the same order.
+func (heap *BinomialHeap[P, V]) union(a, b *binomialTree[P, V]) *binomialTree[P, V] {
+	var (
+		head *binomialTree[P, V]
+		tail **binomialTree[P, V] = &head
+	)
+	for a != nil && b != nil {
+		if a.degree <= b.degree {
+			*tail, a = a, a.sibling
+		} else {
+			*tail, b = b, b.sibling
+		}
+		tail = &(*tail).sibling
+	}
+	if a != nil {
+		*tail = a
+	} else {
+		*tail = b
+	}
+
+	if head == nil {
+		return nil
+	}
+
+	var prev *binomialTree[P, V]
+	x := head
+	for next := x.sibling; next != nil; next = x.sibling {
+		switch {
+		case x.degree != next.degree || (next.sibling != nil && next.sibling.degree == x.degree):
+			prev, x = x, next
+		case !heap.less(next.node.Priority, x.node.Priority):
+			x.sibling = next.sibling
+			link(next, x)
+		default:
+			if prev == nil {
+				head = next
+			} else {
+				prev.sibling = next
+			}
+			link(x, next)
+			x = next
+		}
+	}
+	return head
+}
+
+// link makes the tree y the first child of the tree z of the same order.
+func link[P, V any](y, z *binomialTree[P, V]) {
+	y.parent = z
+	y.sibling = z.child
+	z.child = y
+	z.degree++
+}
@@ -0,0 +1,203 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fibheap
+
+import "fmt"
+
+var _ Heap[int, int] = (*DaryHeap[int, int])(nil)
+
+// DaryHeap is an implicit heap stored in a slice in which every node has
+// up to d children.  A larger d makes the heap shallower, speeding up
+// Enqueue and DecreaseKey at the cost of DequeueMin comparing more
+// children per level, and its contiguous layout makes it cache
+// friendly.  Enqueue and DecreaseKey run in O(log_d n) time, DequeueMin
+// and Delete in O(d log_d n), Min in O(1) and Merge in O(n).
+type DaryHeap[P, V any] struct {
+	nodes []*Node[P, V]
+	d     int
+	less  func(a, b P) bool
+	owner *owner
+}
+
+// NewDaryHeap creates a new, empty d-ary heap in which a node has
+// priority over another when less reports its priority is less than
+// the other's.  A d less than 2 is treated as 2.
+func NewDaryHeap[P, V any](d int, less func(a, b P) bool) *DaryHeap[P, V] {
+	if d < 2 {
+		d = 2
+	}
+	return &DaryHeap[P, V]{d: d, less: less, owner: &owner{}}
+}
+
+// Enqueue adds a value with the given priority to the heap and returns
+// its node.
+func (heap *DaryHeap[P, V]) Enqueue(priority P, value V) *Node[P, V] {
+	node := newNode(heap.owner, priority, value)
+	node.index = len(heap.nodes)
+	heap.nodes = append(heap.nodes, node)
+	heap.up(node.index)
+	return node
+}
+
+// Min returns the node with the least priority in the heap.
+func (heap *DaryHeap[P, V]) Min() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Trying to get minimum element of empty heap")
+	}
+	return heap.nodes[0], nil
+}
+
+// IsEmpty answers: is the heap empty?
+func (heap *DaryHeap[P, V]) IsEmpty() bool {
+	return len(heap.nodes) == 0
+}
+
+// Size gives the number of elements in the heap
+func (heap *DaryHeap[P, V]) Size() uint {
+	return uint(len(heap.nodes))
+}
+
+// Contains reports whether the node is currently in the heap.
+func (heap *DaryHeap[P, V]) Contains(node *Node[P, V]) bool {
+	return node.belongsTo(heap.owner)
+}
+
+// DequeueMin removes and returns the node with the least priority in
+// the heap.
+func (heap *DaryHeap[P, V]) DequeueMin() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Cannot dequeue minimum of empty heap")
+	}
+	return heap.remove(0), nil
+}
+
+// DecreaseKey gives the node a new priority, which must be less than
+// its current one, and returns the node if successfully set.
+func (heap *DaryHeap[P, V]) DecreaseKey(node *Node[P, V], newPriority P) (*Node[P, V], error) {
+	if err := checkNode[P, V](heap, node, "decrease key"); err != nil {
+		return nil, err
+	}
+
+	if !heap.less(newPriority, node.Priority) {
+		return nil, fmt.Errorf("The given new priority: %v, is larger than or equal to the old: %v",
+			newPriority, node.Priority)
+	}
+
+	node.Priority = newPriority
+	heap.up(node.index)
+	return node, nil
+}
+
+// Delete removes the node from the heap.
+func (heap *DaryHeap[P, V]) Delete(node *Node[P, V]) error {
+	if err := checkNode[P, V](heap, node, "delete"); err != nil {
+		return err
+	}
+
+	heap.remove(node.index)
+	return nil
+}
+
+// Merge moves all of the nodes of other, which must also be a DaryHeap,
+// into this heap, leaving other empty but still usable.  Nodes of other
+// become nodes of this heap.  Both heaps must order priorities with the
+// same comparator.
+func (heap *DaryHeap[P, V]) Merge(other Heap[P, V]) error {
+	if other == nil {
+		return NilError("The heap to merge is nil. Cannot merge")
+	}
+	o, ok := other.(*DaryHeap[P, V])
+	if !ok {
+		return IncompatibleHeapError("Cannot merge a DaryHeap with another kind of heap")
+	}
+	if !adopt(heap.owner, o.owner) {
+		return nil
+	}
+
+	for _, node := range o.nodes {
+		node.index = len(heap.nodes)
+		heap.nodes = append(heap.nodes, node)
+	}
+	// rebuild bottom up, which is cheaper than sifting each node in
+	for i := (len(heap.nodes) - 2) / heap.d; i >= 0; i-- {
+		heap.down(i)
+	}
+
+	o.owner = &owner{}
+	o.nodes = nil
+	return nil
+}
+
+func (heap *DaryHeap[P, V]) swap(i, j int) {
+	heap.nodes[i], heap.nodes[j] = heap.nodes[j], heap.nodes[i]
+	heap.nodes[i].index = i
+	heap.nodes[j].index = j
+}
+
+func (heap *DaryHeap[P, V]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / heap.d
+		if !heap.less(heap.nodes[i].Priority, heap.nodes[parent].Priority) {
+			break
+		}
+		heap.swap(i, parent)
+		i = parent
+	}
+}
+
+func (heap *DaryHeap[P, V]) down(i int) bool {
+	start := i
+	for {
+		first := heap.d*i + 1
+		if first >= len(heap.nodes) {
+			break
+		}
+
+		min := first
+		last := first + heap.d
+		if last > len(heap.nodes) {
+			last = len(heap.nodes)
+		}
+		for child := first + 1; child < last; child++ {
+			if heap.less(heap.nodes[child].Priority, heap.nodes[min].Priority) {
+				min = child
+			}
+		}
+
+		if !heap.less(heap.nodes[min].Priority, heap.nodes[i].Priority) {
+			break
+		}
+		heap.swap(i, min)
+		i = min
+	}
+	return i > start
+}
+
+func (heap *DaryHeap[P, V]) remove(i int) *Node[P, V] {
+	last := len(heap.nodes) - 1
+	if i != last {
+		heap.swap(i, last)
+	}
+
+	node := heap.nodes[last]
+	heap.nodes[last] = nil
+	heap.nodes = heap.nodes[:last]
+
+	if i != last && !heap.down(i) {
+		heap.up(i)
+	}
+	node.release()
+	return node
+}
@@ -0,0 +1,285 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fibheap
+
+import "fmt"
+
+var _ Heap[int, int] = (*FibHeap[int, int])(nil)
+
+// FibHeap is a Fibonacci heap ordered by priorities of any type under a
+// comparator, with a value of any type attached to each node.  It works
+// exactly like FloatingFibonacciHeap, but the nodes it hands out carry
+// the caller's data, so no side map from nodes to objects is needed.
+// Enqueue, Min, DecreaseKey and Merge run in amortized O(1) time and
+// DequeueMin and Delete in amortized O(lg n).
+type FibHeap[P, V any] struct {
+	min   *Node[P, V]
+	size  uint
+	less  func(a, b P) bool
+	owner *owner
+}
+
+// NewFibHeap creates a new, empty Fibonacci heap in which a node has
+// priority over another when less reports its priority is less than
+// the other's.
+func NewFibHeap[P, V any](less func(a, b P) bool) *FibHeap[P, V] {
+	return &FibHeap[P, V]{less: less, owner: &owner{}}
+}
+
+// Enqueue adds a value with the given priority to the heap and returns
+// its node.
+func (heap *FibHeap[P, V]) Enqueue(priority P, value V) *Node[P, V] {
+	singleton := newNode(heap.owner, priority, value)
+	singleton.next = singleton
+	singleton.prev = singleton
+
+	heap.min = heap.mergeLists(heap.min, singleton)
+	heap.size++
+	return singleton
+}
+
+// Min returns the node with the least priority in the heap.
+func (heap *FibHeap[P, V]) Min() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Trying to get minimum element of empty heap")
+	}
+	return heap.min, nil
+}
+
+// IsEmpty answers: is the heap empty?
+func (heap *FibHeap[P, V]) IsEmpty() bool {
+	return heap.size == 0
+}
+
+// Size gives the number of elements in the heap
+func (heap *FibHeap[P, V]) Size() uint {
+	return heap.size
+}
+
+// Contains reports whether the node is currently in the heap.
+func (heap *FibHeap[P, V]) Contains(node *Node[P, V]) bool {
+	return node.belongsTo(heap.owner)
+}
+
+// DequeueMin removes and returns the node with the least priority in
+// the heap.
+func (heap *FibHeap[P, V]) DequeueMin() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Cannot dequeue minimum of empty heap")
+	}
+
+	heap.size--
+	min := heap.min
+
+	if min.next == min { // This is the only root node
+		heap.min = nil
+	} else { // There are more root nodes
+		min.prev.next = min.next
+		min.next.prev = min.prev
+		heap.min = min.next // Arbitrary element of the root list
+	}
+
+	if min.child != nil {
+		curr := min.child
+		for ok := true; ok; ok = (curr != min.child) {
+			curr.parent = nil
+			curr = curr.next
+		}
+	}
+
+	heap.min = heap.mergeLists(heap.min, min.child)
+	heap.consolidate()
+
+	min.release()
+	return min, nil
+}
+
+// consolidate links the roots until there is only one tree of each
+// degree, then points min at the least of them.
+func (heap *FibHeap[P, V]) consolidate() {
+	if heap.min == nil {
+		return
+	}
+
+	var (
+		trees   []*Node[P, V]
+		toVisit []*Node[P, V]
+	)
+	for curr := heap.min; len(toVisit) == 0 || toVisit[0] != curr; curr = curr.next {
+		toVisit = append(toVisit, curr)
+	}
+
+	for _, curr := range toVisit {
+		for {
+			for curr.degree >= len(trees) {
+				trees = append(trees, nil)
+			}
+
+			if trees[curr.degree] == nil {
+				trees[curr.degree] = curr
+				break
+			}
+
+			other := trees[curr.degree]
+			trees[curr.degree] = nil
+
+			minT, maxT := curr, other
+			if heap.less(other.Priority, curr.Priority) {
+				minT, maxT = other, curr
+			}
+
+			// Break max out of the root list,
+			// then merge it into min's child list
+			maxT.next.prev = maxT.prev
+			maxT.prev.next = maxT.next
+			maxT.prev = maxT
+			maxT.next = maxT
+			minT.child = heap.mergeLists(minT.child, maxT)
+			maxT.parent = minT
+			maxT.marked = false
+			minT.degree++
+
+			curr = minT
+		}
+
+		// Not less than, so that after merging two trees of equal
+		// priority min points at the root-level one.
+		if !heap.less(heap.min.Priority, curr.Priority) {
+			heap.min = curr
+		}
+	}
+}
+
+// DecreaseKey gives the node a new priority, which must be less than
+// its current one, and returns the node if successfully set.
+func (heap *FibHeap[P, V]) DecreaseKey(node *Node[P, V], newPriority P) (*Node[P, V], error) {
+	if err := heap.check(node, "decrease key"); err != nil {
+		return nil, err
+	}
+
+	if !heap.less(newPriority, node.Priority) {
+		return nil, fmt.Errorf("The given new priority: %v, is larger than or equal to the old: %v",
+			newPriority, node.Priority)
+	}
+
+	node.Priority = newPriority
+	if node.parent != nil && !heap.less(node.parent.Priority, node.Priority) {
+		heap.cut(node)
+	}
+	if !heap.less(heap.min.Priority, node.Priority) {
+		heap.min = node
+	}
+	return node, nil
+}
+
+// Delete removes the node from the heap.
+func (heap *FibHeap[P, V]) Delete(node *Node[P, V]) error {
+	if err := heap.check(node, "delete"); err != nil {
+		return err
+	}
+
+	// there is no smallest priority to decrease to, so lift the node
+	// to the root list and make it the minimum directly
+	if node.parent != nil {
+		heap.cut(node)
+	}
+	heap.min = node
+	heap.DequeueMin()
+	return nil
+}
+
+// Merge moves all of the nodes of other, which must also be a FibHeap,
+// into this heap, leaving other empty but still usable.  Nodes of other
+// become nodes of this heap.  Both heaps must order priorities with the
+// same comparator.
+func (heap *FibHeap[P, V]) Merge(other Heap[P, V]) error {
+	if other == nil {
+		return NilError("The heap to merge is nil. Cannot merge")
+	}
+	o, ok := other.(*FibHeap[P, V])
+	if !ok {
+		return IncompatibleHeapError("Cannot merge a FibHeap with another kind of heap")
+	}
+	if !adopt(heap.owner, o.owner) {
+		return nil
+	}
+
+	heap.min = heap.mergeLists(heap.min, o.min)
+	heap.size += o.size
+
+	o.owner = &owner{}
+	o.min = nil
+	o.size = 0
+	return nil
+}
+
+func (heap *FibHeap[P, V]) check(node *Node[P, V], operation string) error {
+	return checkNode[P, V](heap, node, operation)
+}
+
+func (heap *FibHeap[P, V]) mergeLists(one, two *Node[P, V]) *Node[P, V] {
+	if one == nil {
+		return two
+	}
+	if two == nil {
+		return one
+	}
+
+	oneNext := one.next
+	one.next = two.next
+	one.next.prev = one
+	two.next = oneNext
+	two.next.prev = two
+
+	if heap.less(one.Priority, two.Priority) {
+		return one
+	}
+	return two
+}
+
+// cut moves the node from under its parent to the root list, cutting
+// the parent in turn if it had already lost a child.
+func (heap *FibHeap[P, V]) cut(node *Node[P, V]) {
+	for node.parent != nil {
+		parent := node.parent
+		node.marked = false
+
+		if node.next != node {
+			node.next.prev = node.prev
+			node.prev.next = node.next
+		}
+		if parent.child == node {
+			if node.next != node {
+				parent.child = node.next
+			} else {
+				parent.child = nil
+			}
+		}
+		parent.degree--
+
+		node.prev = node
+		node.next = node
+		node.parent = nil
+		heap.min = heap.mergeLists(heap.min, node)
+
+		if !parent.marked {
+			if parent.parent != nil {
+				parent.marked = true
+			}
+			return
+		}
+		node = parent
+	}
+}
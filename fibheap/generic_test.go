@@ -0,0 +1,207 @@
+package fibheap
+
+// Tests for the generic Fibonacci heap with user payloads
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lessFloat(a, b float64) bool { return a < b }
+
+func TestGenericEnqueueDequeueMin(t *testing.T) {
+	heap := NewFibHeap[float64, int](lessFloat)
+	for i, p := range NumberSequence1 {
+		heap.Enqueue(p, i)
+	}
+	assert.Equal(t, uint(len(NumberSequence1)), heap.Size())
+
+	sorted := append([]float64(nil), NumberSequence1[:]...)
+	sort.Float64s(sorted)
+	for _, p := range sorted {
+		min, err := heap.DequeueMin()
+		require.NoError(t, err)
+		assert.Equal(t, p, min.Priority)
+		// the payload travels with its priority
+		assert.Equal(t, NumberSequence1[min.Value], min.Priority)
+	}
+	assert.True(t, heap.IsEmpty())
+
+	_, err := heap.DequeueMin()
+	assert.IsType(t, EmptyHeapError(""), err)
+	_, err = heap.Min()
+	assert.IsType(t, EmptyHeapError(""), err)
+}
+
+func TestGenericComparator(t *testing.T) {
+	type task struct {
+		name string
+	}
+
+	// a max-heap of string priorities
+	heap := NewFibHeap[string, *task](func(a, b string) bool { return a > b })
+	for _, name := range []string{"b", "d", "a", "c"} {
+		heap.Enqueue(name, &task{name: name})
+	}
+
+	var order []string
+	for !heap.IsEmpty() {
+		min, _ := heap.DequeueMin()
+		order = append(order, min.Value.name)
+	}
+	assert.Equal(t, []string{"d", "c", "b", "a"}, order)
+}
+
+func TestGenericDecreaseKeyDelete(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	heap := NewFibHeap[int, int](func(a, b int) bool { return a < b })
+
+	nodes := make(map[int]*Node[int, int])
+	priorities := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		p := r.Intn(100000)
+		nodes[i] = heap.Enqueue(p, i)
+		priorities[i] = p
+	}
+
+	// consolidate so that decreases have to cut nodes from parents
+	min, _ := heap.DequeueMin()
+	delete(nodes, min.Value)
+	delete(priorities, min.Value)
+
+	for i := 0; i < 1000; i++ {
+		id := r.Intn(1000)
+		node, ok := nodes[id]
+		if !ok {
+			continue
+		}
+		if i%3 == 0 {
+			require.NoError(t, heap.Delete(node))
+			delete(nodes, id)
+			delete(priorities, id)
+			continue
+		}
+		p := priorities[id] - r.Intn(1000) - 1
+		_, err := heap.DecreaseKey(node, p)
+		require.NoError(t, err)
+		priorities[id] = p
+	}
+
+	expected := make([]int, 0, len(priorities))
+	for _, p := range priorities {
+		expected = append(expected, p)
+	}
+	sort.Ints(expected)
+
+	assert.Equal(t, uint(len(expected)), heap.Size())
+	for _, p := range expected {
+		min, err := heap.DequeueMin()
+		require.NoError(t, err)
+		assert.Equal(t, p, min.Priority)
+		assert.Equal(t, p, priorities[min.Value])
+	}
+}
+
+func TestGenericDecreaseKeyErrors(t *testing.T) {
+	heap := NewFibHeap[float64, string](lessFloat)
+	other := NewFibHeap[float64, string](lessFloat)
+
+	_, err := heap.DecreaseKey(nil, 1)
+	assert.IsType(t, EmptyHeapError(""), err)
+
+	node := heap.Enqueue(5, "a")
+	foreign := other.Enqueue(5, "b")
+
+	_, err = heap.DecreaseKey(nil, 1)
+	assert.IsType(t, NilError(""), err)
+	_, err = heap.DecreaseKey(node, 6)
+	assert.EqualError(t, err, "The given new priority: 6, is larger than or equal to the old: 5")
+	_, err = heap.DecreaseKey(foreign, 1)
+	assert.IsType(t, ForeignNodeError(""), err)
+	assert.IsType(t, ForeignNodeError(""), heap.Delete(foreign))
+	assert.Equal(t, 5.0, foreign.Priority)
+
+	// removed nodes no longer belong to any heap
+	heap.Enqueue(10, "c")
+	heap.DequeueMin()
+	assert.False(t, heap.Contains(node))
+	assert.IsType(t, ForeignNodeError(""), heap.Delete(node))
+}
+
+func TestGenericMerge(t *testing.T) {
+	heap1 := NewFibHeap[float64, int](lessFloat)
+	heap2 := NewFibHeap[float64, int](lessFloat)
+	heap3 := NewFibHeap[float64, int](lessFloat)
+	for i, p := range NumberSequence3 {
+		heap1.Enqueue(p, i)
+	}
+	var node *Node[float64, int]
+	for i, p := range NumberSequence4 {
+		node = heap2.Enqueue(p, i)
+	}
+	leftover := heap3.Enqueue(0, 0)
+
+	require.NoError(t, heap1.Merge(heap2))
+	assert.True(t, heap2.IsEmpty())
+	assert.True(t, heap1.Contains(node))
+	assert.False(t, heap2.Contains(node))
+
+	// chains of merges still recognize every node
+	require.NoError(t, heap3.Merge(heap1))
+	assert.True(t, heap3.Contains(node))
+	assert.True(t, heap3.Contains(leftover))
+	require.NoError(t, heap3.Delete(leftover))
+
+	// the emptied heaps stay usable and independent
+	fresh := heap2.Enqueue(1, 1)
+	assert.False(t, heap3.Contains(fresh))
+
+	assert.IsType(t, NilError(""), heap3.Merge(nil))
+
+	for _, p := range NumberSequenceMerged3And4Sorted {
+		min, err := heap3.DequeueMin()
+		require.NoError(t, err)
+		assert.Equal(t, p, min.Priority)
+	}
+	assert.True(t, heap3.IsEmpty())
+}
+
+func ExampleFibHeap() {
+	type job struct {
+		name string
+	}
+
+	heap := NewFibHeap[int, job](func(a, b int) bool { return a < b })
+	heap.Enqueue(3, job{"compile"})
+	deploy := heap.Enqueue(5, job{"deploy"})
+	heap.Enqueue(1, job{"fetch"})
+
+	heap.DecreaseKey(deploy, 2)
+	for !heap.IsEmpty() {
+		node, _ := heap.DequeueMin()
+		fmt.Println(node.Priority, node.Value.name)
+	}
+	// Output:
+	// 1 fetch
+	// 2 deploy
+	// 3 compile
+}
+
+func BenchmarkGenericFibHeap_DecreaseKey(b *testing.B) {
+	heap := NewFibHeap[float64, int](lessFloat)
+	nodes := make([]*Node[float64, int], 0, b.N)
+	for i := 0; i < b.N; i++ {
+		nodes = append(nodes, heap.Enqueue(2*float64(i), i))
+	}
+	heap.DequeueMin()
+	b.ResetTimer()
+
+	for i := 1; i < b.N; i++ {
+		heap.DecreaseKey(nodes[i], float64(-i))
+	}
+}
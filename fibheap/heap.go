@@ -0,0 +1,133 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fibheap
+
+// Heap is a mergeable priority queue ordered by priorities of any type
+// under a comparator, handing out a Node for each value it holds.  A
+// node serves as the handle for DecreaseKey and Delete for as long as
+// it remains in the heap it was enqueued on, or a heap that heap was
+// merged into.  FibHeap, PairingHeap, BinomialHeap and DaryHeap all
+// implement Heap; they differ in the costs of their operations.
+type Heap[P, V any] interface {
+	// Enqueue adds a value with the given priority and returns its node.
+	Enqueue(priority P, value V) *Node[P, V]
+	// Min returns the node with the least priority.
+	Min() (*Node[P, V], error)
+	// DequeueMin removes and returns the node with the least priority.
+	DequeueMin() (*Node[P, V], error)
+	// DecreaseKey gives the node a new priority, which must be less
+	// than its current one.
+	DecreaseKey(node *Node[P, V], priority P) (*Node[P, V], error)
+	// Delete removes the node.
+	Delete(node *Node[P, V]) error
+	// Merge moves every node of other, which must be the same kind of
+	// heap, into this one and leaves other empty.
+	Merge(other Heap[P, V]) error
+	// Contains reports whether the node is in the heap.
+	Contains(node *Node[P, V]) bool
+	// IsEmpty answers: is the heap empty?
+	IsEmpty() bool
+	// Size gives the number of elements in the heap.
+	Size() uint
+}
+
+// ForeignNodeError fires when a node is used with a heap it does
+// not belong to, either because it was enqueued on another heap or
+// because it has already been removed. Its string holds additional data.
+type ForeignNodeError string
+
+func (e ForeignNodeError) Error() string {
+	return string(e)
+}
+
+// IncompatibleHeapError fires when two different kinds of heap are
+// merged. Its string holds additional data.
+type IncompatibleHeapError string
+
+func (e IncompatibleHeapError) Error() string {
+	return string(e)
+}
+
+// Node is a node of a Heap.  Each kind of heap uses the fields it needs
+// to place the node.
+type Node[P, V any] struct {
+	degree                    int
+	marked                    bool
+	next, prev, child, parent *Node[P, V]
+	tree                      *binomialTree[P, V]
+	index                     int
+	owner                     *owner
+	// Priority orders the node in the heap.  It may only be changed
+	// through DecreaseKey.
+	Priority P
+	// Value is the user data attached to the node.
+	Value V
+}
+
+func newNode[P, V any](o *owner, priority P, value V) *Node[P, V] {
+	return &Node[P, V]{owner: o, Priority: priority, Value: value}
+}
+
+func (node *Node[P, V]) belongsTo(o *owner) bool {
+	return node != nil && node.owner != nil && node.owner.find() == o.find()
+}
+
+// release detaches a node that has left its heap.
+func (node *Node[P, V]) release() {
+	node.next, node.prev, node.child, node.parent = node, node, nil, nil
+	node.tree = nil
+	node.degree, node.marked, node.owner = 0, false, nil
+}
+
+// owner identifies a heap for the nodes enqueued on it.  Merging heaps
+// links the owner of the emptied heap to the owner of the other, so the
+// nodes of both can be recognized without visiting each of them.
+type owner struct {
+	parent *owner
+}
+
+func (o *owner) find() *owner {
+	for o.parent != nil {
+		if o.parent.parent != nil {
+			o.parent = o.parent.parent
+		}
+		o = o.parent
+	}
+	return o
+}
+
+// adopt makes the nodes of other belong to o.  It returns false if
+// they already did.
+func adopt(o, other *owner) bool {
+	root, otherRoot := o.find(), other.find()
+	if root == otherRoot {
+		return false
+	}
+	otherRoot.parent = root
+	return true
+}
+
+func checkNode[P, V any](heap Heap[P, V], node *Node[P, V], operation string) error {
+	if heap.IsEmpty() {
+		return EmptyHeapError("Cannot " + operation + " in an empty heap")
+	}
+	if node == nil {
+		return NilError("Cannot " + operation + ": given node is nil")
+	}
+	if !heap.Contains(node) {
+		return ForeignNodeError("Cannot " + operation + ": given node is not in this heap")
+	}
+	return nil
+}
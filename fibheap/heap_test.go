@@ -0,0 +1,255 @@
+package fibheap
+
+// Conformance tests and comparative benchmarks for the Heap implementations
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var heapKinds = []struct {
+	name string
+	new  func() Heap[float64, int]
+}{
+	{"Fibonacci", func() Heap[float64, int] { return NewFibHeap[float64, int](lessFloat) }},
+	{"Pairing", func() Heap[float64, int] { return NewPairingHeap[float64, int](lessFloat) }},
+	{"Binomial", func() Heap[float64, int] { return NewBinomialHeap[float64, int](lessFloat) }},
+	{"Binary", func() Heap[float64, int] { return NewDaryHeap[float64, int](2, lessFloat) }},
+	{"4-ary", func() Heap[float64, int] { return NewDaryHeap[float64, int](4, lessFloat) }},
+}
+
+func forEachHeap(t *testing.T, test func(t *testing.T, newHeap func() Heap[float64, int])) {
+	for _, kind := range heapKinds {
+		kind := kind
+		t.Run(kind.name, func(t *testing.T) {
+			test(t, kind.new)
+		})
+	}
+}
+
+// drain dequeues every node, checking they come out in priority order.
+func drain(t *testing.T, heap Heap[float64, int]) []float64 {
+	var priorities []float64
+	for !heap.IsEmpty() {
+		min, err := heap.Min()
+		require.NoError(t, err)
+		dequeued, err := heap.DequeueMin()
+		require.NoError(t, err)
+		require.Equal(t, min, dequeued)
+		assert.False(t, heap.Contains(dequeued))
+		priorities = append(priorities, dequeued.Priority)
+	}
+	assert.True(t, sort.Float64sAreSorted(priorities))
+	return priorities
+}
+
+func TestHeapEnqueueDequeueMin(t *testing.T) {
+	forEachHeap(t, func(t *testing.T, newHeap func() Heap[float64, int]) {
+		heap := newHeap()
+		for i, p := range NumberSequence1 {
+			node := heap.Enqueue(p, i)
+			assert.True(t, heap.Contains(node))
+		}
+		assert.Equal(t, uint(len(NumberSequence1)), heap.Size())
+
+		sorted := append([]float64(nil), NumberSequence1[:]...)
+		sort.Float64s(sorted)
+		assert.Equal(t, sorted, drain(t, heap))
+
+		_, err := heap.Min()
+		assert.IsType(t, EmptyHeapError(""), err)
+		_, err = heap.DequeueMin()
+		assert.IsType(t, EmptyHeapError(""), err)
+	})
+}
+
+func TestHeapRandomOperations(t *testing.T) {
+	forEachHeap(t, func(t *testing.T, newHeap func() Heap[float64, int]) {
+		r := rand.New(rand.NewSource(42))
+		heap := newHeap()
+		live := map[*Node[float64, int]]bool{}
+		pick := func() *Node[float64, int] {
+			for node := range live {
+				return node
+			}
+			return nil
+		}
+
+		for i := 0; i < 5000; i++ {
+			switch op := r.Intn(10); {
+			case op < 4 || len(live) == 0:
+				live[heap.Enqueue(r.Float64()*1000, i)] = true
+			case op < 6:
+				node := pick()
+				_, err := heap.DecreaseKey(node, node.Priority-r.Float64()*100)
+				require.NoError(t, err)
+			case op < 8:
+				node := pick()
+				require.NoError(t, heap.Delete(node))
+				assert.False(t, heap.Contains(node))
+				delete(live, node)
+			default:
+				min, err := heap.DequeueMin()
+				require.NoError(t, err)
+				require.True(t, live[min])
+				for node := range live {
+					require.False(t, node.Priority < min.Priority)
+				}
+				delete(live, min)
+			}
+			require.Equal(t, uint(len(live)), heap.Size())
+		}
+
+		for node := range live {
+			require.True(t, heap.Contains(node))
+		}
+		assert.Len(t, drain(t, heap), len(live))
+	})
+}
+
+func TestHeapErrors(t *testing.T) {
+	forEachHeap(t, func(t *testing.T, newHeap func() Heap[float64, int]) {
+		heap, other := newHeap(), newHeap()
+		foreign := other.Enqueue(1, 0)
+
+		_, err := heap.DecreaseKey(foreign, 0)
+		assert.IsType(t, EmptyHeapError(""), err)
+		assert.IsType(t, EmptyHeapError(""), heap.Delete(foreign))
+
+		node := heap.Enqueue(5, 0)
+		_, err = heap.DecreaseKey(nil, 0)
+		assert.IsType(t, NilError(""), err)
+		assert.IsType(t, NilError(""), heap.Delete(nil))
+		_, err = heap.DecreaseKey(foreign, 0)
+		assert.IsType(t, ForeignNodeError(""), err)
+		assert.IsType(t, ForeignNodeError(""), heap.Delete(foreign))
+		_, err = heap.DecreaseKey(node, 5)
+		assert.Error(t, err)
+		assert.IsType(t, NilError(""), heap.Merge(nil))
+
+		require.NoError(t, heap.Delete(node))
+		heap.Enqueue(6, 0)
+		assert.IsType(t, ForeignNodeError(""), heap.Delete(node))
+	})
+}
+
+func TestHeapMerge(t *testing.T) {
+	forEachHeap(t, func(t *testing.T, newHeap func() Heap[float64, int]) {
+		heap, other := newHeap(), newHeap()
+		var nodes []*Node[float64, int]
+		for i, p := range NumberSequence1 {
+			if i%2 == 0 {
+				nodes = append(nodes, heap.Enqueue(p, i))
+			} else {
+				nodes = append(nodes, other.Enqueue(p, i))
+			}
+		}
+
+		require.NoError(t, heap.Merge(other))
+		assert.True(t, other.IsEmpty())
+		assert.Equal(t, uint(len(NumberSequence1)), heap.Size())
+		for _, node := range nodes {
+			assert.True(t, heap.Contains(node))
+			assert.False(t, other.Contains(node))
+		}
+
+		// merging a heap into itself changes nothing
+		require.NoError(t, heap.Merge(heap))
+		assert.Equal(t, uint(len(NumberSequence1)), heap.Size())
+
+		// nodes that came from other can be updated through heap
+		_, err := heap.DecreaseKey(nodes[1], -1e11)
+		require.NoError(t, err)
+		min, err := heap.Min()
+		require.NoError(t, err)
+		assert.Equal(t, nodes[1], min)
+		require.NoError(t, heap.Delete(nodes[3]))
+
+		// the emptied heap is still usable, and its new nodes are its own
+		fresh := other.Enqueue(0, 0)
+		assert.True(t, other.Contains(fresh))
+		assert.False(t, heap.Contains(fresh))
+
+		assert.Len(t, drain(t, heap), len(NumberSequence1)-1)
+	})
+}
+
+func TestHeapMergeIncompatible(t *testing.T) {
+	for _, a := range heapKinds {
+		for _, b := range heapKinds {
+			heap, other := a.new(), b.new()
+			other.Enqueue(1, 0)
+			err := heap.Merge(other)
+			if fmt.Sprintf("%T", heap) == fmt.Sprintf("%T", other) {
+				assert.NoError(t, err, "%s into %s", b.name, a.name)
+			} else {
+				assert.IsType(t, IncompatibleHeapError(""), err, "%s into %s", b.name, a.name)
+				assert.Equal(t, uint(1), other.Size())
+			}
+		}
+	}
+}
+
+func benchmarkHeaps(b *testing.B, bench func(b *testing.B, newHeap func() Heap[float64, int])) {
+	for _, kind := range heapKinds {
+		kind := kind
+		b.Run(kind.name, func(b *testing.B) {
+			bench(b, kind.new)
+		})
+	}
+}
+
+func BenchmarkHeap_EnqueueDequeueMin(b *testing.B) {
+	benchmarkHeaps(b, func(b *testing.B, newHeap func() Heap[float64, int]) {
+		slice := make([]float64, 0, b.N)
+		for i := 0; i < b.N; i++ {
+			slice = append(slice, 2*1e8*(rand.Float64()-0.5))
+		}
+		heap := newHeap()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			heap.Enqueue(slice[i], i)
+		}
+		for i := 0; i < b.N; i++ {
+			heap.DequeueMin()
+		}
+	})
+}
+
+func BenchmarkHeap_DecreaseKey(b *testing.B) {
+	benchmarkHeaps(b, func(b *testing.B, newHeap func() Heap[float64, int]) {
+		heap := newHeap()
+		nodes := make([]*Node[float64, int], 0, b.N)
+		for i := 0; i < b.N; i++ {
+			nodes = append(nodes, heap.Enqueue(2*1e8*(rand.Float64()-0.5), i))
+		}
+
+		b.ResetTimer()
+		for i, node := range nodes {
+			heap.DecreaseKey(node, node.Priority-float64(b.N-i))
+		}
+	})
+}
+
+func BenchmarkHeap_Merge(b *testing.B) {
+	benchmarkHeaps(b, func(b *testing.B, newHeap func() Heap[float64, int]) {
+		heap := newHeap()
+		for i := 0; i < 1000; i++ {
+			heap.Enqueue(2*1e8*(rand.Float64()-0.5), i)
+		}
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			other := newHeap()
+			other.Enqueue(2*1e8*(rand.Float64()-0.5), i)
+			other.Enqueue(2*1e8*(rand.Float64()-0.5), i)
+			heap.Merge(other)
+		}
+	})
+}
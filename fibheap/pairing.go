@@ -0,0 +1,213 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fibheap
+
+import "fmt"
+
+var _ Heap[int, int] = (*PairingHeap[int, int])(nil)
+
+// PairingHeap is a pairing heap, as described by Fredman, Sedgewick,
+// Sleator and Tarjan.  It is a single heap-ordered tree of arbitrary
+// shape: melding two trees makes the larger root the leftmost child of
+// the smaller one, and DequeueMin melds the children of the removed
+// root pairwise from left to right and then one by one from right to
+// left.  Enqueue, Min and Merge run in O(1) time, DequeueMin and Delete
+// in amortized O(lg n), and DecreaseKey in amortized o(lg n).  Its
+// simplicity usually makes it faster in practice than a FibHeap.
+//
+// Each node's child points at its leftmost child and next at its right
+// sibling, while prev points at its left sibling or, for the leftmost
+// child, at its parent.
+type PairingHeap[P, V any] struct {
+	root  *Node[P, V]
+	size  uint
+	less  func(a, b P) bool
+	owner *owner
+}
+
+// NewPairingHeap creates a new, empty pairing heap in which a node has
+// priority over another when less reports its priority is less than
+// the other's.
+func NewPairingHeap[P, V any](less func(a, b P) bool) *PairingHeap[P, V] {
+	return &PairingHeap[P, V]{less: less, owner: &owner{}}
+}
+
+// Enqueue adds a value with the given priority to the heap and returns
+// its node.
+func (heap *PairingHeap[P, V]) Enqueue(priority P, value V) *Node[P, V] {
+	node := newNode(heap.owner, priority, value)
+	heap.root = heap.meld(heap.root, node)
+	heap.size++
+	return node
+}
+
+// Min returns the node with the least priority in the heap.
+func (heap *PairingHeap[P, V]) Min() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Trying to get minimum element of empty heap")
+	}
+	return heap.root, nil
+}
+
+// IsEmpty answers: is the heap empty?
+func (heap *PairingHeap[P, V]) IsEmpty() bool {
+	return heap.size == 0
+}
+
+// Size gives the number of elements in the heap
+func (heap *PairingHeap[P, V]) Size() uint {
+	return heap.size
+}
+
+// Contains reports whether the node is currently in the heap.
+func (heap *PairingHeap[P, V]) Contains(node *Node[P, V]) bool {
+	return node.belongsTo(heap.owner)
+}
+
+// DequeueMin removes and returns the node with the least priority in
+// the heap.
+func (heap *PairingHeap[P, V]) DequeueMin() (*Node[P, V], error) {
+	if heap.IsEmpty() {
+		return nil, EmptyHeapError("Cannot dequeue minimum of empty heap")
+	}
+
+	min := heap.root
+	heap.root = heap.mergePairs(min.child)
+	heap.size--
+	min.release()
+	return min, nil
+}
+
+// DecreaseKey gives the node a new priority, which must be less than
+// its current one, and returns the node if successfully set.
+func (heap *PairingHeap[P, V]) DecreaseKey(node *Node[P, V], newPriority P) (*Node[P, V], error) {
+	if err := checkNode[P, V](heap, node, "decrease key"); err != nil {
+		return nil, err
+	}
+
+	if !heap.less(newPriority, node.Priority) {
+		return nil, fmt.Errorf("The given new priority: %v, is larger than or equal to the old: %v",
+			newPriority, node.Priority)
+	}
+
+	node.Priority = newPriority
+	if node != heap.root {
+		heap.detach(node)
+		heap.root = heap.meld(heap.root, node)
+	}
+	return node, nil
+}
+
+// Delete removes the node from the heap.
+func (heap *PairingHeap[P, V]) Delete(node *Node[P, V]) error {
+	if err := checkNode[P, V](heap, node, "delete"); err != nil {
+		return err
+	}
+
+	if node == heap.root {
+		heap.DequeueMin()
+		return nil
+	}
+
+	heap.detach(node)
+	heap.root = heap.meld(heap.root, heap.mergePairs(node.child))
+	heap.size--
+	node.release()
+	return nil
+}
+
+// Merge moves all of the nodes of other, which must also be a
+// PairingHeap, into this heap, leaving other empty but still usable.
+// Nodes of other become nodes of this heap.  Both heaps must order
+// priorities with the same comparator.
+func (heap *PairingHeap[P, V]) Merge(other Heap[P, V]) error {
+	if other == nil {
+		return NilError("The heap to merge is nil. Cannot merge")
+	}
+	o, ok := other.(*PairingHeap[P, V])
+	if !ok {
+		return IncompatibleHeapError("Cannot merge a PairingHeap with another kind of heap")
+	}
+	if !adopt(heap.owner, o.owner) {
+		return nil
+	}
+
+	heap.root = heap.meld(heap.root, o.root)
+	heap.size += o.size
+
+	o.owner = &owner{}
+	o.root = nil
+	o.size = 0
+	return nil
+}
+
+// meld combines two detached trees, making the one with the larger
+// root the leftmost child of the other.
+func (heap *PairingHeap[P, V]) meld(a, b *Node[P, V]) *Node[P, V] {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if heap.less(b.Priority, a.Priority) {
+		a, b = b, a
+	}
+
+	b.prev = a
+	b.next = a.child
+	if a.child != nil {
+		a.child.prev = b
+	}
+	a.child = b
+	return a
+}
+
+// mergePairs melds a list of siblings into a single tree with the
+// standard two-pass method.
+func (heap *PairingHeap[P, V]) mergePairs(first *Node[P, V]) *Node[P, V] {
+	var pairs []*Node[P, V]
+	for first != nil {
+		a, b := first, first.next
+		if b == nil {
+			first = nil
+		} else {
+			first = b.next
+			b.next, b.prev = nil, nil
+		}
+		a.next, a.prev = nil, nil
+		pairs = append(pairs, heap.meld(a, b))
+	}
+
+	var root *Node[P, V]
+	for i := len(pairs) - 1; i >= 0; i-- {
+		root = heap.meld(pairs[i], root)
+	}
+	return root
+}
+
+// detach cuts the subtree rooted at a node other than the root out of
+// the tree.
+func (heap *PairingHeap[P, V]) detach(node *Node[P, V]) {
+	if node.prev.child == node {
+		node.prev.child = node.next
+	} else {
+		node.prev.next = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	}
+	node.next, node.prev = nil, nil
+}
@@ -0,0 +1,276 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Workiva/go-datastructures/queue"
+)
+
+var (
+	// ErrExecutorShutdown is returned for tasks submitted to an Executor
+	// that is shutting down.
+	ErrExecutorShutdown = errors.New("executor shut down")
+
+	// ErrExecutorFull is returned for tasks submitted to an Executor
+	// whose submission queue is full.
+	ErrExecutorFull = errors.New("executor queue full")
+)
+
+// TaskOption configures a single task submitted to an Executor.
+type TaskOption func(*taskConfig)
+
+type taskConfig struct {
+	priority int
+	deadline time.Time
+}
+
+// WithPriority sets the priority of a task.  Queued tasks with a higher
+// priority are started first, and tasks of equal priority are started
+// in the order they were submitted.  The default priority is 0.
+func WithPriority(priority int) TaskOption {
+	return func(c *taskConfig) {
+		c.priority = priority
+	}
+}
+
+// WithDeadline fails a task's future with context.DeadlineExceeded if
+// it has not completed by deadline, whether or not it has started.
+func WithDeadline(deadline time.Time) TaskOption {
+	return func(c *taskConfig) {
+		c.deadline = deadline
+	}
+}
+
+// WithTimeout is WithDeadline relative to the time of submission.
+func WithTimeout(timeout time.Duration) TaskOption {
+	return func(c *taskConfig) {
+		c.deadline = time.Now().Add(timeout)
+	}
+}
+
+// task is a queued unit of work.  It hides the type of the future it
+// fulfills so tasks of any type can share the queue.
+type task struct {
+	priority int
+	seq      uint64
+	run      func()
+	settled  func() bool
+	// queued is set while the task counts toward Executor.queued and is
+	// guarded by the executor's lock.
+	queued bool
+}
+
+// Compare implements queue.Item, ordering higher priorities first and
+// then earlier submissions first.
+func (t *task) Compare(other queue.Item) int {
+	o := other.(*task)
+	switch {
+	case t.priority > o.priority:
+		return -1
+	case t.priority < o.priority:
+		return 1
+	case t.seq < o.seq:
+		return -1
+	case t.seq > o.seq:
+		return 1
+	}
+	return 0
+}
+
+// Executor runs tasks on a bounded pool of workers, returning a future
+// for the result of each.  Tasks wait in a priority queue until a
+// worker is free.
+type Executor struct {
+	queue     *queue.PriorityQueue
+	ctx       context.Context
+	cancel    context.CancelFunc
+	workers   sync.WaitGroup
+	tasks     sync.WaitGroup
+	lock      sync.Mutex
+	shutdown  bool
+	seq       uint64
+	queued    int
+	queueSize int
+}
+
+// NewExecutor starts an Executor with the given number of workers.  If
+// queueSize is greater than zero, at most that many tasks may wait for
+// a worker and further submissions fail with ErrExecutorFull.
+func NewExecutor(workers, queueSize int) *Executor {
+	if workers < 1 {
+		workers = 1
+	}
+
+	e := &Executor{
+		queue:     queue.NewPriorityQueue(queueSize, true),
+		queueSize: queueSize,
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	e.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+// Submit queues fn to be run on e and returns a future for its result.
+// The context passed to fn is done if the task's deadline passes, its
+// future is canceled or the executor is shut down without draining.
+// Canceling the future of a task that has not started yet keeps it
+// from ever starting.  This is a function rather than a method of
+// Executor as methods cannot have type parameters.
+func Submit[T any](e *Executor, fn func(context.Context) (T, error), options ...TaskOption) *GenericFuture[T] {
+	var config taskConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	ctx, release := e.ctx, context.CancelFunc(nil)
+	if !config.deadline.IsZero() {
+		ctx, release = context.WithDeadline(ctx, config.deadline)
+	}
+
+	f := newGenericFuture[T](ctx)
+	t := &task{
+		priority: config.priority,
+		run: func() {
+			v, err := fn(f.ctx)
+			f.finish(v, err)
+		},
+		settled: f.HasResult,
+	}
+	// a task settled while queued no longer takes up room in the queue
+	f.release = func() {
+		if release != nil {
+			release()
+		}
+		e.unqueue(t)
+	}
+	f.watch()
+
+	if err := e.submit(t); err != nil {
+		f.abort(err)
+	}
+	return f
+}
+
+func (e *Executor) submit(t *task) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.shutdown {
+		return ErrExecutorShutdown
+	}
+	if e.queueSize > 0 && e.queued >= e.queueSize {
+		return ErrExecutorFull
+	}
+	if t.settled() {
+		return nil
+	}
+
+	t.seq = e.seq
+	e.seq++
+	e.queued++
+	t.queued = true
+	e.tasks.Add(1)
+	return e.queue.Put(t)
+}
+
+func (e *Executor) work() {
+	defer e.workers.Done()
+
+	for {
+		items, err := e.queue.Get(1)
+		if err != nil {
+			return
+		}
+
+		t := items[0].(*task)
+		e.unqueue(t)
+
+		// tasks canceled or past their deadline while queued are skipped
+		if !t.settled() {
+			t.run()
+		}
+		e.tasks.Done()
+	}
+}
+
+// unqueue stops t from counting toward the queued tasks, if it still
+// does.
+func (e *Executor) unqueue(t *task) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if t.queued {
+		t.queued = false
+		e.queued--
+	}
+}
+
+// Queued returns the number of tasks waiting for a worker.
+func (e *Executor) Queued() int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.queued
+}
+
+// Shutdown stops the executor from accepting tasks and waits for every
+// queued and running task to complete before stopping the workers.  If
+// ctx is done first, every outstanding task is canceled as by
+// ShutdownNow and Shutdown returns ctx.Err() once the workers stop.
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.lock.Lock()
+	e.shutdown = true
+	e.lock.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		e.tasks.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+		e.cancel()
+		<-drained
+	}
+
+	e.queue.Dispose()
+	e.workers.Wait()
+	e.cancel()
+	return err
+}
+
+// ShutdownNow stops the executor from accepting tasks and cancels every
+// outstanding task, failing their futures with context.Canceled.  Queued
+// tasks are never started.  It returns once every running task has
+// returned, so tasks should honor their context.
+func (e *Executor) ShutdownNow() {
+	e.cancel()
+	e.Shutdown(context.Background())
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutorSubmit(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(2, 0)
+
+	f := Submit(e, func(ctx context.Context) (int, error) {
+		return 5, nil
+	})
+	v, err := f.GetResult()
+	assert.Nil(err)
+	assert.Equal(5, v)
+
+	g := Submit(e, func(ctx context.Context) (string, error) {
+		return "", errTest
+	})
+	_, err = g.GetResult()
+	assert.Equal(errTest, err)
+
+	assert.Nil(e.Shutdown(context.Background()))
+}
+
+func TestExecutorPriority(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 0)
+
+	// occupy the only worker so the rest queue up
+	block := make(chan struct{})
+	Submit(e, func(ctx context.Context) (int, error) {
+		<-block
+		return 0, nil
+	})
+	for e.Queued() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var lock sync.Mutex
+	var order []int
+	record := func(i int) func(context.Context) (int, error) {
+		return func(ctx context.Context) (int, error) {
+			lock.Lock()
+			order = append(order, i)
+			lock.Unlock()
+			return i, nil
+		}
+	}
+
+	Submit(e, record(1), WithPriority(1))
+	Submit(e, record(2))
+	Submit(e, record(3), WithPriority(5))
+	Submit(e, record(4), WithPriority(1))
+	assert.Equal(4, e.Queued())
+
+	close(block)
+	assert.Nil(e.Shutdown(context.Background()))
+	assert.Equal([]int{3, 1, 4, 2}, order)
+}
+
+func TestExecutorQueueFull(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 1)
+	defer e.ShutdownNow()
+
+	block := make(chan struct{})
+	defer close(block)
+	Submit(e, func(ctx context.Context) (int, error) {
+		<-block
+		return 0, nil
+	})
+	for e.Queued() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	Submit(e, func(ctx context.Context) (int, error) { return 1, nil })
+	_, err := Submit(e, func(ctx context.Context) (int, error) {
+		return 2, nil
+	}).GetResult()
+	assert.Equal(ErrExecutorFull, err)
+}
+
+func TestExecutorQueueFullCanceled(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 1)
+	defer e.ShutdownNow()
+
+	block := make(chan struct{})
+	defer close(block)
+	Submit(e, func(ctx context.Context) (int, error) {
+		<-block
+		return 0, nil
+	})
+	for e.Queued() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// a canceled task frees its place in the queue
+	Submit(e, func(ctx context.Context) (int, error) { return 1, nil }).Cancel()
+	assert.Zero(e.Queued())
+
+	f := Submit(e, func(ctx context.Context) (int, error) { return 2, nil })
+	assert.False(f.HasResult())
+	assert.Equal(1, e.Queued())
+}
+
+func TestExecutorDeadline(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 0)
+
+	f := Submit(e, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, WithTimeout(10*time.Millisecond))
+
+	// expires while still queued behind f and never runs
+	ran := false
+	g := Submit(e, func(ctx context.Context) (int, error) {
+		ran = true
+		return 0, nil
+	}, WithTimeout(5*time.Millisecond))
+
+	_, err := f.GetResult()
+	assert.Equal(context.DeadlineExceeded, err)
+	_, err = g.GetResult()
+	assert.Equal(context.DeadlineExceeded, err)
+
+	assert.Nil(e.Shutdown(context.Background()))
+	assert.False(ran)
+}
+
+func TestExecutorCancelQueued(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 0)
+
+	block := make(chan struct{})
+	Submit(e, func(ctx context.Context) (int, error) {
+		<-block
+		return 0, nil
+	})
+
+	ran := false
+	f := Submit(e, func(ctx context.Context) (int, error) {
+		ran = true
+		return 0, nil
+	})
+	f.Cancel()
+	_, err := f.GetResult()
+	assert.Equal(ErrFutureCanceled, err)
+
+	close(block)
+	assert.Nil(e.Shutdown(context.Background()))
+	assert.False(ran)
+}
+
+func TestExecutorShutdownDrains(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(2, 0)
+
+	fs := make([]*GenericFuture[int], 10)
+	for i := range fs {
+		i := i
+		fs[i] = Submit(e, func(ctx context.Context) (int, error) {
+			time.Sleep(time.Millisecond)
+			return i, nil
+		})
+	}
+
+	assert.Nil(e.Shutdown(context.Background()))
+	for i, f := range fs {
+		assert.True(f.HasResult())
+		v, err := f.GetResult()
+		assert.Nil(err)
+		assert.Equal(i, v)
+	}
+
+	_, err := Submit(e, func(ctx context.Context) (int, error) {
+		return 0, nil
+	}).GetResult()
+	assert.Equal(ErrExecutorShutdown, err)
+}
+
+func TestExecutorShutdownNow(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 0)
+
+	running := Submit(e, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	queued := Submit(e, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+
+	e.ShutdownNow()
+	_, err := running.GetResult()
+	assert.Equal(context.Canceled, err)
+	_, err = queued.GetResult()
+	assert.Equal(context.Canceled, err)
+}
+
+func TestExecutorShutdownTimeout(t *testing.T) {
+	assert := assert.New(t)
+	e := NewExecutor(1, 0)
+
+	f := Submit(e, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(context.DeadlineExceeded, e.Shutdown(ctx))
+	_, err := f.GetResult()
+	assert.Equal(context.Canceled, err)
+}
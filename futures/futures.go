@@ -24,6 +24,7 @@ to the consumer.
 package futures
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -45,6 +46,9 @@ type Future struct {
 	err       error
 	lock      sync.Mutex
 	wg        sync.WaitGroup
+	done      chan struct{}
+	canceled  chan struct{}
+	callbacks []func(interface{}, error)
 }
 
 // GetResult will immediately fetch the result if it exists
@@ -61,6 +65,54 @@ func (f *Future) GetResult() (interface{}, error) {
 	return f.item, f.err
 }
 
+// GetResultContext will immediately fetch the result if it exists
+// or wait on the result until it is ready or ctx is done, in which case
+// ctx.Err() is returned.  Giving up on the wait does not cancel the
+// future.
+func (f *Future) GetResultContext(ctx context.Context) (interface{}, error) {
+	select {
+	case <-f.done:
+		return f.item, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// OnComplete registers a callback to be called exactly once with the
+// result of the future.  If the future already has a result the
+// callback is called immediately on the calling goroutine, otherwise
+// it is called on the goroutine that completes the future.
+func (f *Future) OnComplete(callback func(interface{}, error)) {
+	f.lock.Lock()
+	if f.triggered {
+		f.lock.Unlock()
+		callback(f.item, f.err)
+		return
+	}
+	f.callbacks = append(f.callbacks, callback)
+	f.lock.Unlock()
+}
+
+// Cancel fails the future with ErrFutureCanceled if it does not already
+// have a result, returning a bool indicating if it did so.  The producer
+// is notified through Canceled and the future stops receiving on its
+// Completer, so a producer sending on an unbuffered Completer should
+// select on Canceled as well to avoid blocking forever.
+func (f *Future) Cancel() bool {
+	if !f.setItem(nil, ErrFutureCanceled) {
+		return false
+	}
+	close(f.canceled)
+	return true
+}
+
+// Canceled returns a channel that is closed when the future is canceled,
+// either by Cancel or by the context passed to NewWithContext, so that
+// producers can stop working on a result nobody will receive.
+func (f *Future) Canceled() <-chan struct{} {
+	return f.canceled
+}
+
 // HasResult will return true iff the result exists
 func (f *Future) HasResult() bool {
 	f.lock.Lock()
@@ -69,13 +121,27 @@ func (f *Future) HasResult() bool {
 	return hasResult
 }
 
-func (f *Future) setItem(item interface{}, err error) {
+// setItem sets the result of the future and runs any callbacks.  Only
+// the first call has any effect, and it returns true.
+func (f *Future) setItem(item interface{}, err error) bool {
 	f.lock.Lock()
+	if f.triggered {
+		f.lock.Unlock()
+		return false
+	}
 	f.triggered = true
 	f.item = item
 	f.err = err
+	callbacks := f.callbacks
+	f.callbacks = nil
+	close(f.done)
 	f.lock.Unlock()
 	f.wg.Done()
+
+	for _, callback := range callbacks {
+		callback(item, err)
+	}
+	return true
 }
 
 func listenForResult(f *Future, ch Completer, timeout time.Duration, wg *sync.WaitGroup) {
@@ -87,7 +153,31 @@ func listenForResult(f *Future, ch Completer, timeout time.Duration, wg *sync.Wa
 		t.Stop() // we want to trigger GC of this timer as soon as it's no longer needed
 	case <-t.C:
 		f.setItem(nil, fmt.Errorf(`timeout after %f seconds`, timeout.Seconds()))
+	case <-f.canceled:
+		t.Stop()
+	}
+}
+
+func listenForResultContext(ctx context.Context, f *Future, ch Completer, wg *sync.WaitGroup) {
+	wg.Done()
+	select {
+	case item := <-ch:
+		f.setItem(item, nil)
+	case <-ctx.Done():
+		if f.setItem(nil, ctx.Err()) {
+			close(f.canceled)
+		}
+	case <-f.canceled:
+	}
+}
+
+func newFuture() *Future {
+	f := &Future{
+		done:     make(chan struct{}),
+		canceled: make(chan struct{}),
 	}
+	f.wg.Add(1)
+	return f
 }
 
 // New is the constructor to generate a new future.  Pass the completed
@@ -95,11 +185,22 @@ func listenForResult(f *Future, ch Completer, timeout time.Duration, wg *sync.Wa
 // notified.  If timeout is hit before toComplete is called,
 // any listeners will get passed an error.
 func New(completer Completer, timeout time.Duration) *Future {
-	f := &Future{}
-	f.wg.Add(1)
+	f := newFuture()
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go listenForResult(f, completer, timeout, &wg)
 	wg.Wait()
 	return f
 }
+
+// NewWithContext is like New, but rather than a fixed timeout the
+// future waits on toComplete until ctx is done, at which point any
+// listeners get passed ctx.Err() and the future is considered canceled.
+func NewWithContext(ctx context.Context, completer Completer) *Future {
+	f := newFuture()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go listenForResultContext(ctx, f, completer, &wg)
+	wg.Wait()
+	return f
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package futures
 
 import (
+	"context"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -78,6 +80,129 @@ func TestTimeout(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestGetResultContext(t *testing.T) {
+	completer := make(chan interface{})
+	f := New(completer, time.Duration(30*time.Minute))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	result, err := f.GetResultContext(ctx)
+	assert.Nil(t, result)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.False(t, f.HasResult())
+
+	completer <- `test`
+	result, err = f.GetResultContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, `test`, result)
+}
+
+func TestCancel(t *testing.T) {
+	completer := make(chan interface{})
+	f := New(completer, time.Duration(30*time.Minute))
+
+	assert.True(t, f.Cancel())
+	assert.False(t, f.Cancel())
+	<-f.Canceled()
+
+	result, err := f.GetResult()
+	assert.Nil(t, result)
+	assert.Equal(t, ErrFutureCanceled, err)
+
+	// a producer watching Canceled is not left blocked on its send
+	select {
+	case completer <- `test`:
+	case <-f.Canceled():
+	}
+
+	result, err = f.GetResult()
+	assert.Nil(t, result)
+	assert.Equal(t, ErrFutureCanceled, err)
+}
+
+func TestCancelAfterResult(t *testing.T) {
+	completer := make(chan interface{})
+	f := New(completer, time.Duration(30*time.Minute))
+
+	completer <- `test`
+	f.GetResult()
+	assert.False(t, f.Cancel())
+
+	select {
+	case <-f.Canceled():
+		t.Fatal("completed future should not be canceled")
+	default:
+	}
+}
+
+func TestNewWithContext(t *testing.T) {
+	completer := make(chan interface{})
+	ctx, cancel := context.WithCancel(context.Background())
+	f := NewWithContext(ctx, completer)
+
+	completer <- `test`
+	result, err := f.GetResult()
+	assert.Nil(t, err)
+	assert.Equal(t, `test`, result)
+	cancel()
+
+	completer = make(chan interface{})
+	ctx, cancel = context.WithCancel(context.Background())
+	f = NewWithContext(ctx, completer)
+	cancel()
+
+	result, err = f.GetResult()
+	assert.Nil(t, result)
+	assert.Equal(t, context.Canceled, err)
+	<-f.Canceled()
+
+	// the producer may close the completer instead of sending
+	close(completer)
+}
+
+func TestOnComplete(t *testing.T) {
+	completer := make(chan interface{})
+	f := New(completer, time.Duration(30*time.Minute))
+
+	var calls int32
+	results := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		f.OnComplete(func(item interface{}, err error) {
+			atomic.AddInt32(&calls, 1)
+			assert.Nil(t, err)
+			results <- item
+		})
+	}
+
+	completer <- `test`
+	assert.Equal(t, `test`, <-results)
+	assert.Equal(t, `test`, <-results)
+
+	// registered after completion, so called immediately
+	called := false
+	f.OnComplete(func(item interface{}, err error) {
+		called = true
+		assert.Equal(t, `test`, item)
+	})
+	assert.True(t, called)
+
+	f.Cancel()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOnCompleteCanceled(t *testing.T) {
+	completer := make(chan interface{})
+	f := New(completer, time.Duration(30*time.Minute))
+
+	errs := make(chan error, 1)
+	f.OnComplete(func(item interface{}, err error) {
+		errs <- err
+	})
+	f.Cancel()
+	assert.Equal(t, ErrFutureCanceled, <-errs)
+	close(completer)
+}
+
 func BenchmarkFuture(b *testing.B) {
 	completer := make(chan interface{})
 	timeout := time.Duration(30 * time.Minute)
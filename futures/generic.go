@@ -0,0 +1,469 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrNoFutures is returned by combinators that cannot settle without
+// at least one input future.
+var ErrNoFutures = errors.New("no futures provided")
+
+// AggregateError is returned by Any when every input future failed.
+type AggregateError struct {
+	// Errors holds the error of each input, in input order.
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+	parts := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		parts[i] = err.Error()
+	}
+	return "all futures failed: " + strings.Join(parts, "; ")
+}
+
+// Settled is the outcome of a single future as reported by AllSettled.
+type Settled[T any] struct {
+	Value T
+	Err   error
+}
+
+// canceler is implemented by every GenericFuture regardless of its type
+// parameter, which lets cancellation propagate across types.
+type canceler interface {
+	Cancel()
+}
+
+// GenericFuture is a future holding a value of type T.  It is completed
+// exactly once, by its Promise, by a combinator, or by being canceled.
+// Like Selectable, it exposes WaitChan so it can be used in a select.
+// The type is named GenericFuture as Future is taken by the untyped
+// future.
+type GenericFuture[T any] struct {
+	sel     Selectable
+	settled uint32
+	ctx     context.Context
+	cancel  context.CancelFunc
+	parents []canceler
+	// release, if set, frees resources held by the context the future
+	// was created with once it is fulfilled.
+	release context.CancelFunc
+}
+
+func newGenericFuture[T any](ctx context.Context, parents ...canceler) *GenericFuture[T] {
+	f := &GenericFuture[T]{parents: parents}
+	f.ctx, f.cancel = context.WithCancel(ctx)
+	return f
+}
+
+// WaitChan returns channel, which is closed when future is fulfilled.
+func (f *GenericFuture[T]) WaitChan() <-chan struct{} {
+	return f.sel.WaitChan()
+}
+
+// GetResult waits for the future to be fulfilled and returns its value
+// or error.
+func (f *GenericFuture[T]) GetResult() (T, error) {
+	<-f.sel.WaitChan()
+	return f.result()
+}
+
+// Get waits for the future to be fulfilled and returns its value or
+// error, or returns ctx.Err() if ctx is done first.  Giving up on the
+// wait does not cancel the future.
+func (f *GenericFuture[T]) Get(ctx context.Context) (T, error) {
+	select {
+	case <-f.sel.WaitChan():
+		return f.result()
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// HasResult will return true iff the future is fulfilled
+func (f *GenericFuture[T]) HasResult() bool {
+	return atomic.LoadUint32(&f.sel.filled) == 1
+}
+
+// Cancel fails the future with ErrFutureCanceled if it is not already
+// fulfilled.  The cancellation is signaled to its producer through the
+// Promise's Context and propagates to every future this one was built
+// from, so canceling the result of a combinator cancels its inputs.
+func (f *GenericFuture[T]) Cancel() {
+	f.abort(ErrFutureCanceled)
+}
+
+func (f *GenericFuture[T]) result() (T, error) {
+	v, err := f.sel.GetResult()
+	value, _ := v.(T)
+	return value, err
+}
+
+// fill completes the future, returning false if it was already
+// fulfilled.
+func (f *GenericFuture[T]) fill(v T, err error) bool {
+	if !atomic.CompareAndSwapUint32(&f.settled, 0, 1) {
+		return false
+	}
+	f.sel.Fill(v, err)
+	f.cancel()
+	if f.release != nil {
+		f.release()
+	}
+	return true
+}
+
+// finish completes the future with the result of a function that was
+// given its context.  If that context ended first the result is moot
+// and the future fails with the context's error instead.
+func (f *GenericFuture[T]) finish(v T, err error) {
+	if ctxErr := f.ctx.Err(); ctxErr != nil {
+		f.abort(ctxErr)
+		return
+	}
+	f.fill(v, err)
+}
+
+// abort fails the future with err and cancels its parents.
+func (f *GenericFuture[T]) abort(err error) {
+	var zero T
+	if !f.fill(zero, err) {
+		return
+	}
+	for _, p := range f.parents {
+		p.Cancel()
+	}
+}
+
+// watch aborts the future with the context's error if its context is
+// done before it is otherwise fulfilled.
+func (f *GenericFuture[T]) watch() {
+	if f.ctx.Done() == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-f.sel.WaitChan():
+		case <-f.ctx.Done():
+			f.abort(f.ctx.Err())
+		}
+	}()
+}
+
+// Promise is the producer side of a GenericFuture.
+type Promise[T any] struct {
+	future *GenericFuture[T]
+}
+
+// NewPromise returns a Promise whose future has not been fulfilled.
+func NewPromise[T any]() *Promise[T] {
+	return &Promise[T]{future: newGenericFuture[T](context.Background())}
+}
+
+// Future returns the future this promise fulfills.
+func (p *Promise[T]) Future() *GenericFuture[T] {
+	return p.future
+}
+
+// Resolve fulfills the future with v.  Returns false if it was already
+// fulfilled, for instance because it was canceled.
+func (p *Promise[T]) Resolve(v T) bool {
+	return p.future.fill(v, nil)
+}
+
+// Reject fails the future with err.  Returns false if it was already
+// fulfilled.
+func (p *Promise[T]) Reject(err error) bool {
+	var zero T
+	return p.future.fill(zero, err)
+}
+
+// Context returns a context that is done once the future is fulfilled,
+// which lets the producer stop working on a canceled future.
+func (p *Promise[T]) Context() context.Context {
+	return p.future.ctx
+}
+
+// Go runs fn in a new goroutine and returns a future fulfilled with its
+// result.  The context passed to fn is done if ctx is done or the
+// future is canceled, either of which fails the future immediately.
+func Go[T any](ctx context.Context, fn func(context.Context) (T, error)) *GenericFuture[T] {
+	f := newGenericFuture[T](ctx)
+	f.watch()
+	go func() {
+		v, err := fn(f.ctx)
+		f.finish(v, err)
+	}()
+	return f
+}
+
+// Resolved returns a future already fulfilled with v.
+func Resolved[T any](v T) *GenericFuture[T] {
+	f := newGenericFuture[T](context.Background())
+	f.fill(v, nil)
+	return f
+}
+
+// Rejected returns a future already failed with err.
+func Rejected[T any](err error) *GenericFuture[T] {
+	var zero T
+	f := newGenericFuture[T](context.Background())
+	f.fill(zero, err)
+	return f
+}
+
+// FromSelectable adapts a Selectable to a GenericFuture.  If the value
+// it is filled with is not nil and not a T, the future fails.
+func FromSelectable[T any](s *Selectable) *GenericFuture[T] {
+	f := newGenericFuture[T](context.Background())
+	go func() {
+		v, err := s.GetResult()
+		value, ok := v.(T)
+		if err == nil && v != nil && !ok {
+			err = fmt.Errorf("futures: value of type %T is not a %T", v, value)
+		}
+		f.fill(value, err)
+	}()
+	return f
+}
+
+// Then returns a future fulfilled with the result of calling fn on the
+// value of f.  If f fails, fn is not called and the returned future
+// fails with the same error.  If ctx is done first, or the returned
+// future is canceled, it fails and f is canceled.
+func Then[T, U any](ctx context.Context, f *GenericFuture[T],
+	fn func(context.Context, T) (U, error)) *GenericFuture[U] {
+
+	next := newGenericFuture[U](ctx, f)
+	go func() {
+		select {
+		case <-f.WaitChan():
+		case <-next.ctx.Done():
+			next.abort(next.ctx.Err())
+			return
+		}
+
+		v, err := f.result()
+		if err != nil {
+			var zero U
+			next.fill(zero, err)
+			return
+		}
+		next.finish(fn(next.ctx, v))
+	}()
+	return next
+}
+
+// Map is Then for functions that cannot fail.
+func Map[T, U any](ctx context.Context, f *GenericFuture[T], fn func(T) U) *GenericFuture[U] {
+	return Then(ctx, f, func(_ context.Context, v T) (U, error) {
+		return fn(v), nil
+	})
+}
+
+// Recover returns a future that is fulfilled with the value of f, or,
+// if f fails, with the result of calling fn on its error.  Cancellation
+// behaves as it does for Then.
+func Recover[T any](ctx context.Context, f *GenericFuture[T],
+	fn func(context.Context, error) (T, error)) *GenericFuture[T] {
+
+	next := newGenericFuture[T](ctx, f)
+	go func() {
+		select {
+		case <-f.WaitChan():
+		case <-next.ctx.Done():
+			next.abort(next.ctx.Err())
+			return
+		}
+
+		v, err := f.result()
+		if err != nil {
+			next.finish(fn(next.ctx, err))
+			return
+		}
+		next.fill(v, err)
+	}()
+	return next
+}
+
+// settle calls fn with the index of each future as it is fulfilled, in
+// the order they are fulfilled, until fn returns false or ctx is done.
+// Returns false if ctx ended the wait.
+func settle[T any](ctx context.Context, fs []*GenericFuture[T], fn func(i int) bool) bool {
+	done := make(chan int, len(fs))
+	stop := make(chan struct{})
+	defer close(stop)
+
+	for i, f := range fs {
+		go func(i int, f *GenericFuture[T]) {
+			select {
+			case <-f.WaitChan():
+				done <- i
+			case <-stop:
+			}
+		}(i, f)
+	}
+
+	for range fs {
+		select {
+		case i := <-done:
+			if !fn(i) {
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+func cancelers[T any](fs []*GenericFuture[T]) []canceler {
+	parents := make([]canceler, len(fs))
+	for i, f := range fs {
+		parents[i] = f
+	}
+	return parents
+}
+
+// cancelAll cancels every future that is not yet fulfilled.
+func cancelAll[T any](fs []*GenericFuture[T]) {
+	for _, f := range fs {
+		f.Cancel()
+	}
+}
+
+// All returns a future fulfilled with the values of fs, in input order,
+// once all of them are fulfilled.  If any of them fails, the returned
+// future fails with that error and the rest are canceled.
+func All[T any](ctx context.Context, fs ...*GenericFuture[T]) *GenericFuture[[]T] {
+	next := newGenericFuture[[]T](ctx, cancelers(fs)...)
+	go func() {
+		values := make([]T, len(fs))
+		var failure error
+		ok := settle(next.ctx, fs, func(i int) bool {
+			values[i], failure = fs[i].result()
+			return failure == nil
+		})
+
+		switch {
+		case !ok:
+			next.abort(next.ctx.Err())
+		case failure != nil:
+			next.fill(nil, failure)
+			cancelAll(fs)
+		default:
+			next.fill(values, nil)
+		}
+	}()
+	return next
+}
+
+// AllSettled returns a future fulfilled with the outcome of each of fs,
+// in input order, once all of them are fulfilled.  It only fails if ctx
+// is done or it is canceled first.
+func AllSettled[T any](ctx context.Context, fs ...*GenericFuture[T]) *GenericFuture[[]Settled[T]] {
+	next := newGenericFuture[[]Settled[T]](ctx, cancelers(fs)...)
+	go func() {
+		results := make([]Settled[T], len(fs))
+		ok := settle(next.ctx, fs, func(i int) bool {
+			results[i].Value, results[i].Err = fs[i].result()
+			return true
+		})
+
+		if !ok {
+			next.abort(next.ctx.Err())
+			return
+		}
+		next.fill(results, nil)
+	}()
+	return next
+}
+
+// Any returns a future fulfilled with the value of the first of fs to
+// succeed, after which the rest are canceled.  If all of them fail, the
+// returned future fails with an *AggregateError.
+func Any[T any](ctx context.Context, fs ...*GenericFuture[T]) *GenericFuture[T] {
+	if len(fs) == 0 {
+		return Rejected[T](ErrNoFutures)
+	}
+
+	next := newGenericFuture[T](ctx, cancelers(fs)...)
+	go func() {
+		errs := make([]error, len(fs))
+		var (
+			value T
+			won   bool
+		)
+		ok := settle(next.ctx, fs, func(i int) bool {
+			v, err := fs[i].result()
+			if err != nil {
+				errs[i] = err
+				return true
+			}
+			value, won = v, true
+			return false
+		})
+
+		switch {
+		case !ok:
+			next.abort(next.ctx.Err())
+		case won:
+			next.fill(value, nil)
+			cancelAll(fs)
+		default:
+			var zero T
+			next.fill(zero, &AggregateError{Errors: errs})
+		}
+	}()
+	return next
+}
+
+// Race returns a future fulfilled with the outcome of the first of fs
+// to be fulfilled, whether it succeeded or failed, after which the rest
+// are canceled.
+func Race[T any](ctx context.Context, fs ...*GenericFuture[T]) *GenericFuture[T] {
+	if len(fs) == 0 {
+		return Rejected[T](ErrNoFutures)
+	}
+
+	next := newGenericFuture[T](ctx, cancelers(fs)...)
+	go func() {
+		var (
+			value T
+			err   error
+		)
+		ok := settle(next.ctx, fs, func(i int) bool {
+			value, err = fs[i].result()
+			return false
+		})
+
+		if !ok {
+			next.abort(next.ctx.Err())
+			return
+		}
+		next.fill(value, err)
+		cancelAll(fs)
+	}()
+	return next
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package futures
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test")
+
+func TestPromise(t *testing.T) {
+	assert := assert.New(t)
+	p := NewPromise[int]()
+	f := p.Future()
+
+	assert.False(f.HasResult())
+	select {
+	case <-f.WaitChan():
+		t.Fatal("future should not be fulfilled")
+	default:
+	}
+
+	assert.True(p.Resolve(5))
+	assert.False(p.Resolve(6))
+	assert.False(p.Reject(errTest))
+
+	<-f.WaitChan()
+	assert.True(f.HasResult())
+	v, err := f.GetResult()
+	assert.Nil(err)
+	assert.Equal(5, v)
+
+	select {
+	case <-p.Context().Done():
+	default:
+		t.Fatal("context should be done once fulfilled")
+	}
+}
+
+func TestPromiseReject(t *testing.T) {
+	assert := assert.New(t)
+	p := NewPromise[string]()
+
+	assert.True(p.Reject(errTest))
+	v, err := p.Future().GetResult()
+	assert.Equal(errTest, err)
+	assert.Equal("", v)
+}
+
+func TestGenericFutureGet(t *testing.T) {
+	assert := assert.New(t)
+	p := NewPromise[int]()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := p.Future().Get(ctx)
+	assert.Equal(context.DeadlineExceeded, err)
+
+	// giving up on the wait leaves the future untouched
+	assert.True(p.Resolve(1))
+	v, err := p.Future().Get(context.Background())
+	assert.Nil(err)
+	assert.Equal(1, v)
+}
+
+func TestGenericFutureCancel(t *testing.T) {
+	assert := assert.New(t)
+	p := NewPromise[int]()
+
+	p.Future().Cancel()
+	<-p.Context().Done()
+	assert.False(p.Resolve(1))
+
+	_, err := p.Future().GetResult()
+	assert.Equal(ErrFutureCanceled, err)
+}
+
+func TestGo(t *testing.T) {
+	assert := assert.New(t)
+
+	f := Go(context.Background(), func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	v, err := f.GetResult()
+	assert.Nil(err)
+	assert.Equal(42, v)
+
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	f = Go(ctx, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(stopped)
+		return 0, nil
+	})
+	cancel()
+	_, err = f.GetResult()
+	assert.Equal(context.Canceled, err)
+	<-stopped
+}
+
+func TestResolvedRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	v, err := Resolved("a").GetResult()
+	assert.Nil(err)
+	assert.Equal("a", v)
+
+	_, err = Rejected[string](errTest).GetResult()
+	assert.Equal(errTest, err)
+}
+
+func TestFromSelectable(t *testing.T) {
+	assert := assert.New(t)
+
+	s := NewSelectable()
+	f := FromSelectable[string](s)
+	s.SetValue("a")
+	v, err := f.GetResult()
+	assert.Nil(err)
+	assert.Equal("a", v)
+
+	s = NewSelectable()
+	s.SetValue(1)
+	_, err = FromSelectable[string](s).GetResult()
+	assert.Error(err)
+
+	s = NewSelectable()
+	s.SetError(errTest)
+	_, err = FromSelectable[string](s).GetResult()
+	assert.Equal(errTest, err)
+}
+
+func TestThenMap(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	p := NewPromise[int]()
+
+	s := Then(ctx, p.Future(), func(ctx context.Context, v int) (string, error) {
+		return strconv.Itoa(v), nil
+	})
+	l := Map(ctx, s, func(s string) int {
+		return len(s)
+	})
+
+	p.Resolve(1234)
+	v, err := s.GetResult()
+	assert.Nil(err)
+	assert.Equal("1234", v)
+
+	n, err := l.GetResult()
+	assert.Nil(err)
+	assert.Equal(4, n)
+
+	called := false
+	failed := Then(ctx, Rejected[int](errTest), func(ctx context.Context, v int) (int, error) {
+		called = true
+		return v, nil
+	})
+	_, err = failed.GetResult()
+	assert.Equal(errTest, err)
+	assert.False(called)
+}
+
+func TestThenCancelPropagates(t *testing.T) {
+	assert := assert.New(t)
+	p := NewPromise[int]()
+
+	next := Map(context.Background(), p.Future(), func(v int) int { return v })
+	next.Cancel()
+
+	<-p.Context().Done()
+	_, err := p.Future().GetResult()
+	assert.Equal(ErrFutureCanceled, err)
+
+	p = NewPromise[int]()
+	ctx, cancel := context.WithCancel(context.Background())
+	next = Map(ctx, p.Future(), func(v int) int { return v })
+	cancel()
+
+	_, err = next.GetResult()
+	assert.Equal(context.Canceled, err)
+	<-p.Context().Done()
+	assert.False(p.Resolve(1))
+}
+
+func TestRecover(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	f := Recover(ctx, Rejected[int](errTest), func(ctx context.Context, err error) (int, error) {
+		return -1, nil
+	})
+	v, err := f.GetResult()
+	assert.Nil(err)
+	assert.Equal(-1, v)
+
+	f = Recover(ctx, Resolved(5), func(ctx context.Context, err error) (int, error) {
+		return -1, nil
+	})
+	v, err = f.GetResult()
+	assert.Nil(err)
+	assert.Equal(5, v)
+}
+
+func TestAll(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	p1, p2 := NewPromise[int](), NewPromise[int]()
+	all := All(ctx, p1.Future(), p2.Future(), Resolved(3))
+	p2.Resolve(2)
+	p1.Resolve(1)
+
+	values, err := all.GetResult()
+	assert.Nil(err)
+	assert.Equal([]int{1, 2, 3}, values)
+
+	values, err = All[int](ctx).GetResult()
+	assert.Nil(err)
+	assert.Len(values, 0)
+
+	p1 = NewPromise[int]()
+	all = All(ctx, p1.Future(), Rejected[int](errTest))
+	_, err = all.GetResult()
+	assert.Equal(errTest, err)
+
+	// the failure cancels the remaining inputs
+	<-p1.Context().Done()
+	_, err = p1.Future().GetResult()
+	assert.Equal(ErrFutureCanceled, err)
+}
+
+func TestAllSettled(t *testing.T) {
+	assert := assert.New(t)
+
+	results, err := AllSettled(context.Background(), Resolved(1), Rejected[int](errTest)).GetResult()
+	assert.Nil(err)
+	assert.Equal([]Settled[int]{{Value: 1}, {Err: errTest}}, results)
+
+	p := NewPromise[int]()
+	settled := AllSettled(context.Background(), p.Future())
+	settled.Cancel()
+	_, err = settled.GetResult()
+	assert.Equal(ErrFutureCanceled, err)
+	<-p.Context().Done()
+}
+
+func TestAny(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	p := NewPromise[int]()
+	v, err := Any(ctx, Rejected[int](errTest), p.Future(), Resolved(2)).GetResult()
+	assert.Nil(err)
+	assert.Equal(2, v)
+	<-p.Context().Done()
+
+	_, err = Any(ctx, Rejected[int](errTest), Rejected[int](errTest)).GetResult()
+	aerr, ok := err.(*AggregateError)
+	assert.True(ok)
+	assert.Equal([]error{errTest, errTest}, aerr.Errors)
+	assert.Contains(aerr.Error(), "test; test")
+
+	_, err = Any[int](ctx).GetResult()
+	assert.Equal(ErrNoFutures, err)
+}
+
+func TestRace(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+
+	p := NewPromise[int]()
+	_, err := Race(ctx, p.Future(), Rejected[int](errTest)).GetResult()
+	assert.Equal(errTest, err)
+	<-p.Context().Done()
+
+	p1, p2 := NewPromise[int](), NewPromise[int]()
+	race := Race(ctx, p1.Future(), p2.Future())
+	p2.Resolve(2)
+	v, err := race.GetResult()
+	assert.Nil(err)
+	assert.Equal(2, v)
+
+	_, err = Race[int](ctx).GetResult()
+	assert.Equal(ErrNoFutures, err)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = Race(timeout, NewPromise[int]().Future()).GetResult()
+	assert.Equal(context.DeadlineExceeded, err)
+}
+
+func TestGenericFutureSelect(t *testing.T) {
+	p1, p2 := NewPromise[int](), NewPromise[string]()
+	p2.Resolve("b")
+
+	select {
+	case <-p1.Future().WaitChan():
+		t.Fatal("p1 should not be fulfilled")
+	case <-p2.Future().WaitChan():
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CycleError is returned when an operation that requires a directed
+// acyclic graph is run on a graph containing a cycle.
+type CycleError struct {
+	// Cycle lists the vertices along one offending cycle.  The first
+	// vertex is repeated at the end to close the cycle.
+	Cycle []interface{}
+}
+
+func (e *CycleError) Error() string {
+	parts := make([]string, len(e.Cycle))
+	for i, v := range e.Cycle {
+		parts[i] = fmt.Sprint(v)
+	}
+	return "graph contains a cycle: " + strings.Join(parts, " -> ")
+}
+
+// VertexWeightFunc returns the weight of vertex v, such as the
+// duration of the job it represents.
+type VertexWeightFunc func(v interface{}) float64
+
+// TopologicalSort orders the vertices of the graph such that every
+// vertex comes before all of its successors, using Kahn's algorithm.
+// Ties are broken by insertion order.  If the graph is not acyclic a
+// *CycleError describing one of the cycles is returned.
+func (g *DirectedGraph) TopologicalSort() ([]interface{}, error) {
+	levels, err := g.TopologicalLevels()
+	if err != nil {
+		return nil, err
+	}
+
+	order := make([]interface{}, 0, g.V())
+	for _, level := range levels {
+		order = append(order, level...)
+	}
+	return order, nil
+}
+
+// TopologicalLevels splits a topological ordering of the graph into
+// levels.  Level 0 holds every vertex without predecessors and level i
+// holds the vertices whose predecessors all appear in earlier levels,
+// so the vertices within a single level are independent of each other
+// and may be processed in parallel.  If the graph is not acyclic a
+// *CycleError describing one of the cycles is returned.
+func (g *DirectedGraph) TopologicalLevels() ([][]interface{}, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	inDegree := make(map[interface{}]int, len(g.order))
+	current := make([]interface{}, 0)
+	for _, v := range g.order {
+		inDegree[v] = len(g.vertices[v].in)
+		if inDegree[v] == 0 {
+			current = append(current, v)
+		}
+	}
+
+	levels := make([][]interface{}, 0)
+	seen := 0
+	for len(current) > 0 {
+		levels = append(levels, current)
+		seen += len(current)
+
+		next := make([]interface{}, 0)
+		for _, v := range current {
+			for _, w := range g.vertices[v].out {
+				inDegree[w]--
+				if inDegree[w] == 0 {
+					next = append(next, w)
+				}
+			}
+		}
+		current = next
+	}
+
+	if seen < len(g.order) {
+		return nil, &CycleError{Cycle: g.findCycle(inDegree)}
+	}
+
+	return levels, nil
+}
+
+// findCycle returns a cycle among the vertices Kahn's algorithm was
+// unable to remove.  Every such vertex has a predecessor that was also
+// not removed, so walking predecessors must eventually repeat a vertex.
+func (g *DirectedGraph) findCycle(inDegree map[interface{}]int) []interface{} {
+	var v interface{}
+	for _, u := range g.order {
+		if inDegree[u] > 0 {
+			v = u
+			break
+		}
+	}
+
+	position := make(map[interface{}]int)
+	walk := make([]interface{}, 0)
+	for {
+		if i, ok := position[v]; ok {
+			walk = append(walk[i:], v)
+			break
+		}
+		position[v] = len(walk)
+		walk = append(walk, v)
+
+		for _, p := range g.vertices[v].in {
+			if inDegree[p] > 0 {
+				v = p
+				break
+			}
+		}
+	}
+
+	// the walk followed edges backwards
+	for i, j := 0, len(walk)-1; i < j; i, j = i+1, j-1 {
+		walk[i], walk[j] = walk[j], walk[i]
+	}
+	return walk
+}
+
+// CriticalPath returns the most expensive chain of dependent vertices
+// in the graph, which bounds how quickly the whole graph can be
+// processed regardless of parallelism.  The cost of the path is the sum
+// of the weights of its vertices.  If weight is nil every vertex is
+// given a weight of 1.  If the graph is not acyclic a *CycleError
+// describing one of the cycles is returned.
+func (g *DirectedGraph) CriticalPath(weight VertexWeightFunc) (*Path, error) {
+	order, err := g.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(order) == 0 {
+		return &Path{Vertices: []interface{}{}}, nil
+	}
+
+	if weight == nil {
+		weight = func(interface{}) float64 { return 1 }
+	}
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	finish := make(map[interface{}]float64, len(order))
+	prev := make(map[interface{}]interface{})
+	last := order[0]
+	for _, v := range order {
+		start := 0.0
+		for _, p := range g.vertices[v].in {
+			f, ok := finish[p]
+			if !ok {
+				continue
+			}
+			if _, set := prev[v]; !set || f > start {
+				start = f
+				prev[v] = p
+			}
+		}
+		finish[v] = start + weight(v)
+
+		if finish[v] > finish[last] {
+			last = v
+		}
+	}
+
+	vertices := []interface{}{last}
+	for p, ok := prev[last]; ok; p, ok = prev[p] {
+		vertices = append(vertices, p)
+	}
+	for i, j := 0, len(vertices)-1; i < j; i, j = i+1, j-1 {
+		vertices[i], vertices[j] = vertices[j], vertices[i]
+	}
+
+	return &Path{Vertices: vertices, Cost: finish[last]}, nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPipeline() *DirectedGraph {
+	dgraph := NewDirectedGraph()
+	dgraph.AddEdge("fetch", "compile")
+	dgraph.AddEdge("fetch", "lint")
+	dgraph.AddEdge("compile", "test")
+	dgraph.AddEdge("compile", "package")
+	dgraph.AddEdge("test", "deploy")
+	dgraph.AddEdge("package", "deploy")
+	dgraph.AddEdge("lint", "deploy")
+	dgraph.AddVertex("docs")
+	return dgraph
+}
+
+func TestTopologicalSort(t *testing.T) {
+	assert := assert.New(t)
+
+	order, err := NewDirectedGraph().TopologicalSort()
+	assert.Nil(err)
+	assert.Len(order, 0)
+
+	dgraph := buildPipeline()
+	order, err = dgraph.TopologicalSort()
+	assert.Nil(err)
+	assert.Equal([]interface{}{
+		"fetch", "docs", "compile", "lint", "test", "package", "deploy",
+	}, order)
+
+	position := make(map[interface{}]int)
+	for i, v := range order {
+		position[v] = i
+	}
+	for _, v := range dgraph.Vertices() {
+		successors, _ := dgraph.Successors(v)
+		for _, w := range successors {
+			assert.True(position[v] < position[w])
+		}
+	}
+}
+
+func TestTopologicalLevels(t *testing.T) {
+	assert := assert.New(t)
+
+	levels, err := buildPipeline().TopologicalLevels()
+	assert.Nil(err)
+	assert.Equal([][]interface{}{
+		{"fetch", "docs"},
+		{"compile", "lint"},
+		{"test", "package"},
+		{"deploy"},
+	}, levels)
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	assert := assert.New(t)
+	dgraph := buildPipeline()
+	dgraph.AddEdge("deploy", "other")
+	dgraph.AddEdge("other", "compile")
+
+	_, err := dgraph.TopologicalSort()
+	cerr, ok := err.(*CycleError)
+	assert.True(ok)
+	assert.Len(cerr.Cycle, 5)
+	assert.Equal(cerr.Cycle[0], cerr.Cycle[len(cerr.Cycle)-1])
+	for i := 0; i < len(cerr.Cycle)-1; i++ {
+		successors, _ := dgraph.Successors(cerr.Cycle[i])
+		assert.Contains(successors, cerr.Cycle[i+1])
+	}
+	assert.Contains(cerr.Cycle, "other")
+	assert.NotContains(cerr.Cycle, "lint")
+	assert.Contains(err.Error(), "->")
+
+	_, err = dgraph.TopologicalLevels()
+	assert.IsType(&CycleError{}, err)
+
+	_, err = dgraph.CriticalPath(nil)
+	assert.IsType(&CycleError{}, err)
+}
+
+func TestCriticalPath(t *testing.T) {
+	assert := assert.New(t)
+	dgraph := buildPipeline()
+
+	durations := map[interface{}]float64{
+		"fetch":   2,
+		"compile": 10,
+		"lint":    20,
+		"test":    4,
+		"package": 7,
+		"deploy":  1,
+		"docs":    5,
+	}
+
+	path, err := dgraph.CriticalPath(func(v interface{}) float64 {
+		return durations[v]
+	})
+	assert.Nil(err)
+	assert.Equal([]interface{}{"fetch", "lint", "deploy"}, path.Vertices)
+	assert.Equal(23.0, path.Cost)
+
+	durations["package"] = 12
+	path, err = dgraph.CriticalPath(func(v interface{}) float64 {
+		return durations[v]
+	})
+	assert.Nil(err)
+	assert.Equal([]interface{}{"fetch", "compile", "package", "deploy"}, path.Vertices)
+	assert.Equal(25.0, path.Cost)
+
+	path, err = dgraph.CriticalPath(nil)
+	assert.Nil(err)
+	assert.Len(path.Vertices, 4)
+	assert.Equal(4.0, path.Cost)
+
+	path, err = NewDirectedGraph().CriticalPath(nil)
+	assert.Nil(err)
+	assert.Len(path.Vertices, 0)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import "sync"
+
+type directedVertex struct {
+	out, in []interface{}
+	outSet  map[interface{}]struct{}
+}
+
+// DirectedGraph is a mutable, non-persistent directed graph.  Parallel
+// edges and self-loops are not permitted.  Vertices and edges are
+// remembered in insertion order so that algorithms run over the graph
+// produce deterministic results.
+type DirectedGraph struct {
+	mutex    sync.RWMutex
+	vertices map[interface{}]*directedVertex
+	order    []interface{}
+	e        int
+}
+
+// V returns the number of vertices in the DirectedGraph
+func (g *DirectedGraph) V() int {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return len(g.order)
+}
+
+// E returns the number of edges in the DirectedGraph
+func (g *DirectedGraph) E() int {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return g.e
+}
+
+// AddVertex adds v to the graph if it does not already exist.  This
+// is only required for vertices without any edges.
+func (g *DirectedGraph) AddVertex(v interface{}) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.addVertex(v)
+}
+
+// AddEdge will create an edge from vertex v to vertex w
+func (g *DirectedGraph) AddEdge(v, w interface{}) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if v == w {
+		return ErrSelfLoop
+	}
+
+	from := g.addVertex(v)
+	to := g.addVertex(w)
+
+	if _, ok := from.outSet[w]; ok {
+		return ErrParallelEdge
+	}
+
+	from.outSet[w] = struct{}{}
+	from.out = append(from.out, w)
+	to.in = append(to.in, v)
+	g.e++
+	return nil
+}
+
+// Vertices returns all of the vertices in the DirectedGraph in the
+// order they were added
+func (g *DirectedGraph) Vertices() []interface{} {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertices := make([]interface{}, len(g.order))
+	copy(vertices, g.order)
+	return vertices
+}
+
+// Successors returns the list of all vertices with an edge from v
+func (g *DirectedGraph) Successors(v interface{}) ([]interface{}, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return nil, ErrVertexNotFound
+	}
+
+	successors := make([]interface{}, len(vertex.out))
+	copy(successors, vertex.out)
+	return successors, nil
+}
+
+// Predecessors returns the list of all vertices with an edge to v
+func (g *DirectedGraph) Predecessors(v interface{}) ([]interface{}, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return nil, ErrVertexNotFound
+	}
+
+	predecessors := make([]interface{}, len(vertex.in))
+	copy(predecessors, vertex.in)
+	return predecessors, nil
+}
+
+// OutDegree returns the number of edges leaving v
+func (g *DirectedGraph) OutDegree(v interface{}) (int, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return 0, ErrVertexNotFound
+	}
+	return len(vertex.out), nil
+}
+
+// InDegree returns the number of edges entering v
+func (g *DirectedGraph) InDegree(v interface{}) (int, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return 0, ErrVertexNotFound
+	}
+	return len(vertex.in), nil
+}
+
+func (g *DirectedGraph) addVertex(v interface{}) *directedVertex {
+	vertex, ok := g.vertices[v]
+	if !ok {
+		vertex = &directedVertex{outSet: make(map[interface{}]struct{})}
+		g.vertices[v] = vertex
+		g.order = append(g.order, v)
+	}
+	return vertex
+}
+
+// NewDirectedGraph creates and returns a DirectedGraph
+func NewDirectedGraph() *DirectedGraph {
+	return &DirectedGraph{
+		vertices: make(map[interface{}]*directedVertex),
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectedAddEdge(t *testing.T) {
+	assert := assert.New(t)
+	dgraph := NewDirectedGraph()
+
+	assert.Nil(dgraph.AddEdge("A", "B"))
+	assert.Equal(ErrParallelEdge, dgraph.AddEdge("A", "B"))
+	assert.Equal(ErrSelfLoop, dgraph.AddEdge("A", "A"))
+
+	// the reverse edge is distinct in a directed graph
+	assert.Nil(dgraph.AddEdge("B", "A"))
+
+	assert.Equal(2, dgraph.V())
+	assert.Equal(2, dgraph.E())
+
+	dgraph.AddVertex("C")
+	dgraph.AddVertex("A")
+	assert.Equal(3, dgraph.V())
+	assert.Equal(2, dgraph.E())
+	assert.Equal([]interface{}{"A", "B", "C"}, dgraph.Vertices())
+}
+
+func TestDirectedNeighbors(t *testing.T) {
+	assert := assert.New(t)
+	dgraph := NewDirectedGraph()
+
+	_, err := dgraph.Successors("A")
+	assert.Equal(ErrVertexNotFound, err)
+	_, err = dgraph.Predecessors("A")
+	assert.Equal(ErrVertexNotFound, err)
+	_, err = dgraph.OutDegree("A")
+	assert.Equal(ErrVertexNotFound, err)
+	_, err = dgraph.InDegree("A")
+	assert.Equal(ErrVertexNotFound, err)
+
+	dgraph.AddEdge("A", "B")
+	dgraph.AddEdge("A", "C")
+	dgraph.AddEdge("C", "B")
+
+	successors, err := dgraph.Successors("A")
+	assert.Nil(err)
+	assert.Equal([]interface{}{"B", "C"}, successors)
+
+	predecessors, err := dgraph.Predecessors("B")
+	assert.Nil(err)
+	assert.Equal([]interface{}{"A", "C"}, predecessors)
+
+	d, err := dgraph.OutDegree("B")
+	assert.Nil(err)
+	assert.Zero(d)
+
+	d, err = dgraph.InDegree("B")
+	assert.Nil(err)
+	assert.Equal(2, d)
+}
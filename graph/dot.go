@@ -0,0 +1,484 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the graph to w in the Graphviz DOT language as an
+// undirected graph with one edge statement per edge.  Every vertex is
+// written as a quoted ID.  If marshal is nil vertices are formatted
+// with fmt.Sprint.
+func (g *SimpleGraph) WriteDOT(w io.Writer, marshal VertexMarshaler) error {
+	edges, err := g.marshaledEdges(marshal)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("graph {\n"); err != nil {
+		return err
+	}
+	for _, e := range edges {
+		v, err := quoteDOT(e.v)
+		if err != nil {
+			return err
+		}
+		u, err := quoteDOT(e.w)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(bw, "\t%s -- %s;\n", v, u); err != nil {
+			return err
+		}
+	}
+	if _, err := bw.WriteString("}\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// quoteDOT returns s as a quoted DOT ID.  DOT only allows quotes to be
+// escaped, so neither a trailing backslash nor a backslash followed by a
+// newline, which continues the line, can be represented.
+func quoteDOT(s string) (string, error) {
+	if strings.HasSuffix(s, `\`) || strings.Contains(s, "\\\n") {
+		return "", fmt.Errorf("graph: vertex %q cannot be written to DOT", s)
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`, nil
+}
+
+// ReadDOT builds a SimpleGraph from an undirected graph written in the
+// Graphviz DOT language.  Edge statements, including chains such as
+// a -- b -- c, are loaded.  Attributes, ports and attribute statements
+// are ignored, as are node statements since a SimpleGraph cannot hold
+// vertices without edges.  Directed graphs and subgraphs are not
+// supported.  Loading fails with an error wrapping ErrSelfLoop or
+// ErrParallelEdge if the input violates the invariants of a
+// SimpleGraph.  If unmarshal is nil vertices are loaded as strings.
+func ReadDOT(r io.Reader, unmarshal VertexUnmarshaler) (*SimpleGraph, error) {
+	if unmarshal == nil {
+		unmarshal = defaultUnmarshaler
+	}
+
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotParser{
+		lexer:     &dotLexer{input: string(input), line: 1},
+		g:         NewSimpleGraph(),
+		unmarshal: unmarshal,
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.g, nil
+}
+
+type dotTokenKind int
+
+const (
+	dotEOF dotTokenKind = iota
+	dotID
+	dotQuotedID
+	dotEdgeOp
+	dotArrow
+	dotPunct
+)
+
+type dotToken struct {
+	kind dotTokenKind
+	text string
+	line int
+}
+
+// is returns a bool indicating if the token is the provided keyword or
+// punctuation.  DOT keywords are case insensitive.
+func (t dotToken) is(text string) bool {
+	return (t.kind == dotID || t.kind == dotPunct) && strings.EqualFold(t.text, text)
+}
+
+var errUnterminated = errors.New("unterminated string or comment")
+
+// dotLexer splits DOT input into tokens, discarding whitespace and
+// comments.
+type dotLexer struct {
+	input string
+	pos   int
+	line  int
+}
+
+func (l *dotLexer) skip() error {
+	atLineStart := l.pos == 0
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+			atLineStart = true
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+			continue
+		case c == '#' && atLineStart, strings.HasPrefix(l.input[l.pos:], "//"):
+			for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+				l.pos++
+			}
+			continue
+		case strings.HasPrefix(l.input[l.pos:], "/*"):
+			end := strings.Index(l.input[l.pos+2:], "*/")
+			if end < 0 {
+				return errUnterminated
+			}
+			comment := l.input[l.pos : l.pos+end+4]
+			l.line += strings.Count(comment, "\n")
+			l.pos += len(comment)
+			continue
+		}
+		return nil
+	}
+	return nil
+}
+
+func (l *dotLexer) next() (dotToken, error) {
+	if err := l.skip(); err != nil {
+		return dotToken{}, err
+	}
+
+	tok := dotToken{line: l.line}
+	if l.pos >= len(l.input) {
+		tok.kind = dotEOF
+		return tok, nil
+	}
+
+	c := l.input[l.pos]
+	switch {
+	case c == '"':
+		return l.quoted(tok)
+	case strings.HasPrefix(l.input[l.pos:], "--"):
+		l.pos += 2
+		tok.kind, tok.text = dotEdgeOp, "--"
+		return tok, nil
+	case strings.HasPrefix(l.input[l.pos:], "->"):
+		l.pos += 2
+		tok.kind, tok.text = dotArrow, "->"
+		return tok, nil
+	case strings.IndexByte("{}[];,=:", c) >= 0:
+		l.pos++
+		tok.kind, tok.text = dotPunct, string(c)
+		return tok, nil
+	case isDOTIDStart(c):
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.input) && (isDOTIDStart(l.input[l.pos]) || isDigit(l.input[l.pos])) {
+			l.pos++
+		}
+		tok.kind, tok.text = dotID, l.input[start:l.pos]
+		return tok, nil
+	case c == '-' || c == '.' || isDigit(c):
+		return l.numeral(tok)
+	}
+
+	return tok, fmt.Errorf("unexpected character %q", c)
+}
+
+// quoted lexes a quoted string, in which the only escape is \" and a
+// backslash followed by a newline continues the string.
+func (l *dotLexer) quoted(tok dotToken) (dotToken, error) {
+	var sb strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			tok.kind, tok.text = dotQuotedID, sb.String()
+			return tok, nil
+		case c == '\\' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '"':
+			sb.WriteByte('"')
+			l.pos++
+		case c == '\\' && l.pos+1 < len(l.input) && l.input[l.pos+1] == '\n':
+			l.line++
+			l.pos++
+		default:
+			if c == '\n' {
+				l.line++
+			}
+			sb.WriteByte(c)
+		}
+	}
+	return tok, errUnterminated
+}
+
+// numeral lexes a DOT numeral: [-]?(.[0-9]+ | [0-9]+(.[0-9]*)?).
+func (l *dotLexer) numeral(tok dotToken) (dotToken, error) {
+	start := l.pos
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	digits, dot := 0, false
+	for ; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		if c == '.' && !dot {
+			dot = true
+			continue
+		}
+		if !isDigit(c) {
+			break
+		}
+		digits++
+	}
+	if digits == 0 {
+		return tok, fmt.Errorf("invalid numeral %q", l.input[start:l.pos])
+	}
+	tok.kind, tok.text = dotID, l.input[start:l.pos]
+	return tok, nil
+}
+
+func isDOTIDStart(c byte) bool {
+	return c == '_' || c >= 0x80 || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// dotParser loads the edges of a DOT graph into a SimpleGraph.
+type dotParser struct {
+	lexer     *dotLexer
+	peeked    *dotToken
+	g         *SimpleGraph
+	unmarshal VertexUnmarshaler
+}
+
+func (p *dotParser) peek() (dotToken, error) {
+	if p.peeked == nil {
+		tok, err := p.lexer.next()
+		if err != nil {
+			return tok, p.errorf(p.lexer.line, "%w", err)
+		}
+		p.peeked = &tok
+	}
+	return *p.peeked, nil
+}
+
+func (p *dotParser) next() (dotToken, error) {
+	tok, err := p.peek()
+	p.peeked = nil
+	return tok, err
+}
+
+func (p *dotParser) expect(text string) error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if !tok.is(text) {
+		return p.errorf(tok.line, "expected %q, found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *dotParser) errorf(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("graph: line %d: %w", line, fmt.Errorf(format, args...))
+}
+
+func (p *dotParser) parse() error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+	if tok.is("strict") {
+		if tok, err = p.next(); err != nil {
+			return err
+		}
+	}
+	if tok.is("digraph") {
+		return p.errorf(tok.line, "directed graphs are not supported")
+	}
+	if !tok.is("graph") {
+		return p.errorf(tok.line, "expected \"graph\", found %q", tok.text)
+	}
+
+	if tok, err = p.peek(); err != nil {
+		return err
+	}
+	if tok.kind == dotID || tok.kind == dotQuotedID {
+		p.next()
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case tok.is("}"):
+			p.next()
+			if tok, err = p.next(); err != nil {
+				return err
+			}
+			if tok.kind != dotEOF {
+				return p.errorf(tok.line, "unexpected %q after graph", tok.text)
+			}
+			return nil
+		case tok.is(";") || tok.is(","):
+			p.next()
+		default:
+			if err := p.statement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *dotParser) statement() error {
+	tok, err := p.next()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case tok.kind == dotEOF:
+		return p.errorf(tok.line, "unexpected end of input")
+	case tok.is("subgraph") || tok.is("{"):
+		return p.errorf(tok.line, "subgraphs are not supported")
+	case tok.is("graph") || tok.is("node") || tok.is("edge"):
+		return p.attributes()
+	case tok.kind != dotID && tok.kind != dotQuotedID:
+		return p.errorf(tok.line, "unexpected %q", tok.text)
+	}
+
+	next, err := p.peek()
+	if err != nil {
+		return err
+	}
+	if next.is("=") {
+		p.next()
+		value, err := p.next()
+		if err != nil {
+			return err
+		}
+		if value.kind != dotID && value.kind != dotQuotedID {
+			return p.errorf(value.line, "expected ID, found %q", value.text)
+		}
+		return nil
+	}
+
+	v, err := p.vertex(tok)
+	if err != nil {
+		return err
+	}
+
+	for {
+		op, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if op.kind == dotArrow {
+			return p.errorf(op.line, "directed edges are not supported")
+		}
+		if op.kind != dotEdgeOp {
+			break
+		}
+		p.next()
+
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+		if tok.is("subgraph") || tok.is("{") {
+			return p.errorf(tok.line, "subgraphs are not supported")
+		}
+		if tok.kind != dotID && tok.kind != dotQuotedID {
+			return p.errorf(tok.line, "expected ID, found %q", tok.text)
+		}
+
+		w, err := p.vertex(tok)
+		if err != nil {
+			return err
+		}
+		if err := p.g.AddEdge(v, w); err != nil {
+			return p.errorf(tok.line, "%w", err)
+		}
+		v = w
+	}
+
+	return p.attributes()
+}
+
+// vertex unmarshals the vertex named by tok and skips any port that
+// follows it.
+func (p *dotParser) vertex(tok dotToken) (interface{}, error) {
+	v, err := p.unmarshal(tok.text)
+	if err != nil {
+		return nil, p.errorf(tok.line, "%w", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		next, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !next.is(":") {
+			break
+		}
+		p.next()
+		if _, err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// attributes skips any attribute lists at the current position.
+func (p *dotParser) attributes() error {
+	for {
+		tok, err := p.peek()
+		if err != nil {
+			return err
+		}
+		if !tok.is("[") {
+			return nil
+		}
+		p.next()
+
+		for {
+			tok, err := p.next()
+			if err != nil {
+				return err
+			}
+			if tok.kind == dotEOF {
+				return p.errorf(tok.line, "unterminated attribute list")
+			}
+			if tok.is("]") {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteDOT(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := buildEncodingGraph()
+	sgraph.AddEdge(`say "hi"`, "A")
+
+	var buf bytes.Buffer
+	assert.Nil(sgraph.WriteDOT(&buf, nil))
+	assert.Equal(`graph {
+	"A" -- "B";
+	"A" -- "C";
+	"A" -- "say \"hi\"";
+	"B" -- "C";
+	"D" -- "E";
+}
+`, buf.String())
+
+	loaded, err := ReadDOT(&buf, nil)
+	assert.Nil(err)
+	assertSameGraph(assert, sgraph, loaded)
+
+	bad := NewSimpleGraph()
+	bad.AddEdge(`A\`, "B")
+	assert.Error(bad.WriteDOT(&buf, nil))
+
+	// a backslash before a newline would be read back as a continuation
+	bad = NewSimpleGraph()
+	bad.AddEdge("a\\\nb", "B")
+	assert.Error(bad.WriteDOT(&buf, nil))
+
+	// other backslashes and newlines round trip
+	odd := NewSimpleGraph()
+	odd.AddEdge("a\\b", "c\nd")
+	buf.Reset()
+	assert.Nil(odd.WriteDOT(&buf, nil))
+	loaded, err = ReadDOT(&buf, nil)
+	assert.Nil(err)
+	assertSameGraph(assert, odd, loaded)
+}
+
+func TestReadDOT(t *testing.T) {
+	assert := assert.New(t)
+
+	input := `
+# preprocessor style comment
+strict graph G {
+	// attribute statements are ignored
+	graph [rankdir=LR];
+	node [shape=box, color="red"]
+	edge [style=dashed]
+	label = "example";
+
+	/* chains add an edge per hop,
+	   and ports are ignored */
+	a -- b:n -- "c" [weight=2];
+	d; e
+	-1.5 -- d, d -- e
+}
+`
+	sgraph, err := ReadDOT(strings.NewReader(input), nil)
+	assert.Nil(err)
+	assert.Equal(6, sgraph.V())
+	assert.Equal(4, sgraph.E())
+
+	adj, err := sgraph.Adj("b")
+	assert.Nil(err)
+	assert.ElementsMatch([]interface{}{"a", "c"}, adj)
+
+	adj, err = sgraph.Adj("d")
+	assert.Nil(err)
+	assert.ElementsMatch([]interface{}{"-1.5", "e"}, adj)
+}
+
+func TestReadDOTIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	sgraph, err := ReadDOT(strings.NewReader("graph { a--b; b--2; -3--.5; x1--_y }"), nil)
+	assert.Nil(err)
+	assert.Equal(7, sgraph.V())
+	assert.Equal(4, sgraph.E())
+
+	adj, err := sgraph.Adj("b")
+	assert.Nil(err)
+	assert.ElementsMatch([]interface{}{"a", "2"}, adj)
+
+	adj, err = sgraph.Adj("-3")
+	assert.Nil(err)
+	assert.ElementsMatch([]interface{}{".5"}, adj)
+
+	for _, input := range []string{
+		"graph { a -- - }",
+		"graph { a -- -b }",
+		"graph { a -- . }",
+	} {
+		_, err := ReadDOT(strings.NewReader(input), nil)
+		assert.Error(err, input)
+	}
+}
+
+func TestReadDOTErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := ReadDOT(strings.NewReader("graph { a -- b; b -- a }"), nil)
+	assert.True(errors.Is(err, ErrParallelEdge))
+
+	_, err = ReadDOT(strings.NewReader("graph {\n\ta -- b\n\ta -- a\n}"), nil)
+	assert.True(errors.Is(err, ErrSelfLoop))
+	assert.Contains(err.Error(), "line 3")
+
+	for _, input := range []string{
+		"digraph { a -> b }",
+		"graph { a -> b }",
+		"graph { subgraph { a -- b } }",
+		"graph { a -- b",
+		`graph { "a -- b }`,
+		"graph { /* a -- b }",
+		"graph { a -- b [color=red }",
+		"graph { a -- }",
+		"graph { a -- b } c",
+		"tree { a -- b }",
+		"graph { a -- b ! }",
+	} {
+		_, err := ReadDOT(strings.NewReader(input), nil)
+		assert.Error(err, input)
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// VertexMarshaler converts a vertex to the string used to identify it
+// when a graph is encoded.  Distinct vertices must produce distinct
+// strings.
+type VertexMarshaler func(v interface{}) (string, error)
+
+// VertexUnmarshaler converts a string produced by a VertexMarshaler
+// back into a vertex.
+type VertexUnmarshaler func(s string) (interface{}, error)
+
+// defaultMarshaler formats vertices with fmt.Sprint.
+func defaultMarshaler(v interface{}) (string, error) {
+	return fmt.Sprint(v), nil
+}
+
+// defaultUnmarshaler leaves vertices as strings.
+func defaultUnmarshaler(s string) (interface{}, error) {
+	return s, nil
+}
+
+// marshaledEdge is an edge whose endpoints have been marshaled.
+type marshaledEdge struct {
+	v, w string
+}
+
+// marshaledEdges returns every edge in the graph once, with its
+// endpoints marshaled and the edges sorted so output is stable.
+func (g *SimpleGraph) marshaledEdges(marshal VertexMarshaler) ([]marshaledEdge, error) {
+	if marshal == nil {
+		marshal = defaultMarshaler
+	}
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	names := make(map[interface{}]string, len(g.adjacencyList))
+	taken := make(map[string]struct{}, len(g.adjacencyList))
+	for v := range g.adjacencyList {
+		s, err := marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := taken[s]; ok {
+			return nil, fmt.Errorf("graph: multiple vertices marshal to %q", s)
+		}
+		taken[s] = struct{}{}
+		names[v] = s
+	}
+
+	edges := make([]marshaledEdge, 0, g.e)
+	for v, adj := range g.adjacencyList {
+		for w := range adj {
+			if names[v] < names[w] {
+				edges = append(edges, marshaledEdge{names[v], names[w]})
+			}
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].v != edges[j].v {
+			return edges[i].v < edges[j].v
+		}
+		return edges[i].w < edges[j].w
+	})
+	return edges, nil
+}
+
+// WriteEdgeList writes the graph to w as plain text with one edge per
+// line, its two vertices separated by a single space.  Marshaled
+// vertices must be non-empty, contain no whitespace and not begin with
+// '#'.  If marshal is nil vertices are formatted with fmt.Sprint.
+func (g *SimpleGraph) WriteEdgeList(w io.Writer, marshal VertexMarshaler) error {
+	edges, err := g.marshaledEdges(marshal)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, e := range edges {
+		for _, s := range []string{e.v, e.w} {
+			if s == "" || strings.HasPrefix(s, "#") || strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+				return fmt.Errorf("graph: vertex %q cannot be written to an edge list", s)
+			}
+		}
+		if _, err := fmt.Fprintf(bw, "%s %s\n", e.v, e.w); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ReadEdgeList builds a SimpleGraph from the text format written by
+// WriteEdgeList.  Blank lines and lines beginning with '#' are ignored.
+// Loading fails with an error wrapping ErrSelfLoop or ErrParallelEdge
+// if the input violates the invariants of a SimpleGraph.  If unmarshal
+// is nil vertices are loaded as strings.
+func ReadEdgeList(r io.Reader, unmarshal VertexUnmarshaler) (*SimpleGraph, error) {
+	if unmarshal == nil {
+		unmarshal = defaultUnmarshaler
+	}
+
+	g := NewSimpleGraph()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("graph: line %d: expected 2 vertices, found %d", line, len(fields))
+		}
+
+		if err := addMarshaledEdge(g, unmarshal, fields[0], fields[1]); err != nil {
+			return nil, fmt.Errorf("graph: line %d: %w", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// WriteJSON writes the graph to w as a JSON object mapping each vertex
+// to the list of its adjacent vertices.  Every edge therefore appears
+// in the lists of both of its vertices.  If marshal is nil vertices are
+// formatted with fmt.Sprint.
+func (g *SimpleGraph) WriteJSON(w io.Writer, marshal VertexMarshaler) error {
+	edges, err := g.marshaledEdges(marshal)
+	if err != nil {
+		return err
+	}
+
+	adjacency := make(map[string][]string)
+	for _, e := range edges {
+		adjacency[e.v] = append(adjacency[e.v], e.w)
+		adjacency[e.w] = append(adjacency[e.w], e.v)
+	}
+	for _, adj := range adjacency {
+		sort.Strings(adj)
+	}
+
+	return json.NewEncoder(w).Encode(adjacency)
+}
+
+// ReadJSON builds a SimpleGraph from the JSON format written by
+// WriteJSON.  An edge may be listed under either or both of its
+// vertices, but listing it twice under the same vertex fails with an
+// error wrapping ErrParallelEdge and listing a vertex as adjacent to
+// itself fails with an error wrapping ErrSelfLoop.  A SimpleGraph
+// cannot hold vertices without edges, so vertices with empty lists are
+// not loaded.  If unmarshal is nil vertices are loaded as strings.
+func ReadJSON(r io.Reader, unmarshal VertexUnmarshaler) (*SimpleGraph, error) {
+	if unmarshal == nil {
+		unmarshal = defaultUnmarshaler
+	}
+
+	adjacency := make(map[string][]string)
+	if err := json.NewDecoder(r).Decode(&adjacency); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(adjacency))
+	for key := range adjacency {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	g := NewSimpleGraph()
+	listed := make(map[[2]interface{}]struct{})
+	for _, key := range keys {
+		v, err := unmarshal(key)
+		if err != nil {
+			return nil, fmt.Errorf("graph: vertex %q: %w", key, err)
+		}
+
+		for _, s := range adjacency[key] {
+			w, err := unmarshal(s)
+			if err != nil {
+				return nil, fmt.Errorf("graph: vertex %q: %w", s, err)
+			}
+
+			if _, ok := listed[[2]interface{}{v, w}]; ok {
+				return nil, fmt.Errorf("graph: vertex %q: %w", key, ErrParallelEdge)
+			}
+
+			// the same edge listed from its other end is expected
+			_, reverse := listed[[2]interface{}{w, v}]
+			listed[[2]interface{}{v, w}] = struct{}{}
+			if reverse {
+				continue
+			}
+
+			if err := g.AddEdge(v, w); err != nil {
+				return nil, fmt.Errorf("graph: vertex %q: %w", key, err)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+func addMarshaledEdge(g *SimpleGraph, unmarshal VertexUnmarshaler, vs, ws string) error {
+	v, err := unmarshal(vs)
+	if err != nil {
+		return err
+	}
+	w, err := unmarshal(ws)
+	if err != nil {
+		return err
+	}
+	return g.AddEdge(v, w)
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildEncodingGraph() *SimpleGraph {
+	sgraph := NewSimpleGraph()
+	sgraph.AddEdge("A", "B")
+	sgraph.AddEdge("B", "C")
+	sgraph.AddEdge("C", "A")
+	sgraph.AddEdge("D", "E")
+	return sgraph
+}
+
+func assertSameGraph(assert *assert.Assertions, expected, actual *SimpleGraph) {
+	assert.Equal(expected.V(), actual.V())
+	assert.Equal(expected.E(), actual.E())
+	for _, v := range expected.Vertices() {
+		eadj, _ := expected.Adj(v)
+		aadj, err := actual.Adj(v)
+		assert.Nil(err)
+		assert.ElementsMatch(eadj, aadj)
+	}
+}
+
+func TestEdgeList(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := buildEncodingGraph()
+
+	var buf bytes.Buffer
+	assert.Nil(sgraph.WriteEdgeList(&buf, nil))
+	assert.Equal("A B\nA C\nB C\nD E\n", buf.String())
+
+	loaded, err := ReadEdgeList(&buf, nil)
+	assert.Nil(err)
+	assertSameGraph(assert, sgraph, loaded)
+
+	loaded, err = ReadEdgeList(strings.NewReader("# comment\n\n  A B \nB C\n"), nil)
+	assert.Nil(err)
+	assert.Equal(2, loaded.E())
+
+	_, err = ReadEdgeList(strings.NewReader("A B\nB A\n"), nil)
+	assert.True(errors.Is(err, ErrParallelEdge))
+	assert.Contains(err.Error(), "line 2")
+
+	_, err = ReadEdgeList(strings.NewReader("A A\n"), nil)
+	assert.True(errors.Is(err, ErrSelfLoop))
+
+	_, err = ReadEdgeList(strings.NewReader("A B C\n"), nil)
+	assert.Error(err)
+
+	bad := NewSimpleGraph()
+	bad.AddEdge("A B", "C")
+	assert.Error(bad.WriteEdgeList(&buf, nil))
+}
+
+func TestEdgeListCustomVertices(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+	sgraph.AddEdge(1, 2)
+	sgraph.AddEdge(2, 10)
+
+	var buf bytes.Buffer
+	assert.Nil(sgraph.WriteEdgeList(&buf, nil))
+
+	loaded, err := ReadEdgeList(&buf, func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+	assert.Nil(err)
+	assertSameGraph(assert, sgraph, loaded)
+
+	_, err = ReadEdgeList(strings.NewReader("1 x\n"), func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+	assert.Error(err)
+
+	err = sgraph.WriteEdgeList(&buf, func(v interface{}) (string, error) {
+		return "same", nil
+	})
+	assert.Error(err)
+}
+
+func TestJSON(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := buildEncodingGraph()
+
+	var buf bytes.Buffer
+	assert.Nil(sgraph.WriteJSON(&buf, nil))
+	assert.JSONEq(`{
+		"A": ["B", "C"],
+		"B": ["A", "C"],
+		"C": ["A", "B"],
+		"D": ["E"],
+		"E": ["D"]
+	}`, buf.String())
+
+	loaded, err := ReadJSON(&buf, nil)
+	assert.Nil(err)
+	assertSameGraph(assert, sgraph, loaded)
+
+	// edges only need to be listed from one end
+	loaded, err = ReadJSON(strings.NewReader(`{"A": ["B", "C"], "D": [], "E": ["D"]}`), nil)
+	assert.Nil(err)
+	assert.Equal(3, loaded.E())
+	assert.Equal(5, loaded.V())
+
+	_, err = ReadJSON(strings.NewReader(`{"A": ["B", "B"]}`), nil)
+	assert.True(errors.Is(err, ErrParallelEdge))
+
+	_, err = ReadJSON(strings.NewReader(`{"A": ["A"]}`), nil)
+	assert.True(errors.Is(err, ErrSelfLoop))
+
+	_, err = ReadJSON(strings.NewReader(`{"A": "B"}`), nil)
+	assert.Error(err)
+}
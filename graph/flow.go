@@ -0,0 +1,165 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import "errors"
+
+// ErrSourceIsSink is returned when a flow is requested from a vertex
+// to itself.
+var ErrSourceIsSink = errors.New("source and sink must be distinct vertices")
+
+// flowEpsilon is the residual capacity below which an edge is treated
+// as saturated, which guards against rounding error in float capacities.
+const flowEpsilon = 1e-9
+
+// Flow is the result of a maximum flow computation.
+type Flow struct {
+	// Value is the total flow from source to sink, which is also the
+	// capacity of the minimum cut.
+	Value float64
+	// Edges holds every edge carrying flow, oriented in the direction
+	// of the flow, with Weight set to the amount of flow.
+	Edges []Edge
+	// Cut holds the edges of a minimum cut separating source from sink,
+	// with Weight set to the capacity of the edge.
+	Cut []Edge
+	// SourceSide holds the vertices on the source side of Cut.
+	SourceSide []interface{}
+}
+
+// MaxFlow computes a maximum flow from source to sink using the
+// Edmonds-Karp algorithm, treating edge weights as capacities.  In an
+// undirected graph each edge may carry flow in either direction.  Runs
+// in O(V E^2).
+func (g *WeightedGraph) MaxFlow(source, sink interface{}) (*Flow, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if _, ok := g.vertices[source]; !ok {
+		return nil, ErrVertexNotFound
+	}
+	if _, ok := g.vertices[sink]; !ok {
+		return nil, ErrVertexNotFound
+	}
+	if source == sink {
+		return nil, ErrSourceIsSink
+	}
+
+	// residual[v][w] is the remaining capacity from v to w, and every
+	// edge is given a reverse entry so flow can be pushed back.
+	residual := make(map[interface{}]map[interface{}]float64, len(g.order))
+	neighbors := make(map[interface{}][]interface{}, len(g.order))
+	for _, v := range g.order {
+		residual[v] = make(map[interface{}]float64)
+	}
+	link := func(v, w interface{}, capacity float64) {
+		if _, ok := residual[v][w]; !ok {
+			neighbors[v] = append(neighbors[v], w)
+		}
+		residual[v][w] += capacity
+	}
+	for _, e := range g.edges {
+		if e.Weight < 0 {
+			return nil, ErrNegativeWeight
+		}
+		reverse := 0.0
+		if !g.directed {
+			reverse = e.Weight
+		}
+		link(e.From, e.To, e.Weight)
+		link(e.To, e.From, reverse)
+	}
+
+	flow := &Flow{}
+	for {
+		prev := map[interface{}]interface{}{source: nil}
+		queue := []interface{}{source}
+		for len(queue) > 0 && !containsKey(prev, sink) {
+			v := queue[0]
+			queue = queue[1:]
+			for _, w := range neighbors[v] {
+				if _, ok := prev[w]; ok || residual[v][w] <= flowEpsilon {
+					continue
+				}
+				prev[w] = v
+				queue = append(queue, w)
+			}
+		}
+
+		if !containsKey(prev, sink) {
+			flow.SourceSide = make([]interface{}, 0, len(prev))
+			for _, v := range g.order {
+				if _, ok := prev[v]; ok {
+					flow.SourceSide = append(flow.SourceSide, v)
+				}
+			}
+			g.collectFlow(flow, residual, prev)
+			return flow, nil
+		}
+
+		bottleneck := residual[prev[sink]][sink]
+		for w := sink; w != source; w = prev[w] {
+			if r := residual[prev[w]][w]; r < bottleneck {
+				bottleneck = r
+			}
+		}
+		for w := sink; w != source; w = prev[w] {
+			residual[prev[w]][w] -= bottleneck
+			residual[w][prev[w]] += bottleneck
+		}
+		flow.Value += bottleneck
+	}
+}
+
+// collectFlow derives the flow carried by each edge and the edges of
+// the minimum cut from the final residual graph.  sourceSide holds the
+// vertices still reachable from the source.
+func (g *WeightedGraph) collectFlow(flow *Flow, residual map[interface{}]map[interface{}]float64,
+	sourceSide map[interface{}]interface{}) {
+
+	flow.Edges = make([]Edge, 0)
+	flow.Cut = make([]Edge, 0)
+
+	for _, e := range g.edges {
+		// the net flow along the edge is whatever capacity it has lost.
+		// A directed edge can only appear to carry negative flow if it
+		// shares its residual capacity with an antiparallel edge, in
+		// which case that flow is attributed to the other edge.
+		net := e.Weight - residual[e.From][e.To]
+		if g.directed && net < 0 {
+			net = 0
+		}
+
+		switch {
+		case net > flowEpsilon:
+			flow.Edges = append(flow.Edges, Edge{From: e.From, To: e.To, Weight: net})
+		case net < -flowEpsilon:
+			flow.Edges = append(flow.Edges, Edge{From: e.To, To: e.From, Weight: -net})
+		}
+
+		_, fromIn := sourceSide[e.From]
+		_, toIn := sourceSide[e.To]
+		if fromIn && !toIn || !g.directed && toIn && !fromIn {
+			flow.Cut = append(flow.Cut, e)
+		}
+	}
+}
+
+func containsKey(m map[interface{}]interface{}, key interface{}) bool {
+	_, ok := m[key]
+	return ok
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxFlow(t *testing.T) {
+	assert := assert.New(t)
+	wgraph := NewWeightedDigraph()
+	wgraph.AddEdge("s", "a", 10)
+	wgraph.AddEdge("s", "c", 10)
+	wgraph.AddEdge("a", "b", 4)
+	wgraph.AddEdge("a", "c", 2)
+	wgraph.AddEdge("a", "d", 8)
+	wgraph.AddEdge("c", "d", 9)
+	wgraph.AddEdge("d", "b", 6)
+	wgraph.AddEdge("b", "t", 10)
+	wgraph.AddEdge("d", "t", 10)
+
+	flow, err := wgraph.MaxFlow("s", "t")
+	assert.Nil(err)
+	assert.Equal(19.0, flow.Value)
+
+	// flow is conserved at every intermediate vertex and respects
+	// every capacity
+	balance := map[interface{}]float64{}
+	for _, e := range flow.Edges {
+		capacity, ok := wgraph.Weight(e.From, e.To)
+		assert.True(ok)
+		assert.True(e.Weight <= capacity)
+		balance[e.From] -= e.Weight
+		balance[e.To] += e.Weight
+	}
+	assert.Equal(-19.0, balance["s"])
+	assert.Equal(19.0, balance["t"])
+	for _, v := range []string{"a", "b", "c", "d"} {
+		assert.Zero(balance[v])
+	}
+
+	cut := 0.0
+	for _, e := range flow.Cut {
+		cut += e.Weight
+	}
+	assert.Equal(flow.Value, cut)
+	assert.Contains(flow.SourceSide, "s")
+	assert.NotContains(flow.SourceSide, "t")
+}
+
+func TestMaxFlowAntiparallel(t *testing.T) {
+	assert := assert.New(t)
+	wgraph := NewWeightedDigraph()
+	wgraph.AddEdge("s", "a", 5)
+	wgraph.AddEdge("s", "b", 5)
+	wgraph.AddEdge("a", "b", 3)
+	wgraph.AddEdge("b", "a", 3)
+	wgraph.AddEdge("a", "t", 7)
+	wgraph.AddEdge("b", "t", 2)
+
+	flow, err := wgraph.MaxFlow("s", "t")
+	assert.Nil(err)
+	assert.Equal(9.0, flow.Value)
+	// a must receive flow from b to saturate a -> t, so no flow can be
+	// reported along a -> b
+	for _, e := range flow.Edges {
+		assert.False(e.From == "a" && e.To == "b")
+	}
+}
+
+func TestMaxFlowUndirected(t *testing.T) {
+	assert := assert.New(t)
+	wgraph := NewWeightedGraph()
+	wgraph.AddEdge("s", "a", 3)
+	wgraph.AddEdge("b", "a", 2)
+	wgraph.AddEdge("b", "s", 1)
+	wgraph.AddEdge("b", "t", 3)
+	wgraph.AddEdge("a", "t", 1)
+	wgraph.AddVertex("x")
+
+	flow, err := wgraph.MaxFlow("s", "t")
+	assert.Nil(err)
+	assert.Equal(4.0, flow.Value)
+	assert.Contains(flow.Edges, Edge{"a", "b", 2})
+	assert.NotContains(flow.SourceSide, "x")
+
+	_, err = wgraph.MaxFlow("s", "s")
+	assert.Equal(ErrSourceIsSink, err)
+
+	_, err = wgraph.MaxFlow("s", "z")
+	assert.Equal(ErrVertexNotFound, err)
+
+	flow, err = wgraph.MaxFlow("s", "x")
+	assert.Nil(err)
+	assert.Zero(flow.Value)
+	assert.Len(flow.Cut, 0)
+
+	wgraph.AddEdge("x", "t", -1)
+	_, err = wgraph.MaxFlow("s", "t")
+	assert.Equal(ErrNegativeWeight, err)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Workiva/go-datastructures/fibheap"
+)
+
+// ErrDirectedGraph is returned when an operation that is only defined
+// for undirected graphs is requested on a directed graph.
+var ErrDirectedGraph = errors.New("operation requires an undirected graph")
+
+// SpanningForest is the result of a minimum spanning tree computation.
+// If the graph is not connected it spans each connected component.
+type SpanningForest struct {
+	// Edges holds the edges of the forest.
+	Edges []Edge
+	// Weight is the sum of the weights of Edges.
+	Weight float64
+}
+
+// Kruskal computes a minimum spanning forest of the graph by adding
+// edges in order of increasing weight, skipping any that would create a
+// cycle.  Runs in O(E log E).  Edges of equal weight are considered in
+// insertion order.
+func (g *WeightedGraph) Kruskal() (*SpanningForest, error) {
+	if g.directed {
+		return nil, ErrDirectedGraph
+	}
+
+	edges := g.Edges()
+	sort.SliceStable(edges, func(i, j int) bool {
+		return edges[i].Weight < edges[j].Weight
+	})
+
+	uf := NewUnionFind()
+	forest := &SpanningForest{Edges: make([]Edge, 0)}
+	for _, e := range edges {
+		if uf.Union(e.From, e.To) {
+			forest.Edges = append(forest.Edges, e)
+			forest.Weight += e.Weight
+		}
+	}
+
+	return forest, nil
+}
+
+// Prim computes a minimum spanning forest of the graph by repeatedly
+// adding the cheapest edge leaving the tree grown so far.  The frontier
+// is kept in a Fibonacci heap, so this runs in O(E + V log V).
+func (g *WeightedGraph) Prim() (*SpanningForest, error) {
+	if g.directed {
+		return nil, ErrDirectedGraph
+	}
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	forest := &SpanningForest{Edges: make([]Edge, 0)}
+	inTree := make(map[interface{}]struct{}, len(g.order))
+
+	for _, root := range g.order {
+		if _, ok := inTree[root]; ok {
+			continue
+		}
+
+		heap := fibheap.NewFloatFibHeap()
+		entries := map[interface{}]*fibheap.Entry{}
+		vertices := map[*fibheap.Entry]interface{}{}
+		via := map[interface{}]interface{}{}
+
+		entry := heap.Enqueue(0)
+		entries[root] = entry
+		vertices[entry] = root
+
+		for !heap.IsEmpty() {
+			min, _ := heap.DequeueMin()
+			v := vertices[min]
+			delete(vertices, min)
+			delete(entries, v)
+			inTree[v] = struct{}{}
+
+			if from, ok := via[v]; ok {
+				forest.Edges = append(forest.Edges, Edge{From: from, To: v, Weight: min.Priority})
+				forest.Weight += min.Priority
+			}
+
+			vertex := g.vertices[v]
+			for _, w := range vertex.adj {
+				if _, ok := inTree[w]; ok {
+					continue
+				}
+
+				weight := vertex.weights[w]
+				if entry, ok := entries[w]; ok {
+					if weight < entry.Priority {
+						heap.DecreaseKey(entry, weight)
+						via[w] = v
+					}
+					continue
+				}
+
+				entry := heap.Enqueue(weight)
+				entries[w] = entry
+				vertices[entry] = w
+				via[w] = v
+			}
+		}
+	}
+
+	return forest, nil
+}
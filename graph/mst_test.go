@@ -0,0 +1,85 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildMSTGraph() *WeightedGraph {
+	wgraph := NewWeightedGraph()
+	wgraph.AddEdge("A", "B", 7)
+	wgraph.AddEdge("A", "D", 5)
+	wgraph.AddEdge("B", "C", 8)
+	wgraph.AddEdge("B", "D", 9)
+	wgraph.AddEdge("B", "E", 7)
+	wgraph.AddEdge("C", "E", 5)
+	wgraph.AddEdge("D", "E", 15)
+	wgraph.AddEdge("D", "F", 6)
+	wgraph.AddEdge("E", "F", 8)
+	wgraph.AddEdge("E", "G", 9)
+	wgraph.AddEdge("F", "G", 11)
+	wgraph.AddEdge("X", "Y", 2)
+	wgraph.AddVertex("Z")
+	return wgraph
+}
+
+func assertSpansMST(assert *assert.Assertions, forest *SpanningForest) {
+	assert.Equal(41.0, forest.Weight)
+	assert.Len(forest.Edges, 7)
+
+	uf := NewUnionFind()
+	total := 0.0
+	for _, e := range forest.Edges {
+		assert.True(uf.Union(e.From, e.To))
+		total += e.Weight
+	}
+	assert.Equal(forest.Weight, total)
+	assert.True(uf.Connected("A", "G"))
+	assert.True(uf.Connected("X", "Y"))
+	assert.False(uf.Connected("A", "X"))
+}
+
+func TestKruskal(t *testing.T) {
+	assert := assert.New(t)
+
+	forest, err := buildMSTGraph().Kruskal()
+	assert.Nil(err)
+	assertSpansMST(assert, forest)
+	assert.Equal(Edge{"X", "Y", 2}, forest.Edges[0])
+
+	_, err = NewWeightedDigraph().Kruskal()
+	assert.Equal(ErrDirectedGraph, err)
+}
+
+func TestPrim(t *testing.T) {
+	assert := assert.New(t)
+
+	forest, err := buildMSTGraph().Prim()
+	assert.Nil(err)
+	assertSpansMST(assert, forest)
+	assert.Equal(Edge{"A", "D", 5}, forest.Edges[0])
+
+	forest, err = NewWeightedGraph().Prim()
+	assert.Nil(err)
+	assert.Len(forest.Edges, 0)
+
+	_, err = NewWeightedDigraph().Prim()
+	assert.Equal(ErrDirectedGraph, err)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"errors"
+
+	"github.com/Workiva/go-datastructures/fibheap"
+)
+
+var (
+	// ErrNoPath is returned when a path is requested between two
+	// vertices that are not connected.
+	ErrNoPath = errors.New("no path between vertices")
+
+	// ErrNegativeWeight is returned when a shortest path search
+	// encounters an edge with a negative weight.
+	ErrNegativeWeight = errors.New("negative edge weights are not permitted")
+)
+
+// WeightFunc returns the weight of the edge between vertices v and w.
+// Shortest path searches require weights to be non-negative.
+type WeightFunc func(v, w interface{}) float64
+
+// Heuristic returns an estimate of the cost of the cheapest path from
+// v to the target of an A* search.  For the search to return an optimal
+// path the estimate must never exceed the true cost, and for every edge
+// (v, w) it must hold that h(v) <= weight(v, w) + h(w).
+type Heuristic func(v interface{}) float64
+
+// Path is the result of a shortest path search.
+type Path struct {
+	// Vertices is the sequence of vertices from source to target,
+	// inclusive of both.
+	Vertices []interface{}
+	// Cost is the sum of the weights of the edges along the path.
+	Cost float64
+}
+
+// ShortestPath uses Dijkstra's algorithm to find the cheapest path from
+// source to target.  If weight is nil every edge is given a weight of 1.
+// The search runs in O(E + V log V) as it is backed by a Fibonacci heap.
+func (g *SimpleGraph) ShortestPath(source, target interface{}, weight WeightFunc) (*Path, error) {
+	return g.AStar(source, target, weight, nil)
+}
+
+// AStar finds the cheapest path from source to target, using h to guide
+// the search towards target.  If weight is nil every edge is given a
+// weight of 1 and if h is nil the search degrades to Dijkstra's algorithm.
+func (g *SimpleGraph) AStar(source, target interface{}, weight WeightFunc, h Heuristic) (*Path, error) {
+	if _, err := g.Degree(source); err != nil {
+		return nil, err
+	}
+	if _, err := g.Degree(target); err != nil {
+		return nil, err
+	}
+
+	if weight == nil {
+		weight = unitWeight
+	}
+	if h == nil {
+		h = zeroHeuristic
+	}
+
+	heap := fibheap.NewFloatFibHeap()
+	entries := map[interface{}]*fibheap.Entry{}
+	vertices := map[*fibheap.Entry]interface{}{}
+	costs := map[interface{}]float64{source: 0}
+	prev := map[interface{}]interface{}{}
+	closed := map[interface{}]struct{}{}
+
+	entry := heap.Enqueue(h(source))
+	entries[source] = entry
+	vertices[entry] = source
+
+	for !heap.IsEmpty() {
+		min, _ := heap.DequeueMin()
+		v := vertices[min]
+		delete(vertices, min)
+		delete(entries, v)
+		closed[v] = struct{}{}
+
+		if v == target {
+			return buildPath(prev, source, target, costs[target]), nil
+		}
+
+		adj, err := g.Adj(v)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, w := range adj {
+			if _, ok := closed[w]; ok {
+				continue
+			}
+
+			edge := weight(v, w)
+			if edge < 0 {
+				return nil, ErrNegativeWeight
+			}
+
+			cost := costs[v] + edge
+			if known, ok := costs[w]; ok && cost >= known {
+				continue
+			}
+			costs[w] = cost
+			prev[w] = v
+
+			if entry, ok := entries[w]; ok {
+				heap.DecreaseKey(entry, cost+h(w))
+				continue
+			}
+
+			entry := heap.Enqueue(cost + h(w))
+			entries[w] = entry
+			vertices[entry] = w
+		}
+	}
+
+	return nil, ErrNoPath
+}
+
+func buildPath(prev map[interface{}]interface{}, source, target interface{}, cost float64) *Path {
+	vertices := []interface{}{target}
+	for v := target; v != source; {
+		v = prev[v]
+		vertices = append(vertices, v)
+	}
+
+	for i, j := 0, len(vertices)-1; i < j; i, j = i+1, j-1 {
+		vertices[i], vertices[j] = vertices[j], vertices[i]
+	}
+
+	return &Path{Vertices: vertices, Cost: cost}
+}
+
+func unitWeight(v, w interface{}) float64 {
+	return 1
+}
+
+func zeroHeuristic(v interface{}) float64 {
+	return 0
+}
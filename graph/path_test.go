@@ -0,0 +1,120 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	x, y int
+}
+
+func euclidean(v, w interface{}) float64 {
+	a, b := v.(point), w.(point)
+	return math.Hypot(float64(a.x-b.x), float64(a.y-b.y))
+}
+
+func TestShortestPath(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	weights := map[[2]string]float64{
+		{"A", "B"}: 7,
+		{"A", "C"}: 9,
+		{"A", "F"}: 14,
+		{"B", "C"}: 10,
+		{"B", "D"}: 15,
+		{"C", "D"}: 11,
+		{"C", "F"}: 2,
+		{"D", "E"}: 6,
+		{"E", "F"}: 9,
+	}
+	for edge := range weights {
+		sgraph.AddEdge(edge[0], edge[1])
+	}
+	sgraph.AddEdge("X", "Y")
+	weight := func(v, w interface{}) float64 {
+		if cost, ok := weights[[2]string{v.(string), w.(string)}]; ok {
+			return cost
+		}
+		return weights[[2]string{w.(string), v.(string)}]
+	}
+
+	path, err := sgraph.ShortestPath("A", "E", weight)
+	assert.Nil(err)
+	assert.Equal([]interface{}{"A", "C", "F", "E"}, path.Vertices)
+	assert.Equal(20.0, path.Cost)
+
+	path, err = sgraph.ShortestPath("A", "E", nil)
+	assert.Nil(err)
+	assert.Len(path.Vertices, 3)
+	assert.Equal(2.0, path.Cost)
+
+	path, err = sgraph.ShortestPath("A", "A", weight)
+	assert.Nil(err)
+	assert.Equal([]interface{}{"A"}, path.Vertices)
+	assert.Zero(path.Cost)
+
+	_, err = sgraph.ShortestPath("A", "X", weight)
+	assert.Equal(ErrNoPath, err)
+
+	_, err = sgraph.ShortestPath("A", "Z", weight)
+	assert.Equal(ErrVertexNotFound, err)
+
+	_, err = sgraph.ShortestPath("A", "E", func(v, w interface{}) float64 {
+		return -1
+	})
+	assert.Equal(ErrNegativeWeight, err)
+}
+
+func TestAStar(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	// a 10x10 grid with a wall at x == 5 that is only open at y == 9
+	for x := 0; x < 10; x++ {
+		for y := 0; y < 10; y++ {
+			if x < 9 && (x != 4 || y == 9) {
+				sgraph.AddEdge(point{x, y}, point{x + 1, y})
+			}
+			if y < 9 {
+				sgraph.AddEdge(point{x, y}, point{x, y + 1})
+			}
+		}
+	}
+
+	target := point{9, 0}
+	h := func(v interface{}) float64 {
+		return euclidean(v, target)
+	}
+
+	path, err := sgraph.AStar(point{0, 0}, target, euclidean, h)
+	assert.Nil(err)
+	assert.Equal(27.0, path.Cost)
+	assert.Len(path.Vertices, 28)
+	assert.Equal(point{0, 0}, path.Vertices[0])
+	assert.Equal(target, path.Vertices[len(path.Vertices)-1])
+	assert.Contains(path.Vertices, point{5, 9})
+
+	dijkstra, err := sgraph.ShortestPath(point{0, 0}, target, euclidean)
+	assert.Nil(err)
+	assert.Equal(path.Cost, dijkstra.Cost)
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 /*
 Package graph provides graph implementations. Currently, this includes an
-undirected simple graph.
+undirected simple graph along with traversal and shortest path algorithms
+that operate on it, a directed graph supporting topological ordering
+and scheduling, and a weighted graph supporting minimum spanning trees
+and maximum flows.
 */
 package graph
 
@@ -105,6 +108,19 @@ func (g *SimpleGraph) Adj(v interface{}) ([]interface{}, error) {
 	return adj, nil
 }
 
+// Vertices returns all of the vertices in the SimpleGraph in no
+// particular order
+func (g *SimpleGraph) Vertices() []interface{} {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertices := make([]interface{}, 0, len(g.adjacencyList))
+	for v := range g.adjacencyList {
+		vertices = append(vertices, v)
+	}
+	return vertices
+}
+
 // Degree returns the number of vertices connected to v
 func (g *SimpleGraph) Degree(v interface{}) (int, error) {
 	g.mutex.RLock()
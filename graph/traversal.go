@@ -0,0 +1,170 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+// Iterator walks the vertices of a graph in the order defined by the
+// traversal that created it.  Next must be called before the first
+// call to Value.
+type Iterator interface {
+	// Next advances the iterator and returns a bool indicating if
+	// there is a vertex to be read with Value.
+	Next() bool
+	// Value returns the vertex at the iterator's current position or
+	// nil if the traversal is exhausted.
+	Value() interface{}
+}
+
+// bfsIterator lazily performs a breadth-first traversal, only expanding
+// a vertex's neighbors once the vertex has been returned.
+type bfsIterator struct {
+	g       *SimpleGraph
+	queue   []interface{}
+	visited map[interface{}]struct{}
+	current interface{}
+}
+
+func (iter *bfsIterator) Next() bool {
+	if len(iter.queue) == 0 {
+		iter.current = nil
+		return false
+	}
+
+	iter.current = iter.queue[0]
+	iter.queue[0] = nil
+	iter.queue = iter.queue[1:]
+
+	adj, _ := iter.g.Adj(iter.current)
+	for _, w := range adj {
+		if _, ok := iter.visited[w]; ok {
+			continue
+		}
+		iter.visited[w] = struct{}{}
+		iter.queue = append(iter.queue, w)
+	}
+	return true
+}
+
+func (iter *bfsIterator) Value() interface{} {
+	return iter.current
+}
+
+// dfsIterator lazily performs a preorder depth-first traversal.
+type dfsIterator struct {
+	g       *SimpleGraph
+	stack   []interface{}
+	visited map[interface{}]struct{}
+	current interface{}
+}
+
+func (iter *dfsIterator) Next() bool {
+	for len(iter.stack) > 0 {
+		v := iter.stack[len(iter.stack)-1]
+		iter.stack[len(iter.stack)-1] = nil
+		iter.stack = iter.stack[:len(iter.stack)-1]
+
+		if _, ok := iter.visited[v]; ok {
+			continue
+		}
+		iter.visited[v] = struct{}{}
+
+		adj, _ := iter.g.Adj(v)
+		for _, w := range adj {
+			if _, ok := iter.visited[w]; !ok {
+				iter.stack = append(iter.stack, w)
+			}
+		}
+
+		iter.current = v
+		return true
+	}
+
+	iter.current = nil
+	return false
+}
+
+func (iter *dfsIterator) Value() interface{} {
+	return iter.current
+}
+
+// BFS returns an iterator that visits every vertex reachable from start
+// in breadth-first order.  Neighbors of a single vertex are visited in
+// no particular order.  Returns ErrVertexNotFound if start is not in
+// the graph.
+func (g *SimpleGraph) BFS(start interface{}) (Iterator, error) {
+	if _, err := g.Degree(start); err != nil {
+		return nil, err
+	}
+
+	return &bfsIterator{
+		g:       g,
+		queue:   []interface{}{start},
+		visited: map[interface{}]struct{}{start: {}},
+	}, nil
+}
+
+// DFS returns an iterator that visits every vertex reachable from start
+// in depth-first preorder.  Neighbors of a single vertex are visited in
+// no particular order.  Returns ErrVertexNotFound if start is not in
+// the graph.
+func (g *SimpleGraph) DFS(start interface{}) (Iterator, error) {
+	if _, err := g.Degree(start); err != nil {
+		return nil, err
+	}
+
+	return &dfsIterator{
+		g:       g,
+		stack:   []interface{}{start},
+		visited: make(map[interface{}]struct{}),
+	}, nil
+}
+
+// ConnectedComponents partitions the vertices of the graph into its
+// connected components.  Neither the components nor the vertices within
+// them are returned in any particular order.
+func (g *SimpleGraph) ConnectedComponents() [][]interface{} {
+	visited := make(map[interface{}]struct{})
+	components := make([][]interface{}, 0)
+
+	for _, v := range g.Vertices() {
+		if _, ok := visited[v]; ok {
+			continue
+		}
+
+		component := make([]interface{}, 0)
+		iter, _ := g.BFS(v)
+		for iter.Next() {
+			visited[iter.Value()] = struct{}{}
+			component = append(component, iter.Value())
+		}
+		components = append(components, component)
+	}
+
+	return components
+}
+
+// HasCycle returns a bool indicating if the graph contains a cycle.
+// As the graph is simple, this is the case exactly when some connected
+// component has at least as many edges as it has vertices.
+func (g *SimpleGraph) HasCycle() bool {
+	components := g.ConnectedComponents()
+
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	// a forest of k trees over v vertices has exactly v - k edges
+	return g.e > g.v-len(components)
+}
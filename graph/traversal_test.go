@@ -0,0 +1,125 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func exhaust(iter Iterator) []interface{} {
+	vertices := make([]interface{}, 0)
+	for iter.Next() {
+		vertices = append(vertices, iter.Value())
+	}
+	return vertices
+}
+
+func TestBFS(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	_, err := sgraph.BFS("A")
+	assert.Equal(ErrVertexNotFound, err)
+
+	sgraph.AddEdge("A", "B")
+	sgraph.AddEdge("A", "C")
+	sgraph.AddEdge("B", "D")
+	sgraph.AddEdge("C", "D")
+	sgraph.AddEdge("D", "E")
+	sgraph.AddEdge("F", "G")
+
+	iter, err := sgraph.BFS("A")
+	assert.Nil(err)
+	result := exhaust(iter)
+	assert.Len(result, 5)
+	assert.Equal("A", result[0])
+	assert.ElementsMatch([]interface{}{"B", "C"}, result[1:3])
+	assert.Equal("D", result[3])
+	assert.Equal("E", result[4])
+	assert.False(iter.Next())
+	assert.Nil(iter.Value())
+}
+
+func TestDFS(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	_, err := sgraph.DFS("A")
+	assert.Equal(ErrVertexNotFound, err)
+
+	sgraph.AddEdge("A", "B")
+	sgraph.AddEdge("B", "C")
+	sgraph.AddEdge("C", "D")
+	sgraph.AddEdge("A", "E")
+	sgraph.AddEdge("F", "G")
+
+	iter, err := sgraph.DFS("A")
+	assert.Nil(err)
+	result := exhaust(iter)
+	assert.Len(result, 5)
+	assert.Equal("A", result[0])
+
+	// whichever branch is taken first must be fully explored before
+	// the other is started
+	if result[1] == "B" {
+		assert.Equal([]interface{}{"A", "B", "C", "D", "E"}, result)
+	} else {
+		assert.Equal([]interface{}{"A", "E", "B", "C", "D"}, result)
+	}
+}
+
+func TestConnectedComponents(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	assert.Len(sgraph.ConnectedComponents(), 0)
+
+	sgraph.AddEdge("A", "B")
+	sgraph.AddEdge("B", "C")
+	sgraph.AddEdge("D", "E")
+	sgraph.AddEdge("F", "G")
+	sgraph.AddEdge("G", "H")
+	sgraph.AddEdge("H", "F")
+
+	components := sgraph.ConnectedComponents()
+	assert.Len(components, 3)
+	sizes := make([]int, 0, len(components))
+	for _, c := range components {
+		sizes = append(sizes, len(c))
+		if len(c) == 2 {
+			assert.ElementsMatch([]interface{}{"D", "E"}, c)
+		}
+	}
+	assert.ElementsMatch([]int{3, 2, 3}, sizes)
+}
+
+func TestHasCycle(t *testing.T) {
+	assert := assert.New(t)
+	sgraph := NewSimpleGraph()
+
+	assert.False(sgraph.HasCycle())
+
+	sgraph.AddEdge("A", "B")
+	sgraph.AddEdge("B", "C")
+	sgraph.AddEdge("D", "E")
+	assert.False(sgraph.HasCycle())
+
+	sgraph.AddEdge("C", "A")
+	assert.True(sgraph.HasCycle())
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+// UnionFind is a disjoint set forest over arbitrary comparable elements.
+// Find uses path compression and Union uses union by rank, so any
+// sequence of m operations over n elements runs in O(m α(n)) time.
+// Elements are added implicitly the first time they are seen.  This
+// structure is not threadsafe.
+type UnionFind struct {
+	parent map[interface{}]interface{}
+	rank   map[interface{}]int
+	sets   int
+}
+
+// Add adds v to the structure in a set of its own if it is not already
+// present.
+func (uf *UnionFind) Add(v interface{}) {
+	if _, ok := uf.parent[v]; ok {
+		return
+	}
+
+	uf.parent[v] = v
+	uf.rank[v] = 0
+	uf.sets++
+}
+
+// Find returns the representative element of the set containing v.
+func (uf *UnionFind) Find(v interface{}) interface{} {
+	uf.Add(v)
+
+	root := v
+	for uf.parent[root] != root {
+		root = uf.parent[root]
+	}
+
+	// compress the path so later finds are a single hop
+	for v != root {
+		next := uf.parent[v]
+		uf.parent[v] = root
+		v = next
+	}
+
+	return root
+}
+
+// Union merges the sets containing v and w.  Returns false if they were
+// already in the same set.
+func (uf *UnionFind) Union(v, w interface{}) bool {
+	rv, rw := uf.Find(v), uf.Find(w)
+	if rv == rw {
+		return false
+	}
+
+	switch {
+	case uf.rank[rv] < uf.rank[rw]:
+		uf.parent[rv] = rw
+	case uf.rank[rv] > uf.rank[rw]:
+		uf.parent[rw] = rv
+	default:
+		uf.parent[rw] = rv
+		uf.rank[rv]++
+	}
+
+	uf.sets--
+	return true
+}
+
+// Connected returns a bool indicating if v and w are in the same set.
+func (uf *UnionFind) Connected(v, w interface{}) bool {
+	return uf.Find(v) == uf.Find(w)
+}
+
+// Len returns the number of elements in the structure.
+func (uf *UnionFind) Len() int {
+	return len(uf.parent)
+}
+
+// Sets returns the number of disjoint sets in the structure.
+func (uf *UnionFind) Sets() int {
+	return uf.sets
+}
+
+// NewUnionFind creates and returns an empty UnionFind
+func NewUnionFind() *UnionFind {
+	return &UnionFind{
+		parent: make(map[interface{}]interface{}),
+		rank:   make(map[interface{}]int),
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnionFind(t *testing.T) {
+	assert := assert.New(t)
+	uf := NewUnionFind()
+
+	assert.Zero(uf.Len())
+	assert.Zero(uf.Sets())
+
+	for i := 0; i < 10; i++ {
+		uf.Add(i)
+	}
+	uf.Add(0)
+	assert.Equal(10, uf.Len())
+	assert.Equal(10, uf.Sets())
+
+	assert.True(uf.Union(0, 1))
+	assert.True(uf.Union(2, 3))
+	assert.True(uf.Union(1, 3))
+	assert.False(uf.Union(0, 2))
+	assert.Equal(7, uf.Sets())
+
+	assert.True(uf.Connected(0, 3))
+	assert.False(uf.Connected(0, 4))
+	assert.Equal(uf.Find(0), uf.Find(2))
+
+	// unseen elements are added implicitly
+	assert.Equal(11, uf.Find(11))
+	assert.Equal(11, uf.Len())
+	assert.Equal(8, uf.Sets())
+}
+
+func TestUnionFindPathCompression(t *testing.T) {
+	assert := assert.New(t)
+	uf := NewUnionFind()
+
+	for i := 1; i < 100; i++ {
+		uf.Union(i-1, i)
+	}
+	assert.Equal(1, uf.Sets())
+
+	root := uf.Find(99)
+	for i := 0; i < 100; i++ {
+		uf.Find(i)
+		assert.Equal(root, uf.parent[i])
+	}
+	assert.True(uf.rank[root] <= 1)
+}
+
+func BenchmarkUnionFind(b *testing.B) {
+	numItems := 1000
+	for i := 0; i < b.N; i++ {
+		uf := NewUnionFind()
+		for j := 1; j < numItems; j++ {
+			uf.Union(j-1, j)
+		}
+		for j := 0; j < numItems; j++ {
+			uf.Find(j)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import "sync"
+
+// Edge is a weighted edge between two vertices.  In an undirected graph
+// From and To may be given in either order.
+type Edge struct {
+	From, To interface{}
+	Weight   float64
+}
+
+type weightedVertex struct {
+	adj     []interface{}
+	weights map[interface{}]float64
+}
+
+// WeightedGraph is a mutable, non-persistent graph whose edges carry a
+// float64 weight.  It may be either directed or undirected.  Parallel
+// edges and self-loops are not permitted.  Vertices and edges are
+// remembered in insertion order so that algorithms run over the graph
+// produce deterministic results.
+type WeightedGraph struct {
+	mutex    sync.RWMutex
+	directed bool
+	vertices map[interface{}]*weightedVertex
+	order    []interface{}
+	edges    []Edge
+}
+
+// Directed returns a bool indicating if the edges of the graph are
+// directed.
+func (g *WeightedGraph) Directed() bool {
+	return g.directed
+}
+
+// V returns the number of vertices in the WeightedGraph
+func (g *WeightedGraph) V() int {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return len(g.order)
+}
+
+// E returns the number of edges in the WeightedGraph
+func (g *WeightedGraph) E() int {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return len(g.edges)
+}
+
+// AddVertex adds v to the graph if it does not already exist.  This
+// is only required for vertices without any edges.
+func (g *WeightedGraph) AddVertex(v interface{}) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.addVertex(v)
+}
+
+// AddEdge will create an edge with the provided weight between vertices
+// v and w.  If the graph is directed the edge runs from v to w.
+func (g *WeightedGraph) AddEdge(v, w interface{}, weight float64) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if v == w {
+		return ErrSelfLoop
+	}
+
+	from := g.addVertex(v)
+	to := g.addVertex(w)
+
+	if _, ok := from.weights[w]; ok {
+		return ErrParallelEdge
+	}
+
+	from.weights[w] = weight
+	from.adj = append(from.adj, w)
+	if !g.directed {
+		to.weights[v] = weight
+		to.adj = append(to.adj, v)
+	}
+	g.edges = append(g.edges, Edge{From: v, To: w, Weight: weight})
+	return nil
+}
+
+// Weight returns the weight of the edge between v and w and a bool
+// indicating if such an edge exists.
+func (g *WeightedGraph) Weight(v, w interface{}) (float64, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return 0, false
+	}
+	weight, ok := vertex.weights[w]
+	return weight, ok
+}
+
+// Adj returns the list of all vertices reachable from v over a single
+// edge
+func (g *WeightedGraph) Adj(v interface{}) ([]interface{}, error) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertex, ok := g.vertices[v]
+	if !ok {
+		return nil, ErrVertexNotFound
+	}
+
+	adj := make([]interface{}, len(vertex.adj))
+	copy(adj, vertex.adj)
+	return adj, nil
+}
+
+// Vertices returns all of the vertices in the WeightedGraph in the
+// order they were added
+func (g *WeightedGraph) Vertices() []interface{} {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	vertices := make([]interface{}, len(g.order))
+	copy(vertices, g.order)
+	return vertices
+}
+
+// Edges returns all of the edges in the WeightedGraph in the order they
+// were added
+func (g *WeightedGraph) Edges() []Edge {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	edges := make([]Edge, len(g.edges))
+	copy(edges, g.edges)
+	return edges
+}
+
+func (g *WeightedGraph) addVertex(v interface{}) *weightedVertex {
+	vertex, ok := g.vertices[v]
+	if !ok {
+		vertex = &weightedVertex{weights: make(map[interface{}]float64)}
+		g.vertices[v] = vertex
+		g.order = append(g.order, v)
+	}
+	return vertex
+}
+
+// NewWeightedGraph creates and returns an undirected WeightedGraph
+func NewWeightedGraph() *WeightedGraph {
+	return &WeightedGraph{
+		vertices: make(map[interface{}]*weightedVertex),
+	}
+}
+
+// NewWeightedDigraph creates and returns a directed WeightedGraph
+func NewWeightedDigraph() *WeightedGraph {
+	g := NewWeightedGraph()
+	g.directed = true
+	return g
+}
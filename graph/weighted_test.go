@@ -0,0 +1,71 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedAddEdge(t *testing.T) {
+	assert := assert.New(t)
+	wgraph := NewWeightedGraph()
+	assert.False(wgraph.Directed())
+
+	assert.Nil(wgraph.AddEdge("A", "B", 3))
+	assert.Equal(ErrParallelEdge, wgraph.AddEdge("B", "A", 4))
+	assert.Equal(ErrSelfLoop, wgraph.AddEdge("A", "A", 1))
+	assert.Nil(wgraph.AddEdge("B", "C", 5))
+	wgraph.AddVertex("D")
+
+	assert.Equal(4, wgraph.V())
+	assert.Equal(2, wgraph.E())
+	assert.Equal([]interface{}{"A", "B", "C", "D"}, wgraph.Vertices())
+	assert.Equal([]Edge{{"A", "B", 3}, {"B", "C", 5}}, wgraph.Edges())
+
+	w, ok := wgraph.Weight("B", "A")
+	assert.True(ok)
+	assert.Equal(3.0, w)
+	_, ok = wgraph.Weight("A", "C")
+	assert.False(ok)
+
+	adj, err := wgraph.Adj("B")
+	assert.Nil(err)
+	assert.Equal([]interface{}{"A", "C"}, adj)
+	_, err = wgraph.Adj("E")
+	assert.Equal(ErrVertexNotFound, err)
+}
+
+func TestWeightedDigraphAddEdge(t *testing.T) {
+	assert := assert.New(t)
+	wgraph := NewWeightedDigraph()
+	assert.True(wgraph.Directed())
+
+	assert.Nil(wgraph.AddEdge("A", "B", 3))
+	assert.Equal(ErrParallelEdge, wgraph.AddEdge("A", "B", 4))
+	assert.Nil(wgraph.AddEdge("B", "A", 4))
+	assert.Equal(2, wgraph.E())
+
+	w, ok := wgraph.Weight("B", "A")
+	assert.True(ok)
+	assert.Equal(4.0, w)
+
+	adj, err := wgraph.Adj("A")
+	assert.Nil(err)
+	assert.Equal([]interface{}{"B"}, adj)
+}
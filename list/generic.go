@@ -0,0 +1,258 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+// List is an immutable, persistent linked list of items of type T.  The
+// nil *List is the empty list, and every method may be called on it.
+// Each node records the length of the list it starts, so Length is
+// O(1).
+type List[T any] struct {
+	head   T
+	tail   *List[T]
+	length uint
+}
+
+// NewList returns a list holding the given items, the first item being
+// the head.
+func NewList[T any](items ...T) *List[T] {
+	return prependList(items, nil)
+}
+
+// Head returns the head of the list. The bool will be false if the list is
+// empty.
+func (l *List[T]) Head() (T, bool) {
+	if l == nil {
+		var zero T
+		return zero, false
+	}
+	return l.head, true
+}
+
+// Tail returns the tail of the list. The bool will be false if the list is
+// empty.
+func (l *List[T]) Tail() (*List[T], bool) {
+	if l == nil {
+		return nil, false
+	}
+	return l.tail, true
+}
+
+// IsEmpty indicates if the list is empty.
+func (l *List[T]) IsEmpty() bool {
+	return l == nil
+}
+
+// Length returns the number of items in the list.
+func (l *List[T]) Length() uint {
+	if l == nil {
+		return 0
+	}
+	return l.length
+}
+
+// Add will add the item to the list, returning the new list.
+func (l *List[T]) Add(head T) *List[T] {
+	return &List[T]{head: head, tail: l, length: l.Length() + 1}
+}
+
+// Insert will insert the item at the given position, returning the new
+// list or an error if the position is invalid.
+func (l *List[T]) Insert(val T, pos uint) (*List[T], error) {
+	if pos > l.Length() {
+		return nil, ErrEmptyList
+	}
+
+	items := l.Take(pos).ToSlice()
+	return prependList(items, l.Drop(pos).Add(val)), nil
+}
+
+// Get returns the item at the given position. The bool will be false if the
+// position is invalid.
+func (l *List[T]) Get(pos uint) (T, bool) {
+	return l.Drop(pos).Head()
+}
+
+// Remove will remove the item at the given position, returning the new list
+// or an error if the position is invalid.
+func (l *List[T]) Remove(pos uint) (*List[T], error) {
+	if pos >= l.Length() {
+		return nil, ErrEmptyList
+	}
+
+	items := l.Take(pos).ToSlice()
+	return prependList(items, l.Drop(pos+1)), nil
+}
+
+// Find applies the predicate function to the list and returns the first item
+// which matches.
+func (l *List[T]) Find(pred func(T) bool) (T, bool) {
+	for curr := l; curr != nil; curr = curr.tail {
+		if pred(curr.head) {
+			return curr.head, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// FindIndex applies the predicate function to the list and returns the index
+// of the first item which matches or -1 if there is no match.
+func (l *List[T]) FindIndex(pred func(T) bool) int {
+	idx := 0
+	for curr := l; curr != nil; curr = curr.tail {
+		if pred(curr.head) {
+			return idx
+		}
+		idx++
+	}
+	return -1
+}
+
+// Reverse returns a new list with the items in reverse order.
+func (l *List[T]) Reverse() *List[T] {
+	var reversed *List[T]
+	for curr := l; curr != nil; curr = curr.tail {
+		reversed = reversed.Add(curr.head)
+	}
+	return reversed
+}
+
+// Concat returns a list of the items of this list followed by the items of
+// the given list, which is shared by the result.
+func (l *List[T]) Concat(other *List[T]) *List[T] {
+	if other == nil {
+		return l
+	}
+	return prependList(l.ToSlice(), other)
+}
+
+// Filter returns a list of the items matching the predicate function. The
+// longest tail of matching items is shared by the result.
+func (l *List[T]) Filter(pred func(T) bool) *List[T] {
+	var kept []T
+	shared := l
+	for curr := l; curr != nil; curr = curr.tail {
+		if pred(curr.head) {
+			continue
+		}
+
+		// everything since the last failing item matched, and the items
+		// after this one may turn out to be a shareable tail
+		for ; shared != curr; shared = shared.tail {
+			kept = append(kept, shared.head)
+		}
+		shared = curr.tail
+	}
+	return prependList(kept, shared)
+}
+
+// Take returns a list of the first n items of the list.
+func (l *List[T]) Take(n uint) *List[T] {
+	if n >= l.Length() {
+		return l
+	}
+
+	items := make([]T, 0, n)
+	for curr := l; uint(len(items)) < n; curr = curr.tail {
+		items = append(items, curr.head)
+	}
+	return NewList(items...)
+}
+
+// Drop returns the list without its first n items, which shares the
+// remaining items with this list.
+func (l *List[T]) Drop(n uint) *List[T] {
+	curr := l
+	for ; n > 0 && curr != nil; n-- {
+		curr = curr.tail
+	}
+	return curr
+}
+
+// ToSlice returns the items of the list from head to tail.
+func (l *List[T]) ToSlice() []T {
+	items := make([]T, 0, l.Length())
+	for curr := l; curr != nil; curr = curr.tail {
+		items = append(items, curr.head)
+	}
+	return items
+}
+
+// Equal indicates if both lists hold equal items, as determined by the
+// comparator function, in the same order.  The comparator is called even
+// for items the lists share.
+func (l *List[T]) Equal(other *List[T], eq func(a, b T) bool) bool {
+	if l.Length() != other.Length() {
+		return false
+	}
+
+	for curr := l; curr != nil; curr, other = curr.tail, other.tail {
+		if !eq(curr.head, other.head) {
+			return false
+		}
+	}
+	return true
+}
+
+// Map applies the function to each item in the list and returns the list of
+// the results.
+func Map[T, U any](l *List[T], f func(T) U) *List[U] {
+	results := make([]U, 0, l.Length())
+	for curr := l; curr != nil; curr = curr.tail {
+		results = append(results, f(curr.head))
+	}
+	return NewList(results...)
+}
+
+// FoldLeft combines the items of the list from head to tail, starting with
+// the initial value.
+func FoldLeft[T, A any](l *List[T], init A, f func(acc A, item T) A) A {
+	acc := init
+	for curr := l; curr != nil; curr = curr.tail {
+		acc = f(acc, curr.head)
+	}
+	return acc
+}
+
+// FoldRight combines the items of the list from tail to head, starting with
+// the initial value.
+func FoldRight[T, A any](l *List[T], init A, f func(item T, acc A) A) A {
+	items := l.ToSlice()
+	acc := init
+	for i := len(items) - 1; i >= 0; i-- {
+		acc = f(items[i], acc)
+	}
+	return acc
+}
+
+// Zip returns a list of Pairs of the items of both lists at the same
+// positions, as long as the shorter of the two.
+func Zip[A, B any](a *List[A], b *List[B]) *List[Pair[A, B]] {
+	var pairs []Pair[A, B]
+	for ; a != nil && b != nil; a, b = a.tail, b.tail {
+		pairs = append(pairs, Pair[A, B]{First: a.head, Second: b.head})
+	}
+	return NewList(pairs...)
+}
+
+// prependList returns the list of the given items followed by the tail.
+func prependList[T any](items []T, tail *List[T]) *List[T] {
+	for i := len(items) - 1; i >= 0; i-- {
+		tail = tail.Add(items[i])
+	}
+	return tail
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericListEmpty(t *testing.T) {
+	assert := assert.New(t)
+	var l *List[int]
+
+	head, ok := l.Head()
+	assert.Equal(0, head)
+	assert.False(ok)
+	tail, ok := l.Tail()
+	assert.Nil(tail)
+	assert.False(ok)
+	assert.True(l.IsEmpty())
+	assert.Equal(uint(0), l.Length())
+	assert.Equal([]int{}, l.ToSlice())
+	assert.Nil(NewList[int]())
+}
+
+func TestGenericListAddGet(t *testing.T) {
+	assert := assert.New(t)
+	l := NewList(1, 2, 3)
+	assert.Equal(uint(3), l.Length())
+	assert.Equal([]int{1, 2, 3}, l.ToSlice())
+
+	l2 := l.Add(0)
+	assert.Equal(uint(4), l2.Length())
+	tail, _ := l2.Tail()
+	assert.True(tail == l)
+
+	item, ok := l2.Get(3)
+	assert.True(ok)
+	assert.Equal(3, item)
+	_, ok = l2.Get(4)
+	assert.False(ok)
+}
+
+func TestGenericListInsertRemove(t *testing.T) {
+	assert := assert.New(t)
+	l := NewList(1, 2, 3)
+
+	inserted, err := l.Insert(9, 1)
+	assert.NoError(err)
+	assert.Equal([]int{1, 9, 2, 3}, inserted.ToSlice())
+	assert.True(inserted.Drop(2) == l.Drop(1))
+	inserted, err = l.Insert(9, 3)
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 9}, inserted.ToSlice())
+	_, err = l.Insert(9, 4)
+	assert.Equal(ErrEmptyList, err)
+
+	removed, err := l.Remove(1)
+	assert.NoError(err)
+	assert.Equal([]int{1, 3}, removed.ToSlice())
+	assert.True(removed.Drop(1) == l.Drop(2))
+	_, err = l.Remove(3)
+	assert.Equal(ErrEmptyList, err)
+
+	assert.Equal([]int{1, 2, 3}, l.ToSlice())
+}
+
+func TestGenericListFind(t *testing.T) {
+	assert := assert.New(t)
+	l := NewList(1, 2, 3, 4)
+	even := func(x int) bool { return x%2 == 0 }
+
+	item, ok := l.Find(even)
+	assert.True(ok)
+	assert.Equal(2, item)
+	assert.Equal(1, l.FindIndex(even))
+
+	_, ok = l.Find(func(x int) bool { return x > 4 })
+	assert.False(ok)
+	assert.Equal(-1, l.FindIndex(func(x int) bool { return x > 4 }))
+}
+
+func TestGenericListOperations(t *testing.T) {
+	assert := assert.New(t)
+	l := NewList(1, 2, 3, 4, 6)
+	other := NewList(7, 8)
+
+	assert.Equal([]int{6, 4, 3, 2, 1}, l.Reverse().ToSlice())
+
+	concat := l.Concat(other)
+	assert.Equal([]int{1, 2, 3, 4, 6, 7, 8}, concat.ToSlice())
+	assert.True(concat.Drop(5) == other)
+	assert.True(l.Concat(nil) == l)
+
+	filtered := l.Filter(func(x int) bool { return x%2 == 0 })
+	assert.Equal([]int{2, 4, 6}, filtered.ToSlice())
+	assert.True(filtered.Drop(1) == l.Drop(3))
+	calls := 0
+	l.Filter(func(x int) bool {
+		calls++
+		return x%2 == 0
+	})
+	assert.Equal(5, calls)
+
+	assert.Equal([]int{1, 2}, l.Take(2).ToSlice())
+	assert.True(l.Take(10) == l)
+	assert.Equal([]int{4, 6}, l.Drop(3).ToSlice())
+	assert.Nil(l.Drop(10))
+
+	assert.True(l.Equal(NewList(1, 2, 3, 4, 6), func(a, b int) bool { return a == b }))
+	assert.False(l.Equal(other, func(a, b int) bool { return a == b }))
+	assert.False(l.Equal(NewList(1, 2, 3, 4, 5), func(a, b int) bool { return a == b }))
+	nan := NewList(math.NaN())
+	assert.False(nan.Equal(nan, func(a, b float64) bool { return a == b }))
+}
+
+func TestGenericListFunctions(t *testing.T) {
+	assert := assert.New(t)
+	l := NewList(1, 2, 3)
+
+	assert.Equal([]string{"1", "2", "3"}, Map(l, strconv.Itoa).ToSlice())
+	assert.Equal("x123", FoldLeft(l, "x", func(acc string, item int) string {
+		return acc + strconv.Itoa(item)
+	}))
+	assert.Equal("x321", FoldRight(l, "x", func(item int, acc string) string {
+		return acc + strconv.Itoa(item)
+	}))
+
+	zipped := Zip(l, NewList("a", "b"))
+	assert.Equal([]Pair[int, string]{{1, "a"}, {2, "b"}}, zipped.ToSlice())
+	assert.True(Zip(l, (*List[string])(nil)).IsEmpty())
+}
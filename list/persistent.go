@@ -16,7 +16,9 @@ limitations under the License.
 
 /*
 Package list provides list implementations. Currently, this includes a
-persistent, immutable linked list.
+persistent, immutable linked list, its generic counterpart List, and a
+persistent vector, built as a relaxed radix balanced tree, for fast
+indexed access, slicing and concatenation.
 */
 package list
 
@@ -73,6 +75,55 @@ type PersistentList interface {
 	// Map applies the function to each entry in the list and returns the
 	// resulting slice.
 	Map(func(interface{}) interface{}) []interface{}
+
+	// Reverse returns a new list with the items in reverse order.
+	Reverse() PersistentList
+
+	// Concat returns a list of the items of this list followed by the items
+	// of the given list, which is shared by the result.
+	Concat(PersistentList) PersistentList
+
+	// Filter returns a list of the items matching the predicate function.
+	// The longest tail of matching items is shared by the result.
+	Filter(func(interface{}) bool) PersistentList
+
+	// FoldLeft combines the items of the list from head to tail, starting
+	// with the initial value.
+	FoldLeft(init interface{}, f func(acc, item interface{}) interface{}) interface{}
+
+	// FoldRight combines the items of the list from tail to head, starting
+	// with the initial value.
+	FoldRight(init interface{}, f func(item, acc interface{}) interface{}) interface{}
+
+	// Take returns a list of the first n items of the list.
+	Take(n uint) PersistentList
+
+	// Drop returns the list without its first n items, which shares the
+	// remaining items with this list.
+	Drop(n uint) PersistentList
+
+	// Zip returns a list of Pairs of the items of this list and the given
+	// list at the same positions, as long as the shorter of the two.
+	Zip(PersistentList) PersistentList
+
+	// ToSlice returns the items of the list from head to tail.
+	ToSlice() []interface{}
+
+	// Equal indicates if both lists hold equal items, as determined by the
+	// comparator function, in the same order.
+	Equal(other PersistentList, eq func(a, b interface{}) bool) bool
+}
+
+// Pair holds the items at the same position of two zipped lists.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// FromSlice returns a list holding the given items, the first item being
+// the head.
+func FromSlice(items []interface{}) PersistentList {
+	return prepend(items, Empty)
 }
 
 type emptyList struct{}
@@ -143,6 +194,63 @@ func (e *emptyList) Map(func(interface{}) interface{}) []interface{} {
 	return nil
 }
 
+// Reverse returns a new list with the items in reverse order.
+func (e *emptyList) Reverse() PersistentList {
+	return e
+}
+
+// Concat returns a list of the items of this list followed by the items of
+// the given list, which is shared by the result.
+func (e *emptyList) Concat(other PersistentList) PersistentList {
+	return other
+}
+
+// Filter returns a list of the items matching the predicate function. The
+// longest tail of matching items is shared by the result.
+func (e *emptyList) Filter(func(interface{}) bool) PersistentList {
+	return e
+}
+
+// FoldLeft combines the items of the list from head to tail, starting with
+// the initial value.
+func (e *emptyList) FoldLeft(init interface{}, f func(acc, item interface{}) interface{}) interface{} {
+	return init
+}
+
+// FoldRight combines the items of the list from tail to head, starting with
+// the initial value.
+func (e *emptyList) FoldRight(init interface{}, f func(item, acc interface{}) interface{}) interface{} {
+	return init
+}
+
+// Take returns a list of the first n items of the list.
+func (e *emptyList) Take(n uint) PersistentList {
+	return e
+}
+
+// Drop returns the list without its first n items, which shares the
+// remaining items with this list.
+func (e *emptyList) Drop(n uint) PersistentList {
+	return e
+}
+
+// Zip returns a list of Pairs of the items of this list and the given list
+// at the same positions, as long as the shorter of the two.
+func (e *emptyList) Zip(PersistentList) PersistentList {
+	return e
+}
+
+// ToSlice returns the items of the list from head to tail.
+func (e *emptyList) ToSlice() []interface{} {
+	return nil
+}
+
+// Equal indicates if both lists hold equal items, as determined by the
+// comparator function, in the same order.
+func (e *emptyList) Equal(other PersistentList, eq func(a, b interface{}) bool) bool {
+	return other.IsEmpty()
+}
+
 type list struct {
 	head interface{}
 	tail PersistentList
@@ -253,3 +361,142 @@ func (l *list) FindIndex(pred func(interface{}) bool) int {
 func (l *list) Map(f func(interface{}) interface{}) []interface{} {
 	return append(l.tail.Map(f), f(l.head))
 }
+
+// Reverse returns a new list with the items in reverse order.
+func (l *list) Reverse() PersistentList {
+	reversed := Empty
+	for curr := PersistentList(l); !curr.IsEmpty(); curr, _ = curr.Tail() {
+		head, _ := curr.Head()
+		reversed = reversed.Add(head)
+	}
+	return reversed
+}
+
+// Concat returns a list of the items of this list followed by the items of
+// the given list, which is shared by the result.
+func (l *list) Concat(other PersistentList) PersistentList {
+	if other.IsEmpty() {
+		return l
+	}
+	return prepend(l.ToSlice(), other)
+}
+
+// Filter returns a list of the items matching the predicate function. The
+// longest tail of matching items is shared by the result.
+func (l *list) Filter(pred func(interface{}) bool) PersistentList {
+	var (
+		kept   []interface{}
+		shared PersistentList = l
+	)
+	for curr := PersistentList(l); !curr.IsEmpty(); curr, _ = curr.Tail() {
+		head, _ := curr.Head()
+		if pred(head) {
+			continue
+		}
+
+		// everything since the last failing item matched, and the items
+		// after this one may turn out to be a shareable tail
+		for ; shared != curr; shared, _ = shared.Tail() {
+			item, _ := shared.Head()
+			kept = append(kept, item)
+		}
+		shared, _ = curr.Tail()
+	}
+	return prepend(kept, shared)
+}
+
+// FoldLeft combines the items of the list from head to tail, starting with
+// the initial value.
+func (l *list) FoldLeft(init interface{}, f func(acc, item interface{}) interface{}) interface{} {
+	acc := init
+	for curr := PersistentList(l); !curr.IsEmpty(); curr, _ = curr.Tail() {
+		head, _ := curr.Head()
+		acc = f(acc, head)
+	}
+	return acc
+}
+
+// FoldRight combines the items of the list from tail to head, starting with
+// the initial value.
+func (l *list) FoldRight(init interface{}, f func(item, acc interface{}) interface{}) interface{} {
+	items := l.ToSlice()
+	acc := init
+	for i := len(items) - 1; i >= 0; i-- {
+		acc = f(items[i], acc)
+	}
+	return acc
+}
+
+// Take returns a list of the first n items of the list.
+func (l *list) Take(n uint) PersistentList {
+	var items []interface{}
+	curr := PersistentList(l)
+	for ; n > 0 && !curr.IsEmpty(); n-- {
+		head, _ := curr.Head()
+		items = append(items, head)
+		curr, _ = curr.Tail()
+	}
+	if curr.IsEmpty() {
+		return l
+	}
+	return FromSlice(items)
+}
+
+// Drop returns the list without its first n items, which shares the
+// remaining items with this list.
+func (l *list) Drop(n uint) PersistentList {
+	curr := PersistentList(l)
+	for ; n > 0 && !curr.IsEmpty(); n-- {
+		curr, _ = curr.Tail()
+	}
+	return curr
+}
+
+// Zip returns a list of Pairs of the items of this list and the given list
+// at the same positions, as long as the shorter of the two.
+func (l *list) Zip(other PersistentList) PersistentList {
+	var pairs []interface{}
+	for curr := PersistentList(l); !curr.IsEmpty() && !other.IsEmpty(); {
+		first, _ := curr.Head()
+		second, _ := other.Head()
+		pairs = append(pairs, Pair[interface{}, interface{}]{First: first, Second: second})
+		curr, _ = curr.Tail()
+		other, _ = other.Tail()
+	}
+	return FromSlice(pairs)
+}
+
+// ToSlice returns the items of the list from head to tail.
+func (l *list) ToSlice() []interface{} {
+	var items []interface{}
+	for curr := PersistentList(l); !curr.IsEmpty(); curr, _ = curr.Tail() {
+		head, _ := curr.Head()
+		items = append(items, head)
+	}
+	return items
+}
+
+// Equal indicates if both lists hold equal items, as determined by the
+// comparator function, in the same order.  The comparator is called even
+// for items the lists share.
+func (l *list) Equal(other PersistentList, eq func(a, b interface{}) bool) bool {
+	curr := PersistentList(l)
+	for !curr.IsEmpty() && !other.IsEmpty() {
+		a, _ := curr.Head()
+		b, _ := other.Head()
+		if !eq(a, b) {
+			return false
+		}
+		curr, _ = curr.Tail()
+		other, _ = other.Tail()
+	}
+	return curr.IsEmpty() && other.IsEmpty()
+}
+
+// prepend returns the list of the given items followed by the tail.
+func prepend(items []interface{}, tail PersistentList) PersistentList {
+	for i := len(items) - 1; i >= 0; i-- {
+		tail = tail.Add(items[i])
+	}
+	return tail
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package list
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -287,3 +288,123 @@ func TestMap(t *testing.T) {
 	l := Empty.Add(1).Add(2).Add(3).Add(4)
 	assert.Equal([]interface{}{1, 4, 9, 16}, l.Map(f))
 }
+
+func TestFromSliceToSlice(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(Empty, FromSlice(nil))
+	assert.Nil(Empty.ToSlice())
+
+	l := FromSlice([]interface{}{1, 2, 3})
+	head, _ := l.Head()
+	assert.Equal(1, head)
+	assert.Equal([]interface{}{1, 2, 3}, l.ToSlice())
+}
+
+func TestReverse(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(Empty, Empty.Reverse())
+
+	l := FromSlice([]interface{}{1, 2, 3})
+	assert.Equal([]interface{}{3, 2, 1}, l.Reverse().ToSlice())
+	assert.Equal([]interface{}{1, 2, 3}, l.ToSlice())
+}
+
+func TestConcat(t *testing.T) {
+	assert := assert.New(t)
+	l1 := FromSlice([]interface{}{1, 2})
+	l2 := FromSlice([]interface{}{3, 4})
+
+	l := l1.Concat(l2)
+	assert.Equal([]interface{}{1, 2, 3, 4}, l.ToSlice())
+	// the second list is shared
+	assert.True(l.Drop(2) == l2)
+	assert.True(Empty.Concat(l2) == l2)
+	assert.True(l1.Concat(Empty) == l1)
+}
+
+func TestFilter(t *testing.T) {
+	assert := assert.New(t)
+	even := func(x interface{}) bool { return x.(int)%2 == 0 }
+	assert.Equal(Empty, Empty.Filter(even))
+
+	l := FromSlice([]interface{}{1, 2, 3, 4, 6, 8})
+	filtered := l.Filter(even)
+	assert.Equal([]interface{}{2, 4, 6, 8}, filtered.ToSlice())
+	// the tail after the last rejected item is shared
+	assert.True(filtered.Drop(1) == l.Drop(3))
+
+	all := FromSlice([]interface{}{2, 4})
+	assert.True(all.Filter(even) == all)
+	assert.True(FromSlice([]interface{}{1, 3}).Filter(even).IsEmpty())
+
+	// the predicate sees every item exactly once
+	var seen []interface{}
+	l.Filter(func(x interface{}) bool {
+		seen = append(seen, x)
+		return even(x)
+	})
+	assert.Equal(l.ToSlice(), seen)
+}
+
+func TestFold(t *testing.T) {
+	assert := assert.New(t)
+	concat := func(acc, item interface{}) interface{} {
+		return acc.(string) + item.(string)
+	}
+	assert.Equal("x", Empty.FoldLeft("x", concat))
+	assert.Equal("x", Empty.FoldRight("x", concat))
+
+	l := FromSlice([]interface{}{"a", "b", "c"})
+	assert.Equal("xabc", l.FoldLeft("x", concat))
+	assert.Equal("xcba", l.FoldRight("x", func(item, acc interface{}) interface{} {
+		return acc.(string) + item.(string)
+	}))
+	assert.Equal("abcx", l.FoldRight("x", concat))
+}
+
+func TestTakeDrop(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(Empty, Empty.Take(2))
+	assert.Equal(Empty, Empty.Drop(2))
+
+	l := FromSlice([]interface{}{1, 2, 3})
+	assert.Equal([]interface{}{1, 2}, l.Take(2).ToSlice())
+	assert.True(l.Take(0).IsEmpty())
+	assert.True(l.Take(3) == l)
+	assert.True(l.Take(5) == l)
+
+	assert.Equal([]interface{}{3}, l.Drop(2).ToSlice())
+	assert.True(l.Drop(0) == l)
+	assert.True(l.Drop(5).IsEmpty())
+}
+
+func TestZip(t *testing.T) {
+	assert := assert.New(t)
+	l1 := FromSlice([]interface{}{1, 2, 3})
+	l2 := FromSlice([]interface{}{"a", "b"})
+
+	assert.Equal([]interface{}{
+		Pair[interface{}, interface{}]{First: 1, Second: "a"},
+		Pair[interface{}, interface{}]{First: 2, Second: "b"},
+	}, l1.Zip(l2).ToSlice())
+	assert.True(l1.Zip(Empty).IsEmpty())
+	assert.True(Empty.Zip(l1).IsEmpty())
+}
+
+func TestEqual(t *testing.T) {
+	assert := assert.New(t)
+	eq := func(a, b interface{}) bool { return a == b }
+
+	l := FromSlice([]interface{}{1, 2, 3})
+	assert.True(Empty.Equal(Empty, eq))
+	assert.False(Empty.Equal(l, eq))
+	assert.False(l.Equal(Empty, eq))
+	assert.True(l.Equal(FromSlice([]interface{}{1, 2, 3}), eq))
+	assert.True(l.Equal(l, eq))
+	assert.False(l.Equal(FromSlice([]interface{}{1, 2}), eq))
+	assert.False(l.Equal(FromSlice([]interface{}{1, 2, 4}), eq))
+
+	// shared items are still compared
+	nan := FromSlice([]interface{}{math.NaN()})
+	assert.False(nan.Equal(nan, func(a, b interface{}) bool { return a.(float64) == b.(float64) }))
+}
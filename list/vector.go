@@ -0,0 +1,543 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import "errors"
+
+// ErrIndexOutOfBounds is returned when a position is outside of a
+// vector.
+var ErrIndexOutOfBounds = errors.New("Index out of bounds")
+
+const (
+	vectorBits  = 5
+	vectorWidth = 1 << vectorBits
+
+	// vectorExtras is the number of nodes more than the optimal number
+	// that concatenation tolerates at each level before rebalancing.
+	vectorExtras = 2
+)
+
+// EmptyVector is an empty Vector.
+var EmptyVector = &Vector{root: &vectorNode{}}
+
+// vectorEdit identifies the transient allowed to modify a node in
+// place.  It is not zero sized so that distinct edits never compare
+// equal.
+type vectorEdit struct{ _ byte }
+
+// vectorNode is a node of a relaxed radix balanced tree.  Leaves hold
+// up to vectorWidth items and branches up to vectorWidth children along
+// with the cumulative number of items under each child, which lets
+// children hold fewer items than a perfectly dense tree would.
+type vectorNode struct {
+	edit     *vectorEdit
+	items    []interface{}
+	children []*vectorNode
+	sizes    []uint
+}
+
+func newVectorBranch(e *vectorEdit, children []*vectorNode, height uint) *vectorNode {
+	sizes := make([]uint, len(children), vectorWidth)
+	total := uint(0)
+	for i, child := range children {
+		total += child.size(height - 1)
+		sizes[i] = total
+	}
+	return &vectorNode{edit: e, children: children, sizes: sizes}
+}
+
+// newVectorPath returns a node of the given height holding one item.
+func newVectorPath(e *vectorEdit, height uint, val interface{}) *vectorNode {
+	if height == 0 {
+		items := make([]interface{}, 1, vectorWidth)
+		items[0] = val
+		return &vectorNode{edit: e, items: items}
+	}
+	return newVectorBranch(e, append(make([]*vectorNode, 0, vectorWidth), newVectorPath(e, height-1, val)), height)
+}
+
+func (n *vectorNode) size(height uint) uint {
+	if height == 0 {
+		return uint(len(n.items))
+	}
+	return n.sizes[len(n.sizes)-1]
+}
+
+// slots returns the number of items or children held by the node.
+func (n *vectorNode) slots(height uint) int {
+	if height == 0 {
+		return len(n.items)
+	}
+	return len(n.children)
+}
+
+// editable returns the node if it belongs to the edit and a copy
+// belonging to the edit otherwise.
+func (n *vectorNode) editable(e *vectorEdit) *vectorNode {
+	if e != nil && n.edit == e {
+		return n
+	}
+
+	cp := &vectorNode{edit: e}
+	if n.children == nil {
+		cp.items = append(make([]interface{}, 0, vectorWidth), n.items...)
+	} else {
+		cp.children = append(make([]*vectorNode, 0, vectorWidth), n.children...)
+		cp.sizes = append(make([]uint, 0, vectorWidth), n.sizes...)
+	}
+	return cp
+}
+
+// locate returns the child of a branch holding the item at position i
+// and the position of the item within that child.  A child never holds
+// more than a dense subtree would, so the search starts at the radix
+// guess and only moves right.
+func (n *vectorNode) locate(height, i uint) (int, uint) {
+	idx := int(i >> (vectorBits * height))
+	for n.sizes[idx] <= i {
+		idx++
+	}
+	if idx > 0 {
+		i -= n.sizes[idx-1]
+	}
+	return idx, i
+}
+
+func (n *vectorNode) get(height, i uint) interface{} {
+	for ; height > 0; height-- {
+		var idx int
+		idx, i = n.locate(height, i)
+		n = n.children[idx]
+	}
+	return n.items[i]
+}
+
+func (n *vectorNode) set(e *vectorEdit, height, i uint, val interface{}) *vectorNode {
+	n = n.editable(e)
+	if height == 0 {
+		n.items[i] = val
+		return n
+	}
+
+	idx, i := n.locate(height, i)
+	n.children[idx] = n.children[idx].set(e, height-1, i, val)
+	return n
+}
+
+// push appends an item to the rightmost leaf under the node, returning
+// the updated node or nil if the rightmost path has no room.
+func (n *vectorNode) push(e *vectorEdit, height uint, val interface{}) *vectorNode {
+	if height == 0 {
+		if len(n.items) == vectorWidth {
+			return nil
+		}
+		n = n.editable(e)
+		n.items = append(n.items, val)
+		return n
+	}
+
+	last := len(n.children) - 1
+	if child := n.children[last].push(e, height-1, val); child != nil {
+		n = n.editable(e)
+		n.children[last] = child
+		n.sizes[last]++
+		return n
+	}
+
+	if len(n.children) == vectorWidth {
+		return nil
+	}
+	n = n.editable(e)
+	n.children = append(n.children, newVectorPath(e, height-1, val))
+	n.sizes = append(n.sizes, n.sizes[last]+1)
+	return n
+}
+
+// takeLeft returns a node holding the first count items under the node,
+// which must be at least one.
+func (n *vectorNode) takeLeft(height, count uint) *vectorNode {
+	if height == 0 {
+		return &vectorNode{items: append([]interface{}(nil), n.items[:count]...)}
+	}
+
+	idx, i := n.locate(height, count-1)
+	children := append([]*vectorNode(nil), n.children[:idx+1]...)
+	children[idx] = children[idx].takeLeft(height-1, i+1)
+	return newVectorBranch(nil, children, height)
+}
+
+// dropLeft returns a node holding all but the first count items under
+// the node, which must hold more than count items.
+func (n *vectorNode) dropLeft(height, count uint) *vectorNode {
+	if height == 0 {
+		return &vectorNode{items: append([]interface{}(nil), n.items[count:]...)}
+	}
+
+	idx, i := n.locate(height, count)
+	children := append([]*vectorNode(nil), n.children[idx:]...)
+	children[0] = children[0].dropLeft(height-1, i)
+	return newVectorBranch(nil, children, height)
+}
+
+func (n *vectorNode) each(height uint, fn func(interface{}) bool) bool {
+	if height == 0 {
+		for _, item := range n.items {
+			if !fn(item) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, child := range n.children {
+		if !child.each(height-1, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeVectorNodes concatenates two nodes of the same height, returning
+// one or two nodes of that height holding all of their items.
+func mergeVectorNodes(left, right *vectorNode, height uint) []*vectorNode {
+	if height == 0 {
+		items := make([]interface{}, 0, len(left.items)+len(right.items))
+		items = append(append(items, left.items...), right.items...)
+		if len(items) <= vectorWidth {
+			return []*vectorNode{{items: items}}
+		}
+		return []*vectorNode{{items: items[:vectorWidth:vectorWidth]}, {items: items[vectorWidth:]}}
+	}
+
+	last := len(left.children) - 1
+	middle := mergeVectorNodes(left.children[last], right.children[0], height-1)
+
+	all := make([]*vectorNode, 0, last+len(middle)+len(right.children)-1)
+	all = append(all, left.children[:last]...)
+	all = append(all, middle...)
+	all = append(all, right.children[1:]...)
+	all = rebalanceVectorNodes(all, height-1)
+
+	if len(all) <= vectorWidth {
+		return []*vectorNode{newVectorBranch(nil, all, height)}
+	}
+	return []*vectorNode{
+		newVectorBranch(nil, all[:vectorWidth:vectorWidth], height),
+		newVectorBranch(nil, all[vectorWidth:], height),
+	}
+}
+
+// rebalanceVectorNodes redistributes the slots of a run of nodes of the
+// same height so that it uses at most vectorExtras nodes more than the
+// optimum, following the concatenation plan of Bagwell and Rompf.
+// Nodes that keep their contents are reused.
+func rebalanceVectorNodes(nodes []*vectorNode, height uint) []*vectorNode {
+	plan := make([]int, len(nodes))
+	total := 0
+	for i, node := range nodes {
+		plan[i] = node.slots(height)
+		total += plan[i]
+	}
+
+	optimal := (total + vectorWidth - 1) / vectorWidth
+	n := len(plan)
+	if n <= optimal+vectorExtras {
+		return nodes
+	}
+
+	for i := 0; n > optimal+vectorExtras; i-- {
+		// full nodes have no room to absorb slots, so they are skipped
+		for plan[i] == vectorWidth {
+			i++
+		}
+
+		// spread the slots of node i over the nodes after it
+		for remaining := plan[i]; remaining > 0; i++ {
+			size := remaining + plan[i+1]
+			if size > vectorWidth {
+				size = vectorWidth
+			}
+			plan[i] = size
+			remaining += plan[i+1] - size
+		}
+		copy(plan[i:n-1], plan[i+1:n])
+		n--
+	}
+
+	result := make([]*vectorNode, 0, n)
+	src, offset := 0, 0
+	for _, size := range plan[:n] {
+		if offset == 0 && nodes[src].slots(height) == size {
+			result = append(result, nodes[src])
+			src++
+			continue
+		}
+
+		node := &vectorNode{}
+		if height == 0 {
+			node.items = make([]interface{}, 0, size)
+		} else {
+			node.children = make([]*vectorNode, 0, size)
+		}
+		for filled := 0; filled < size; {
+			from := nodes[src]
+			take := from.slots(height) - offset
+			if take > size-filled {
+				take = size - filled
+			}
+			if height == 0 {
+				node.items = append(node.items, from.items[offset:offset+take]...)
+			} else {
+				node.children = append(node.children, from.children[offset:offset+take]...)
+			}
+			filled += take
+			offset += take
+			if offset == from.slots(height) {
+				src, offset = src+1, 0
+			}
+		}
+		if height > 0 {
+			node = newVectorBranch(nil, node.children, height)
+		}
+		result = append(result, node)
+	}
+
+	return result
+}
+
+// Vector is an immutable, persistent vector implemented as a relaxed
+// radix balanced (RRB) tree with a branching factor of 32.  Get, Set and
+// Append run in O(log32 n) time, and Slice and Concat also run in
+// O(log n) time while sharing all untouched nodes with the original
+// vectors, which makes Insert and Remove at any position cheap too.
+// Every operation returns a new vector and leaves the receiver
+// unchanged, so Vectors are safe for concurrent use.  Use a
+// TransientVector to build a vector from many items.
+type Vector struct {
+	root   *vectorNode
+	height uint
+	length uint
+}
+
+// NewVector returns a vector holding the provided items in order.
+func NewVector(items ...interface{}) *Vector {
+	return EmptyVector.Append(items...)
+}
+
+// Len returns the number of items in the vector.
+func (v *Vector) Len() uint {
+	return v.length
+}
+
+// IsEmpty indicates if the vector is empty.
+func (v *Vector) IsEmpty() bool {
+	return v.length == 0
+}
+
+// Get returns the item at the given position.  The bool will be false if
+// the position is invalid.
+func (v *Vector) Get(pos uint) (interface{}, bool) {
+	if pos >= v.length {
+		return nil, false
+	}
+	return v.root.get(v.height, pos), true
+}
+
+// Set returns a new vector with the item at the given position replaced
+// or an error if the position is invalid.
+func (v *Vector) Set(pos uint, val interface{}) (*Vector, error) {
+	if pos >= v.length {
+		return nil, ErrIndexOutOfBounds
+	}
+	return &Vector{root: v.root.set(nil, v.height, pos, val), height: v.height, length: v.length}, nil
+}
+
+// Append returns a new vector with the provided items added to the end.
+func (v *Vector) Append(items ...interface{}) *Vector {
+	if len(items) == 0 {
+		return v
+	}
+	return v.Transient().Append(items...).Persistent()
+}
+
+// Slice returns a new vector holding the items from position from up to
+// but not including position to, or an error if the range is invalid.
+func (v *Vector) Slice(from, to uint) (*Vector, error) {
+	if from > to || to > v.length {
+		return nil, ErrIndexOutOfBounds
+	}
+	if from == to {
+		return EmptyVector, nil
+	}
+	if from == 0 && to == v.length {
+		return v, nil
+	}
+
+	root := v.root.takeLeft(v.height, to)
+	if from > 0 {
+		root = root.dropLeft(v.height, from)
+	}
+	return newVector(root, v.height, to-from), nil
+}
+
+// Concat returns a new vector holding the items of this vector followed
+// by the items of other.
+func (v *Vector) Concat(other *Vector) *Vector {
+	if other.IsEmpty() {
+		return v
+	}
+	if v.IsEmpty() {
+		return other
+	}
+
+	left, right, height := v.root, other.root, v.height
+	for ; height < other.height; height++ {
+		left = newVectorBranch(nil, []*vectorNode{left}, height+1)
+	}
+	for h := other.height; h < height; h++ {
+		right = newVectorBranch(nil, []*vectorNode{right}, h+1)
+	}
+
+	nodes := mergeVectorNodes(left, right, height)
+	if len(nodes) == 1 {
+		return newVector(nodes[0], height, v.length+other.length)
+	}
+	return newVector(newVectorBranch(nil, nodes, height+1), height+1, v.length+other.length)
+}
+
+// Insert returns a new vector with the item inserted at the given
+// position, shifting later items right, or an error if the position is
+// invalid.
+func (v *Vector) Insert(val interface{}, pos uint) (*Vector, error) {
+	left, err := v.Slice(0, pos)
+	if err != nil {
+		return nil, err
+	}
+	right, _ := v.Slice(pos, v.length)
+	return left.Append(val).Concat(right), nil
+}
+
+// Remove returns a new vector without the item at the given position or
+// an error if the position is invalid.
+func (v *Vector) Remove(pos uint) (*Vector, error) {
+	if pos >= v.length {
+		return nil, ErrIndexOutOfBounds
+	}
+	left, _ := v.Slice(0, pos)
+	right, _ := v.Slice(pos+1, v.length)
+	return left.Concat(right), nil
+}
+
+// Range calls fn for each item in the vector in order until fn returns
+// false.
+func (v *Vector) Range(fn func(pos uint, item interface{}) bool) {
+	pos := uint(0)
+	v.root.each(v.height, func(item interface{}) bool {
+		if !fn(pos, item) {
+			return false
+		}
+		pos++
+		return true
+	})
+}
+
+// ToSlice returns the items of the vector in order.
+func (v *Vector) ToSlice() []interface{} {
+	items := make([]interface{}, 0, v.length)
+	v.root.each(v.height, func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Transient returns a TransientVector initialized with the items of
+// this vector.  The vector itself is not affected by changes to the
+// transient.
+func (v *Vector) Transient() *TransientVector {
+	return &TransientVector{root: v.root, height: v.height, length: v.length, edit: &vectorEdit{}}
+}
+
+// newVector returns a vector with the root stripped of branches that
+// have a single child.
+func newVector(root *vectorNode, height, length uint) *Vector {
+	for height > 0 && len(root.children) == 1 {
+		root = root.children[0]
+		height--
+	}
+	return &Vector{root: root, height: height, length: length}
+}
+
+// TransientVector is a mutable builder for a Vector.  It modifies the
+// nodes it has already copied in place instead of copying them again,
+// which makes building a vector from many items much cheaper than
+// appending them one at a time to a Vector.  TransientVector is not
+// threadsafe.
+type TransientVector struct {
+	root   *vectorNode
+	height uint
+	length uint
+	edit   *vectorEdit
+}
+
+// Len returns the number of items in the vector.
+func (t *TransientVector) Len() uint {
+	return t.length
+}
+
+// Get returns the item at the given position.  The bool will be false if
+// the position is invalid.
+func (t *TransientVector) Get(pos uint) (interface{}, bool) {
+	if pos >= t.length {
+		return nil, false
+	}
+	return t.root.get(t.height, pos), true
+}
+
+// Set replaces the item at the given position or returns an error if the
+// position is invalid.
+func (t *TransientVector) Set(pos uint, val interface{}) error {
+	if pos >= t.length {
+		return ErrIndexOutOfBounds
+	}
+	t.root = t.root.set(t.edit, t.height, pos, val)
+	return nil
+}
+
+// Append adds the provided items to the end of the vector and returns
+// the transient for chaining.
+func (t *TransientVector) Append(items ...interface{}) *TransientVector {
+	for _, item := range items {
+		root := t.root.push(t.edit, t.height, item)
+		if root == nil {
+			children := append(make([]*vectorNode, 0, vectorWidth), t.root, newVectorPath(t.edit, t.height, item))
+			root = newVectorBranch(t.edit, children, t.height+1)
+			t.height++
+		}
+		t.root = root
+		t.length++
+	}
+	return t
+}
+
+// Persistent returns a Vector holding the current items.  The transient
+// remains usable afterwards, but later changes to it copy nodes again
+// rather than modify those now shared with the returned vector.
+func (t *TransientVector) Persistent() *Vector {
+	t.edit = &vectorEdit{}
+	return &Vector{root: t.root, height: t.height, length: t.length}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package list
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkVector verifies the structure of the vector and that it holds
+// the expected items.
+func checkVector(t *testing.T, expected []interface{}, v *Vector) {
+	require.Equal(t, uint(len(expected)), v.Len())
+	require.Equal(t, uint(len(expected)), checkVectorNode(t, v.root, v.height))
+	if len(expected) == 0 {
+		assert.Empty(t, v.ToSlice())
+	} else {
+		require.Equal(t, expected, v.ToSlice())
+	}
+	for i, item := range expected {
+		got, ok := v.Get(uint(i))
+		require.True(t, ok)
+		require.Equal(t, item, got)
+	}
+	_, ok := v.Get(uint(len(expected)))
+	assert.False(t, ok)
+}
+
+func checkVectorNode(t *testing.T, n *vectorNode, height uint) uint {
+	if height == 0 {
+		require.Nil(t, n.children)
+		require.True(t, len(n.items) <= vectorWidth)
+		return uint(len(n.items))
+	}
+
+	require.NotEmpty(t, n.children)
+	require.True(t, len(n.children) <= vectorWidth)
+	require.Len(t, n.sizes, len(n.children))
+	total := uint(0)
+	for i, child := range n.children {
+		total += checkVectorNode(t, child, height-1)
+		require.Equal(t, total, n.sizes[i])
+	}
+	return total
+}
+
+func intRange(from, to int) []interface{} {
+	items := make([]interface{}, 0, to-from)
+	for i := from; i < to; i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+func TestVectorEmpty(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(EmptyVector.IsEmpty())
+	assert.Equal(uint(0), EmptyVector.Len())
+	_, ok := EmptyVector.Get(0)
+	assert.False(ok)
+	_, err := EmptyVector.Set(0, 1)
+	assert.Equal(ErrIndexOutOfBounds, err)
+	_, err = EmptyVector.Remove(0)
+	assert.Equal(ErrIndexOutOfBounds, err)
+	assert.Equal(EmptyVector, NewVector())
+}
+
+func TestVectorAppendGetSet(t *testing.T) {
+	v := EmptyVector
+	var versions []*Vector
+	for i := 0; i < 2000; i++ {
+		v = v.Append(i)
+		if i%100 == 0 {
+			versions = append(versions, v)
+		}
+	}
+	checkVector(t, intRange(0, 2000), v)
+	assert.Equal(t, uint(2), v.height)
+
+	// older versions are untouched
+	for i, version := range versions {
+		checkVector(t, intRange(0, i*100+1), version)
+	}
+
+	updated, err := v.Set(1000, `x`)
+	require.NoError(t, err)
+	item, _ := updated.Get(1000)
+	assert.Equal(t, `x`, item)
+	item, _ = v.Get(1000)
+	assert.Equal(t, 1000, item)
+	_, err = v.Set(2000, `x`)
+	assert.Equal(t, ErrIndexOutOfBounds, err)
+}
+
+func TestVectorSlice(t *testing.T) {
+	expected := intRange(0, 5000)
+	v := NewVector(expected...)
+
+	for _, r := range [][2]uint{{0, 5000}, {0, 0}, {10, 10}, {0, 1}, {4999, 5000}, {31, 33}, {1000, 4321}, {1024, 1056}} {
+		s, err := v.Slice(r[0], r[1])
+		require.NoError(t, err)
+		checkVector(t, expected[r[0]:r[1]], s)
+	}
+
+	_, err := v.Slice(10, 5)
+	assert.Equal(t, ErrIndexOutOfBounds, err)
+	_, err = v.Slice(0, 5001)
+	assert.Equal(t, ErrIndexOutOfBounds, err)
+
+	// slices can be sliced and appended to
+	s, _ := v.Slice(100, 4000)
+	s, _ = s.Slice(50, 3000)
+	s = s.Append(`a`, `b`)
+	checkVector(t, append(append([]interface{}(nil), expected[150:3100]...), `a`, `b`), s)
+	checkVector(t, expected, v)
+}
+
+func TestVectorConcat(t *testing.T) {
+	for _, sizes := range [][2]int{{0, 5}, {5, 0}, {1, 1}, {32, 1}, {33, 1000}, {1000, 33}, {1025, 5000}, {40000, 7}} {
+		left := NewVector(intRange(0, sizes[0])...)
+		right := NewVector(intRange(sizes[0], sizes[0]+sizes[1])...)
+		checkVector(t, intRange(0, sizes[0]+sizes[1]), left.Concat(right))
+	}
+}
+
+func TestVectorConcatManySmall(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	v := EmptyVector
+	var expected []interface{}
+	for i := 0; i < 300; i++ {
+		size := r.Intn(40)
+		part := intRange(len(expected), len(expected)+size)
+		expected = append(expected, part...)
+		if r.Intn(2) == 0 {
+			v = v.Concat(NewVector(part...))
+		} else {
+			prefix := NewVector(expected[:len(expected)-size]...)
+			v = prefix.Concat(NewVector(part...))
+		}
+	}
+	checkVector(t, expected, v)
+}
+
+func TestVectorInsertRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	v := NewVector(intRange(0, 500)...)
+	expected := intRange(0, 500)
+
+	for i := 0; i < 500; i++ {
+		pos := r.Intn(len(expected) + 1)
+		if r.Intn(2) == 0 && pos < len(expected) {
+			var err error
+			v, err = v.Remove(uint(pos))
+			require.NoError(t, err)
+			expected = append(expected[:pos:pos], expected[pos+1:]...)
+		} else {
+			var err error
+			v, err = v.Insert(-i, uint(pos))
+			require.NoError(t, err)
+			expected = append(expected[:pos:pos], append([]interface{}{-i}, expected[pos:]...)...)
+		}
+	}
+	checkVector(t, expected, v)
+
+	_, err := v.Insert(0, v.Len()+1)
+	assert.Equal(t, ErrIndexOutOfBounds, err)
+}
+
+func TestVectorRange(t *testing.T) {
+	v := NewVector(intRange(0, 100)...)
+	count := 0
+	v.Range(func(pos uint, item interface{}) bool {
+		assert.Equal(t, int(pos), item)
+		count++
+		return pos < 49
+	})
+	assert.Equal(t, 50, count)
+}
+
+func TestTransientVector(t *testing.T) {
+	assert := assert.New(t)
+	base := NewVector(intRange(0, 100)...)
+
+	transient := base.Transient()
+	transient.Append(intRange(100, 2000)...)
+	require.NoError(t, transient.Set(5, `x`))
+	assert.Equal(ErrIndexOutOfBounds, transient.Set(2000, `x`))
+	assert.Equal(uint(2000), transient.Len())
+	item, ok := transient.Get(5)
+	assert.True(ok)
+	assert.Equal(`x`, item)
+
+	v := transient.Persistent()
+	expected := intRange(0, 2000)
+	expected[5] = `x`
+	checkVector(t, expected, v)
+	checkVector(t, intRange(0, 100), base)
+
+	// the transient may keep going without affecting v
+	transient.Set(6, `y`)
+	transient.Append(`z`)
+	checkVector(t, expected, v)
+	item, _ = transient.Get(6)
+	assert.Equal(`y`, item)
+	assert.Equal(uint(2001), transient.Persistent().Len())
+}
+
+func BenchmarkVectorAppend(b *testing.B) {
+	v := EmptyVector
+	for i := 0; i < b.N; i++ {
+		v = v.Append(i)
+	}
+}
+
+func BenchmarkTransientVectorAppend(b *testing.B) {
+	transient := EmptyVector.Transient()
+	for i := 0; i < b.N; i++ {
+		transient.Append(i)
+	}
+}
+
+func BenchmarkVectorGet(b *testing.B) {
+	v := NewVector(intRange(0, 100000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v.Get(uint(i % 100000))
+	}
+}
+
+func BenchmarkVectorConcat(b *testing.B) {
+	left := NewVector(intRange(0, 100000)...)
+	right := NewVector(intRange(0, 1000)...)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		left.Concat(right)
+	}
+}
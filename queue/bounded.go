@@ -0,0 +1,327 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// boundedWaiter is a goroutine blocked on a BoundedQueue.  Waiters are
+// served by whichever goroutine makes room or adds items, which hands
+// the items over and closes ready, so a woken waiter never has to race
+// for the lock to claim what it was waiting for.
+type boundedWaiter struct {
+	ready  chan struct{}
+	items  []interface{}
+	number int64
+	done   bool
+}
+
+type boundedWaiters []*boundedWaiter
+
+func (w *boundedWaiters) remove(waiter *boundedWaiter) {
+	for i, other := range *w {
+		if other == waiter {
+			copy((*w)[i:], (*w)[i+1:])
+			(*w)[len(*w)-1] = nil
+			*w = (*w)[:len(*w)-1]
+			return
+		}
+	}
+}
+
+func (w *boundedWaiters) pop() *boundedWaiter {
+	waiter := (*w)[0]
+	copy((*w)[0:], (*w)[1:])
+	(*w)[len(*w)-1] = nil
+	*w = (*w)[:len(*w)-1]
+	return waiter
+}
+
+// BoundedQueue is a threadsafe FIFO queue holding at most a fixed
+// number of items.  Puts to a full queue block until there is room and
+// gets from an empty queue block until there are items.  Blocked
+// producers are served in the order they arrived, as are blocked
+// consumers, and the items of a single PutAll are always added together.
+// Calling Dispose releases every blocked goroutine with ErrDisposed.
+type BoundedQueue struct {
+	lock      sync.Mutex
+	items     items
+	capacity  int64
+	producers boundedWaiters
+	consumers boundedWaiters
+	disposed  bool
+}
+
+// NewBoundedQueue is a constructor for a new threadsafe queue holding
+// at most capacity items.  A capacity less than one is treated as one.
+func NewBoundedQueue(capacity int64) *BoundedQueue {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &BoundedQueue{
+		items:    make([]interface{}, 0, capacity),
+		capacity: capacity,
+	}
+}
+
+// Put adds the provided item to the queue, blocking until there is room
+// for it.  An error is returned if the queue is disposed.
+func (bq *BoundedQueue) Put(item interface{}) error {
+	return bq.PutAllContext(context.Background(), item)
+}
+
+// PutContext is like Put but also returns ctx.Err() if ctx is done
+// before there is room for the item.
+func (bq *BoundedQueue) PutContext(ctx context.Context, item interface{}) error {
+	return bq.PutAllContext(ctx, item)
+}
+
+// Offer adds the provided item to the queue if there is room for it
+// and no other producers are waiting.  Otherwise it returns false
+// without blocking.  An error is returned if the queue is disposed.
+func (bq *BoundedQueue) Offer(item interface{}) (bool, error) {
+	bq.lock.Lock()
+	defer bq.lock.Unlock()
+
+	if bq.disposed {
+		return false, ErrDisposed
+	}
+
+	if len(bq.producers) > 0 || int64(len(bq.items)) >= bq.capacity {
+		return false, nil
+	}
+
+	bq.items = append(bq.items, item)
+	bq.serve()
+	return true, nil
+}
+
+// PutAll adds all of the provided items to the queue at once, blocking
+// until there is room for all of them.  ErrExceedsCapacity is returned
+// if there are more items than the queue can hold.
+func (bq *BoundedQueue) PutAll(items ...interface{}) error {
+	return bq.PutAllContext(context.Background(), items...)
+}
+
+// PutAllContext is like PutAll but also returns ctx.Err() if ctx is
+// done before there is room for the items, in which case none of them
+// are added.
+func (bq *BoundedQueue) PutAllContext(ctx context.Context, items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	bq.lock.Lock()
+
+	if bq.disposed {
+		bq.lock.Unlock()
+		return ErrDisposed
+	}
+
+	if int64(len(items)) > bq.capacity {
+		bq.lock.Unlock()
+		return ErrExceedsCapacity
+	}
+
+	if len(bq.producers) == 0 && bq.free() >= int64(len(items)) {
+		bq.items = append(bq.items, items...)
+		bq.serve()
+		bq.lock.Unlock()
+		return nil
+	}
+
+	waiter := &boundedWaiter{ready: make(chan struct{}), items: items}
+	bq.producers = append(bq.producers, waiter)
+	bq.lock.Unlock()
+
+	select {
+	case <-waiter.ready:
+	case <-ctx.Done():
+		bq.lock.Lock()
+		if !waiter.done {
+			bq.producers.remove(waiter)
+			// the producers behind this one may fit now
+			bq.serve()
+			bq.lock.Unlock()
+			return ctx.Err()
+		}
+		bq.lock.Unlock()
+	}
+
+	if waiter.items == nil {
+		return ErrDisposed
+	}
+	return nil
+}
+
+// Get retrieves the next item from the queue, blocking until there is
+// one.  An error is returned if the queue is disposed.
+func (bq *BoundedQueue) Get() (interface{}, error) {
+	return bq.GetContext(context.Background())
+}
+
+// GetContext is like Get but also returns ctx.Err() if ctx is done
+// before there is an item in the queue.
+func (bq *BoundedQueue) GetContext(ctx context.Context) (interface{}, error) {
+	items, err := bq.GetUpToContext(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	return items[0], nil
+}
+
+// GetUpTo retrieves items from the queue.  If there are some items in
+// the queue, it returns a number UP TO the number passed in without
+// blocking.  Otherwise it blocks until items are put to the queue.
+func (bq *BoundedQueue) GetUpTo(number int64) ([]interface{}, error) {
+	return bq.GetUpToContext(context.Background(), number)
+}
+
+// GetUpToContext is like GetUpTo but also returns ctx.Err() if ctx is
+// done before there are items in the queue.
+func (bq *BoundedQueue) GetUpToContext(ctx context.Context, number int64) ([]interface{}, error) {
+	if number < 1 {
+		return []interface{}{}, nil
+	}
+
+	bq.lock.Lock()
+
+	if bq.disposed {
+		bq.lock.Unlock()
+		return nil, ErrDisposed
+	}
+
+	if len(bq.consumers) == 0 && len(bq.items) > 0 {
+		items := bq.items.get(number)
+		bq.serve()
+		bq.lock.Unlock()
+		return items, nil
+	}
+
+	waiter := &boundedWaiter{ready: make(chan struct{}), number: number}
+	bq.consumers = append(bq.consumers, waiter)
+	bq.lock.Unlock()
+
+	select {
+	case <-waiter.ready:
+	case <-ctx.Done():
+		bq.lock.Lock()
+		if !waiter.done {
+			bq.consumers.remove(waiter)
+			bq.lock.Unlock()
+			return nil, ctx.Err()
+		}
+		bq.lock.Unlock()
+	}
+
+	if waiter.items == nil {
+		return nil, ErrDisposed
+	}
+	return waiter.items, nil
+}
+
+func (bq *BoundedQueue) free() int64 {
+	return bq.capacity - int64(len(bq.items))
+}
+
+// serve hands items to waiting consumers and room to waiting producers,
+// in arrival order, until neither can make progress.  It must be called
+// with the lock held after anything that adds items or frees room.
+func (bq *BoundedQueue) serve() {
+	for {
+		progress := false
+
+		for len(bq.consumers) > 0 && len(bq.items) > 0 {
+			waiter := bq.consumers.pop()
+			waiter.items = bq.items.get(waiter.number)
+			waiter.done = true
+			close(waiter.ready)
+			progress = true
+		}
+
+		for len(bq.producers) > 0 && bq.free() >= int64(len(bq.producers[0].items)) {
+			waiter := bq.producers.pop()
+			bq.items = append(bq.items, waiter.items...)
+			waiter.done = true
+			close(waiter.ready)
+			progress = true
+		}
+
+		if !progress {
+			return
+		}
+	}
+}
+
+// Len returns the number of items in the queue.
+func (bq *BoundedQueue) Len() int64 {
+	bq.lock.Lock()
+	defer bq.lock.Unlock()
+
+	return int64(len(bq.items))
+}
+
+// Cap returns the maximum number of items the queue can hold.
+func (bq *BoundedQueue) Cap() int64 {
+	return bq.capacity
+}
+
+// Empty returns a bool indicating if the queue is empty.
+func (bq *BoundedQueue) Empty() bool {
+	return bq.Len() == 0
+}
+
+// Disposed returns a bool indicating if the queue has had Dispose
+// called on it.
+func (bq *BoundedQueue) Disposed() bool {
+	bq.lock.Lock()
+	defer bq.lock.Unlock()
+
+	return bq.disposed
+}
+
+// Dispose will dispose of the queue and return the items it held.  Any
+// blocked goroutines are released with ErrDisposed, and any subsequent
+// calls to Put or Get will return ErrDisposed.
+func (bq *BoundedQueue) Dispose() []interface{} {
+	bq.lock.Lock()
+	defer bq.lock.Unlock()
+
+	if bq.disposed {
+		return nil
+	}
+	bq.disposed = true
+
+	for _, waiter := range bq.producers {
+		waiter.items = nil
+		waiter.done = true
+		close(waiter.ready)
+	}
+	for _, waiter := range bq.consumers {
+		waiter.done = true
+		close(waiter.ready)
+	}
+
+	disposedItems := bq.items
+	bq.items = nil
+	bq.producers = nil
+	bq.consumers = nil
+	return disposedItems
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedPutGet(t *testing.T) {
+	bq := NewBoundedQueue(3)
+	assert.Equal(t, int64(3), bq.Cap())
+	assert.True(t, bq.Empty())
+
+	assert.Nil(t, bq.Put(1))
+	assert.Nil(t, bq.PutAll(2, 3))
+	assert.Equal(t, int64(3), bq.Len())
+
+	ok, err := bq.Offer(4)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	result, err := bq.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result)
+
+	ok, err = bq.Offer(4)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	items, err := bq.GetUpTo(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{2, 3, 4}, items)
+
+	assert.Equal(t, ErrExceedsCapacity, bq.PutAll(1, 2, 3, 4))
+}
+
+func TestBoundedPutBlocks(t *testing.T) {
+	bq := NewBoundedQueue(1)
+	bq.Put(1)
+
+	done := make(chan error)
+	go func() {
+		done <- bq.Put(2)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("put to a full queue should block")
+	case <-time.After(5 * time.Millisecond):
+	}
+
+	result, err := bq.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result)
+	assert.Nil(t, <-done)
+
+	result, err = bq.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result)
+}
+
+func TestBoundedGetBlocks(t *testing.T) {
+	bq := NewBoundedQueue(2)
+
+	done := make(chan []interface{})
+	go func() {
+		items, err := bq.GetUpTo(2)
+		assert.Nil(t, err)
+		done <- items
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	bq.PutAll(1, 2)
+	assert.Equal(t, []interface{}{1, 2}, <-done)
+}
+
+func TestBoundedProducerFairness(t *testing.T) {
+	bq := NewBoundedQueue(2)
+	bq.PutAll(0, 0)
+
+	// a large batch at the head must not be starved by small puts behind it
+	done := make(chan error, 2)
+	go func() {
+		done <- bq.PutAll(1, 1)
+	}()
+	for {
+		bq.lock.Lock()
+		waiting := len(bq.producers)
+		bq.lock.Unlock()
+		if waiting == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		done <- bq.Put(2)
+	}()
+
+	ok, err := bq.Offer(3)
+	assert.Nil(t, err)
+	assert.False(t, ok)
+
+	var result []interface{}
+	for len(result) < 5 {
+		items, err := bq.GetUpTo(1)
+		assert.Nil(t, err)
+		result = append(result, items...)
+	}
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-done)
+	assert.Equal(t, []interface{}{0, 0, 1, 1, 2}, result)
+}
+
+func TestBoundedContext(t *testing.T) {
+	bq := NewBoundedQueue(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err := bq.GetContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, bq.consumers, 0)
+
+	bq.Put(1)
+	assert.Equal(t, context.DeadlineExceeded, bq.PutContext(ctx, 2))
+	assert.Len(t, bq.producers, 0)
+	assert.Equal(t, int64(1), bq.Len())
+
+	// a canceled producer at the head lets the ones behind it through
+	done := make(chan error)
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		done <- bq.PutContext(ctx, 2)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	go func() {
+		done <- bq.Put(3)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	items, err := bq.GetUpTo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1}, items)
+	assert.Nil(t, <-done)
+	items, err = bq.GetUpTo(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{3}, items)
+}
+
+func TestBoundedDispose(t *testing.T) {
+	bq := NewBoundedQueue(1)
+	bq.Put(1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, ErrDisposed, bq.Put(2))
+	}()
+
+	empty := NewBoundedQueue(1)
+	go func() {
+		defer wg.Done()
+		_, err := empty.Get()
+		assert.Equal(t, ErrDisposed, err)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, []interface{}{1}, bq.Dispose())
+	empty.Dispose()
+	wg.Wait()
+
+	assert.True(t, bq.Disposed())
+	assert.Equal(t, ErrDisposed, bq.Put(1))
+	_, err := bq.Get()
+	assert.Equal(t, ErrDisposed, err)
+	_, err = bq.Offer(1)
+	assert.Equal(t, ErrDisposed, err)
+}
+
+func TestBoundedConcurrent(t *testing.T) {
+	bq := NewBoundedQueue(8)
+	const producers, perProducer = 4, 1000
+
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	for i := 0; i < producers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perProducer; j += 2 {
+				bq.PutAll(i, i)
+			}
+		}(i)
+	}
+
+	counts := make(map[interface{}]int)
+	for received := 0; received < producers*perProducer; {
+		items, err := bq.GetUpTo(3)
+		if !assert.Nil(t, err) {
+			return
+		}
+		for _, item := range items {
+			counts[item]++
+		}
+		received += len(items)
+	}
+	wg.Wait()
+
+	for i := 0; i < producers; i++ {
+		assert.Equal(t, perProducer, counts[i])
+	}
+	assert.True(t, bq.Empty())
+}
+
+func BenchmarkBoundedQueue(b *testing.B) {
+	bq := NewBoundedQueue(1024)
+	b.ResetTimer()
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			bq.Put(i)
+		}
+	}()
+
+	for i := 0; i < b.N; i++ {
+		bq.Get()
+	}
+}
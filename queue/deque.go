@@ -0,0 +1,288 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// dequeWaiter is a goroutine blocked popping from an empty Deque.  The
+// next push hands its item straight to the longest waiting one.
+type dequeWaiter struct {
+	ready chan struct{}
+	item  interface{}
+	err   error
+	done  bool
+}
+
+// Deque is a threadsafe double-ended queue backed by a circular buffer
+// that grows as necessary.  Pops either return ErrEmptyQueue right away
+// or, for the Get and Poll variants, block until an item is pushed to
+// either end.  As with Queue, Dispose releases blocked goroutines with
+// ErrDisposed and any subsequent call returns ErrDisposed.
+type Deque struct {
+	lock     sync.Mutex
+	items    []interface{}
+	head     int
+	count    int
+	waiters  []*dequeWaiter
+	disposed bool
+}
+
+// NewDeque is a constructor for a new threadsafe deque with room for
+// hint items before it has to grow.
+func NewDeque(hint int) *Deque {
+	if hint < 1 {
+		hint = 1
+	}
+
+	return &Deque{items: make([]interface{}, hint)}
+}
+
+// PushFront adds an item to the front of the deque.
+func (d *Deque) PushFront(item interface{}) error {
+	return d.push(item, true)
+}
+
+// PushBack adds an item to the back of the deque.
+func (d *Deque) PushBack(item interface{}) error {
+	return d.push(item, false)
+}
+
+func (d *Deque) push(item interface{}, front bool) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.disposed {
+		return ErrDisposed
+	}
+
+	// there are only waiters when the deque is empty, so either end
+	// would have given them this item
+	if len(d.waiters) > 0 {
+		waiter := d.waiters[0]
+		copy(d.waiters, d.waiters[1:])
+		d.waiters[len(d.waiters)-1] = nil
+		d.waiters = d.waiters[:len(d.waiters)-1]
+
+		waiter.item = item
+		waiter.done = true
+		close(waiter.ready)
+		return nil
+	}
+
+	if d.count == len(d.items) {
+		d.grow()
+	}
+
+	if front {
+		d.head = (d.head - 1 + len(d.items)) % len(d.items)
+		d.items[d.head] = item
+	} else {
+		d.items[(d.head+d.count)%len(d.items)] = item
+	}
+	d.count++
+	return nil
+}
+
+func (d *Deque) grow() {
+	items := make([]interface{}, 2*len(d.items))
+	n := copy(items, d.items[d.head:])
+	copy(items[n:], d.items[:d.head])
+	d.items = items
+	d.head = 0
+}
+
+// PopFront removes and returns the item at the front of the deque, or
+// ErrEmptyQueue if there is none.
+func (d *Deque) PopFront() (interface{}, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.pop(true)
+}
+
+// PopBack removes and returns the item at the back of the deque, or
+// ErrEmptyQueue if there is none.
+func (d *Deque) PopBack() (interface{}, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.pop(false)
+}
+
+func (d *Deque) pop(front bool) (interface{}, error) {
+	if d.disposed {
+		return nil, ErrDisposed
+	}
+	if d.count == 0 {
+		return nil, ErrEmptyQueue
+	}
+
+	index := d.head
+	if front {
+		d.head = (d.head + 1) % len(d.items)
+	} else {
+		index = (d.head + d.count - 1) % len(d.items)
+	}
+
+	item := d.items[index]
+	d.items[index] = nil // prevent memory leak
+	d.count--
+	return item, nil
+}
+
+// GetFront removes and returns the item at the front of the deque.  If
+// the deque is empty, this call blocks until an item is pushed.
+func (d *Deque) GetFront() (interface{}, error) {
+	return d.wait(context.Background(), 0, true)
+}
+
+// GetBack removes and returns the item at the back of the deque.  If
+// the deque is empty, this call blocks until an item is pushed.
+func (d *Deque) GetBack() (interface{}, error) {
+	return d.wait(context.Background(), 0, false)
+}
+
+// GetFrontContext is like GetFront but also returns ctx.Err() if ctx
+// is done before an item is pushed.
+func (d *Deque) GetFrontContext(ctx context.Context) (interface{}, error) {
+	return d.wait(ctx, 0, true)
+}
+
+// GetBackContext is like GetBack but also returns ctx.Err() if ctx is
+// done before an item is pushed.
+func (d *Deque) GetBackContext(ctx context.Context) (interface{}, error) {
+	return d.wait(ctx, 0, false)
+}
+
+// PollFront is like GetFront but returns ErrTimeout if no item is
+// pushed within the timeout.  A non-positive timeout will block until
+// an item is pushed.
+func (d *Deque) PollFront(timeout time.Duration) (interface{}, error) {
+	return d.wait(context.Background(), timeout, true)
+}
+
+// PollBack is like GetBack but returns ErrTimeout if no item is pushed
+// within the timeout.  A non-positive timeout will block until an item
+// is pushed.
+func (d *Deque) PollBack(timeout time.Duration) (interface{}, error) {
+	return d.wait(context.Background(), timeout, false)
+}
+
+func (d *Deque) wait(ctx context.Context, timeout time.Duration, front bool) (interface{}, error) {
+	d.lock.Lock()
+
+	if d.disposed || d.count > 0 {
+		item, err := d.pop(front)
+		d.lock.Unlock()
+		return item, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		d.lock.Unlock()
+		return nil, err
+	}
+
+	waiter := &dequeWaiter{ready: make(chan struct{})}
+	d.waiters = append(d.waiters, waiter)
+	d.lock.Unlock()
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	var err error
+	select {
+	case <-waiter.ready:
+	case <-timeoutC:
+		err = ErrTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+		if !waiter.done {
+			for i, w := range d.waiters {
+				if w == waiter {
+					d.waiters = append(d.waiters[:i], d.waiters[i+1:]...)
+					break
+				}
+			}
+			return nil, err
+		}
+	}
+
+	return waiter.item, waiter.err
+}
+
+// Len returns the number of items in the deque.
+func (d *Deque) Len() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.count
+}
+
+// Empty returns a bool indicating if the deque is empty.
+func (d *Deque) Empty() bool {
+	return d.Len() == 0
+}
+
+// Disposed returns a bool indicating if this deque has had Dispose
+// called on it.
+func (d *Deque) Disposed() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.disposed
+}
+
+// Dispose will dispose of this deque and return the items it held, from
+// front to back.  Any blocked goroutines are released with ErrDisposed.
+func (d *Deque) Dispose() []interface{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.disposed {
+		return nil
+	}
+	d.disposed = true
+
+	for _, waiter := range d.waiters {
+		waiter.err = ErrDisposed
+		waiter.done = true
+		close(waiter.ready)
+	}
+
+	disposedItems := make([]interface{}, 0, d.count)
+	for i := 0; i < d.count; i++ {
+		disposedItems = append(disposedItems, d.items[(d.head+i)%len(d.items)])
+	}
+
+	d.items = nil
+	d.count = 0
+	d.waiters = nil
+	return disposedItems
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDequePushPop(t *testing.T) {
+	d := NewDeque(2)
+
+	d.PushBack(2)
+	d.PushFront(1)
+	d.PushBack(3)
+	d.PushFront(0)
+	assert.Equal(t, 4, d.Len())
+
+	item, err := d.PopFront()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, item)
+	item, err = d.PopBack()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, item)
+	item, _ = d.PopBack()
+	assert.Equal(t, 2, item)
+	item, _ = d.PopFront()
+	assert.Equal(t, 1, item)
+
+	assert.True(t, d.Empty())
+	_, err = d.PopFront()
+	assert.Equal(t, ErrEmptyQueue, err)
+	_, err = d.PopBack()
+	assert.Equal(t, ErrEmptyQueue, err)
+}
+
+func TestDequeGrowWrapped(t *testing.T) {
+	d := NewDeque(4)
+
+	// leave the head in the middle of the buffer before growing
+	for i := 0; i < 3; i++ {
+		d.PushBack(i)
+	}
+	d.PopFront()
+	d.PopFront()
+	for i := 3; i < 10; i++ {
+		d.PushBack(i)
+	}
+	d.PushFront(1)
+
+	for i := 1; i < 10; i++ {
+		item, err := d.PopFront()
+		assert.Nil(t, err)
+		assert.Equal(t, i, item)
+	}
+}
+
+func TestDequeGetBlocks(t *testing.T) {
+	d := NewDeque(1)
+
+	results := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			item, err := d.GetBack()
+			assert.Nil(t, err)
+			results <- item
+		}()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	d.PushFront(1)
+	d.PushBack(2)
+	assert.ElementsMatch(t, []interface{}{1, 2}, []interface{}{<-results, <-results})
+	assert.True(t, d.Empty())
+}
+
+func TestDequePoll(t *testing.T) {
+	d := NewDeque(1)
+
+	_, err := d.PollFront(time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = d.GetFrontContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, d.waiters, 0)
+
+	// abandoned waiters must not swallow later items
+	d.PushBack(1)
+	item, err := d.PollBack(time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, item)
+}
+
+func TestDequeDispose(t *testing.T) {
+	d := NewDeque(1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			defer wg.Done()
+			_, err := d.GetFront()
+			assert.Equal(t, ErrDisposed, err)
+		}()
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Len(t, d.Dispose(), 0)
+	wg.Wait()
+
+	assert.True(t, d.Disposed())
+	assert.Equal(t, ErrDisposed, d.PushBack(1))
+	_, err := d.PopFront()
+	assert.Equal(t, ErrDisposed, err)
+	_, err = d.GetBackContext(context.Background())
+	assert.Equal(t, ErrDisposed, err)
+
+	d = NewDeque(1)
+	d.PushBack(2)
+	d.PushFront(1)
+	assert.Equal(t, []interface{}{1, 2}, d.Dispose())
+}
+
+func BenchmarkDeque(b *testing.B) {
+	d := NewDeque(64)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		d.PopFront()
+	}
+}
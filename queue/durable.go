@@ -0,0 +1,692 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SyncPolicy determines when a DurableQueue flushes its log to stable
+// storage.  Only writes that have been flushed survive a crash of the
+// machine; a crash of just the process loses nothing that was written.
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes before every Put, Ack or Nack returns.
+	SyncAlways SyncPolicy = iota
+	// SyncBatch flushes once every SyncEvery records.
+	SyncBatch
+	// SyncInterval flushes in the background every SyncInterval.
+	SyncInterval
+)
+
+// DurableOptions configures a DurableQueue.  The zero value gives a
+// queue of []byte items that syncs every write.
+type DurableOptions struct {
+	// Codec encodes items for the log.  Defaults to BytesCodec.
+	Codec Codec
+	// Sync determines when the log is flushed to stable storage.
+	Sync SyncPolicy
+	// SyncEvery is the number of records between flushes with
+	// SyncBatch.  Defaults to 100.
+	SyncEvery int
+	// SyncInterval is the time between flushes with SyncInterval.
+	// Defaults to one second.
+	SyncInterval time.Duration
+	// SegmentSize is the size at which a new log file is started.
+	// Defaults to 64MB.
+	SegmentSize int64
+	// RedeliveryTimeout is how long a retrieved item may go
+	// unacknowledged before it is made available to be retrieved again.
+	// If zero, items are only redelivered when nacked or when the queue
+	// is reopened.
+	RedeliveryTimeout time.Duration
+}
+
+// Message is an item retrieved from a DurableQueue.  It must be passed
+// back by ID to Ack once processed or to Nack to have it redelivered.
+type Message struct {
+	ID   uint64
+	Item interface{}
+	// Deliveries counts how many times the item has been retrieved
+	// since the queue was opened, including this one.
+	Deliveries int
+}
+
+type durableEntry struct {
+	item       interface{}
+	segment    *segment
+	inFlight   bool
+	deadline   time.Time
+	deliveries int
+}
+
+// idHeap orders ready items by id, which is the order they were put in.
+type idHeap []uint64
+
+func (h idHeap) Len() int            { return len(h) }
+func (h idHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h idHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *idHeap) Push(x interface{}) { *h = append(*h, x.(uint64)) }
+func (h *idHeap) Pop() interface{} {
+	old := *h
+	id := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return id
+}
+
+// DurableQueue is a FIFO queue that persists its items to an append-only
+// log in a directory so they survive a crash.  Items are delivered at
+// least once: each retrieved item must be acknowledged with Ack, and
+// items that are nacked, time out or were unacknowledged when the
+// process stopped are delivered again.  Every unacknowledged item is
+// also held in memory.
+//
+// The log is split into segment files.  A segment is deleted once it
+// and every segment before it contain only acknowledged items, and
+// Compact rewrites the remaining items so that old segments kept alive
+// by a few stragglers can be reclaimed.
+type DurableQueue struct {
+	dir      string
+	options  DurableOptions
+	lock     sync.Mutex
+	entries  map[uint64]*durableEntry
+	ready    idHeap
+	numReady int
+	nextID   uint64
+	segments []*segment
+	active   logFile
+	unsynced int
+	// failed is set once a write leaves the log in a state it cannot be
+	// safely appended to, and is returned by every later write.
+	failed   error
+	buf      []byte
+	changed  chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	disposed bool
+}
+
+// OpenDurableQueue opens the durable queue stored in dir, creating it
+// if it does not exist.  Every unacknowledged item in the log is made
+// available to be retrieved again.  Only one DurableQueue may have a
+// directory open at a time.
+func OpenDurableQueue(dir string, options DurableOptions) (*DurableQueue, error) {
+	if options.Codec == nil {
+		options.Codec = BytesCodec{}
+	}
+	if options.SyncEvery < 1 {
+		options.SyncEvery = 100
+	}
+	if options.SyncInterval <= 0 {
+		options.SyncInterval = time.Second
+	}
+	if options.SegmentSize <= 0 {
+		options.SegmentSize = 64 << 20
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	puts, nextID, err := replay(segments)
+	if err != nil {
+		return nil, err
+	}
+
+	dq := &DurableQueue{
+		dir:      dir,
+		options:  options,
+		entries:  make(map[uint64]*durableEntry, len(puts)),
+		ready:    make(idHeap, 0, len(puts)),
+		nextID:   nextID,
+		segments: segments,
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	for id, r := range puts {
+		item, err := options.Codec.Decode(r.payload)
+		if err != nil {
+			return nil, err
+		}
+		dq.entries[id] = &durableEntry{item: item, segment: r.segment}
+		dq.ready = append(dq.ready, id)
+	}
+	heap.Init(&dq.ready)
+	dq.numReady = len(dq.ready)
+
+	if err := dq.roll(); err != nil {
+		return nil, err
+	}
+
+	if options.RedeliveryTimeout > 0 || options.Sync == SyncInterval {
+		dq.wg.Add(1)
+		go dq.janitor()
+	}
+	return dq, nil
+}
+
+// Put adds the provided items to the queue.  Once Put returns, the items
+// survive a crash of the process, and, depending on the SyncPolicy, of
+// the machine.
+func (dq *DurableQueue) Put(items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	payloads := make([][]byte, 0, len(items))
+	for _, item := range items {
+		payload, err := dq.options.Codec.Encode(item)
+		if err != nil {
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return ErrDisposed
+	}
+
+	seg := dq.segments[len(dq.segments)-1]
+	dq.buf = dq.buf[:0]
+	ids := make([]uint64, 0, len(items))
+	for _, payload := range payloads {
+		ids = append(ids, dq.nextID)
+		dq.buf = appendRecord(dq.buf, recordPut, dq.nextID, payload)
+		dq.nextID++
+	}
+
+	if err := dq.write(len(items)); err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		dq.entries[id] = &durableEntry{item: items[i], segment: seg}
+		heap.Push(&dq.ready, id)
+	}
+	seg.live += len(ids)
+	dq.numReady += len(ids)
+	dq.notify()
+
+	return dq.maybeRoll()
+}
+
+// Get retrieves items from the queue.  If there are some items in the
+// queue, get will return a number UP TO the number passed in as a
+// parameter.  If no items are in the queue, this method will pause
+// until items are added to the queue.
+func (dq *DurableQueue) Get(number int64) ([]Message, error) {
+	return dq.PollContext(context.Background(), number, 0)
+}
+
+// Poll is like Get but returns ErrTimeout if no items are available
+// within the timeout.  A non-positive timeout will block until items
+// are added.
+func (dq *DurableQueue) Poll(number int64, timeout time.Duration) ([]Message, error) {
+	return dq.PollContext(context.Background(), number, timeout)
+}
+
+// PollContext is like Poll but also returns ctx.Err() if ctx is done
+// before items are available.
+func (dq *DurableQueue) PollContext(ctx context.Context, number int64, timeout time.Duration) ([]Message, error) {
+	if number < 1 {
+		return []Message{}, nil
+	}
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	dq.lock.Lock()
+	for {
+		if dq.disposed {
+			dq.lock.Unlock()
+			return nil, ErrDisposed
+		}
+
+		if messages := dq.take(number); len(messages) > 0 {
+			dq.lock.Unlock()
+			return messages, nil
+		}
+
+		changed := dq.changed
+		dq.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-timeoutC:
+			return nil, ErrTimeout
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		dq.lock.Lock()
+	}
+}
+
+func (dq *DurableQueue) take(number int64) []Message {
+	var messages []Message
+	for int64(len(messages)) < number && len(dq.ready) > 0 {
+		id := heap.Pop(&dq.ready).(uint64)
+		entry, ok := dq.entries[id]
+		if !ok || entry.inFlight {
+			// acknowledged while waiting to be redelivered
+			continue
+		}
+
+		entry.inFlight = true
+		entry.deliveries++
+		if dq.options.RedeliveryTimeout > 0 {
+			entry.deadline = time.Now().Add(dq.options.RedeliveryTimeout)
+		}
+		dq.numReady--
+		messages = append(messages, Message{ID: id, Item: entry.item, Deliveries: entry.deliveries})
+	}
+	return messages
+}
+
+// Ack acknowledges that the items with the given ids have been processed
+// so they are never delivered again.  Unknown ids, including those
+// already acknowledged, are ignored.
+func (dq *DurableQueue) Ack(ids ...uint64) error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return ErrDisposed
+	}
+
+	dq.buf = dq.buf[:0]
+	acked := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := dq.entries[id]; ok {
+			acked = append(acked, id)
+			dq.buf = appendRecord(dq.buf, recordAck, id, nil)
+		}
+	}
+	if len(acked) == 0 {
+		return nil
+	}
+
+	if err := dq.write(len(acked)); err != nil {
+		return err
+	}
+
+	for _, id := range acked {
+		entry := dq.entries[id]
+		// ids can repeat within a call
+		if entry == nil {
+			continue
+		}
+		if !entry.inFlight {
+			dq.numReady--
+		}
+		entry.segment.live--
+		delete(dq.entries, id)
+	}
+
+	if err := dq.maybeRoll(); err != nil {
+		return err
+	}
+	return dq.trim()
+}
+
+// Nack makes the retrieved items with the given ids available to be
+// retrieved again immediately, ahead of any items put after them.  Ids
+// that are not currently retrieved are ignored.
+func (dq *DurableQueue) Nack(ids ...uint64) error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return ErrDisposed
+	}
+
+	for _, id := range ids {
+		if entry, ok := dq.entries[id]; ok && entry.inFlight {
+			dq.requeue(id, entry)
+		}
+	}
+	dq.notify()
+	return nil
+}
+
+func (dq *DurableQueue) requeue(id uint64, entry *durableEntry) {
+	entry.inFlight = false
+	entry.deadline = time.Time{}
+	heap.Push(&dq.ready, id)
+	dq.numReady++
+}
+
+// Len returns the number of items available to be retrieved.
+func (dq *DurableQueue) Len() int64 {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return int64(dq.numReady)
+}
+
+// InFlight returns the number of items that have been retrieved but not
+// yet acknowledged.
+func (dq *DurableQueue) InFlight() int64 {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return int64(len(dq.entries) - dq.numReady)
+}
+
+// Sync flushes the log to stable storage regardless of the SyncPolicy.
+func (dq *DurableQueue) Sync() error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return ErrDisposed
+	}
+	return dq.sync()
+}
+
+// Compact rewrites every unacknowledged item into a new segment and
+// deletes all older segments, reclaiming the space taken by items that
+// have been acknowledged.
+func (dq *DurableQueue) Compact() error {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	if dq.disposed {
+		return ErrDisposed
+	}
+
+	ids := make([]uint64, 0, len(dq.entries))
+	for id := range dq.entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	dq.buf = appendRecord(dq.buf[:0], recordMark, dq.nextID, nil)
+	for _, id := range ids {
+		payload, err := dq.options.Codec.Encode(dq.entries[id].item)
+		if err != nil {
+			return err
+		}
+		dq.buf = appendRecord(dq.buf, recordPut, id, payload)
+	}
+
+	// every record must be durable before the segments holding the
+	// originals are deleted
+	if err := dq.sync(); err != nil {
+		return err
+	}
+
+	old := dq.segments
+	last := old[len(old)-1]
+	compacted := &segment{
+		seq:  last.seq + 1,
+		path: segmentPath(dq.dir, last.seq+1),
+		size: int64(len(dq.buf)),
+		live: len(ids),
+	}
+	if err := writeSegment(compacted.path, dq.buf); err != nil {
+		return err
+	}
+
+	// the compacted segment must not outlive a failed roll, as records
+	// still written to the old active segment would then come before
+	// the copies of the puts they refer to
+	dq.segments = append(old[:len(old):len(old)], compacted)
+	if err := dq.roll(); err != nil {
+		dq.segments = old
+		os.Remove(compacted.path)
+		return err
+	}
+	dq.segments = dq.segments[len(old):]
+	for _, entry := range dq.entries {
+		entry.segment = compacted
+	}
+
+	for _, seg := range old {
+		if err := os.Remove(seg.path); err != nil {
+			return err
+		}
+	}
+	syncDir(dq.dir)
+	return nil
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (dq *DurableQueue) Disposed() bool {
+	dq.lock.Lock()
+	defer dq.lock.Unlock()
+
+	return dq.disposed
+}
+
+// Dispose flushes and closes the log.  Any blocked Gets are released
+// with ErrDisposed and any subsequent calls return ErrDisposed.
+// Unacknowledged items remain in the log for the next time the queue is
+// opened.
+func (dq *DurableQueue) Dispose() error {
+	dq.lock.Lock()
+	if dq.disposed {
+		dq.lock.Unlock()
+		return nil
+	}
+
+	dq.disposed = true
+	close(dq.done)
+	dq.notify()
+
+	err := dq.active.Sync()
+	if cerr := dq.active.Close(); err == nil {
+		err = cerr
+	}
+	dq.entries = nil
+	dq.ready = nil
+	dq.lock.Unlock()
+
+	dq.wg.Wait()
+	return err
+}
+
+// notify wakes every blocked Get.  It must be called with the lock held.
+func (dq *DurableQueue) notify() {
+	close(dq.changed)
+	dq.changed = make(chan struct{})
+}
+
+// write appends the buffered records, which hold n records, to the
+// active segment and flushes them as the SyncPolicy requires.  A failed
+// write is cut back off the segment, as anything appended after a torn
+// record would be lost when the log is replayed.  If that fails too the
+// queue refuses any further writes.
+func (dq *DurableQueue) write(n int) error {
+	if dq.failed != nil {
+		return dq.failed
+	}
+
+	seg := dq.segments[len(dq.segments)-1]
+	if _, err := dq.active.Write(dq.buf); err != nil {
+		if terr := dq.active.Truncate(seg.size); terr != nil {
+			dq.failed = fmt.Errorf(`queue: log unwritable after failed write: %w`, terr)
+		}
+		return err
+	}
+	seg.size += int64(len(dq.buf))
+	dq.unsynced += n
+
+	switch dq.options.Sync {
+	case SyncAlways:
+		return dq.sync()
+	case SyncBatch:
+		if dq.unsynced >= dq.options.SyncEvery {
+			return dq.sync()
+		}
+	}
+	return nil
+}
+
+func (dq *DurableQueue) sync() error {
+	if dq.unsynced == 0 {
+		return nil
+	}
+	if err := dq.active.Sync(); err != nil {
+		return err
+	}
+	dq.unsynced = 0
+	return nil
+}
+
+func (dq *DurableQueue) maybeRoll() error {
+	if dq.segments[len(dq.segments)-1].size < dq.options.SegmentSize {
+		return nil
+	}
+	if err := dq.roll(); err != nil {
+		return err
+	}
+	return dq.trim()
+}
+
+// roll seals the active segment, if there is one, and starts a new one.
+// The new segment is opened before the old one is closed, so if it
+// cannot be created writes carry on in the old one.
+func (dq *DurableQueue) roll() error {
+	var seq uint64
+	if len(dq.segments) > 0 {
+		seq = dq.segments[len(dq.segments)-1].seq + 1
+	}
+
+	if dq.active != nil {
+		if err := dq.sync(); err != nil {
+			return err
+		}
+	}
+
+	seg := &segment{seq: seq, path: segmentPath(dq.dir, seq)}
+	f, err := os.OpenFile(seg.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// the mark must be durable before any older segment can be deleted
+	mark := appendRecord(nil, recordMark, dq.nextID, nil)
+	if _, err = f.Write(mark); err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(seg.path)
+		return err
+	}
+	seg.size = int64(len(mark))
+	syncDir(dq.dir)
+
+	if dq.active != nil {
+		// already synced, so nothing is lost if closing fails
+		dq.active.Close()
+	}
+	dq.active = f
+	dq.segments = append(dq.segments, seg)
+	return nil
+}
+
+// trim deletes the oldest sealed segments as long as every put in them
+// has been acknowledged.  Acks are only ever written after the put they
+// refer to, so once a prefix of the log holds no live puts none of its
+// records are needed.
+func (dq *DurableQueue) trim() error {
+	removed := 0
+	for removed < len(dq.segments)-1 && dq.segments[removed].live == 0 {
+		if err := os.Remove(dq.segments[removed].path); err != nil {
+			return err
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		dq.segments = append(dq.segments[:0], dq.segments[removed:]...)
+		syncDir(dq.dir)
+	}
+	return nil
+}
+
+// janitor redelivers items whose timeout has passed and flushes the log
+// with SyncInterval.
+func (dq *DurableQueue) janitor() {
+	defer dq.wg.Done()
+
+	var redeliverC, syncC <-chan time.Time
+	if timeout := dq.options.RedeliveryTimeout; timeout > 0 {
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+		redeliverC = ticker.C
+	}
+	if dq.options.Sync == SyncInterval {
+		ticker := time.NewTicker(dq.options.SyncInterval)
+		defer ticker.Stop()
+		syncC = ticker.C
+	}
+
+	for {
+		select {
+		case <-dq.done:
+			return
+		case now := <-redeliverC:
+			dq.lock.Lock()
+			if !dq.disposed {
+				dq.redeliver(now)
+			}
+			dq.lock.Unlock()
+		case <-syncC:
+			dq.lock.Lock()
+			if !dq.disposed {
+				dq.sync()
+			}
+			dq.lock.Unlock()
+		}
+	}
+}
+
+func (dq *DurableQueue) redeliver(now time.Time) {
+	requeued := false
+	for id, entry := range dq.entries {
+		if entry.inFlight && !entry.deadline.After(now) {
+			dq.requeue(id, entry)
+			requeued = true
+		}
+	}
+	if requeued {
+		dq.notify()
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrCorruptLog is returned when opening a durable queue whose log is
+// damaged anywhere other than a torn write at its very end.
+var ErrCorruptLog = errors.New(`queue: corrupt log`)
+
+// Codec converts the items of a DurableQueue to and from the bytes
+// written to its log.
+type Codec interface {
+	Encode(item interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+// BytesCodec stores items that are []byte as is.  It is the default
+// codec of a DurableQueue.
+type BytesCodec struct{}
+
+// Encode implements Codec.
+func (BytesCodec) Encode(item interface{}) ([]byte, error) {
+	b, ok := item.([]byte)
+	if !ok {
+		return nil, fmt.Errorf(`queue: BytesCodec cannot encode %T`, item)
+	}
+	return b, nil
+}
+
+// Decode implements Codec.
+func (BytesCodec) Decode(data []byte) (interface{}, error) {
+	return data, nil
+}
+
+// GobCodec stores items of any type with encoding/gob.  Concrete types
+// must be registered with gob.Register before use.
+type GobCodec struct{}
+
+// Encode implements Codec.
+func (GobCodec) Encode(item interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&item); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements Codec.
+func (GobCodec) Decode(data []byte) (interface{}, error) {
+	var item interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+const (
+	recordPut byte = iota + 1
+	recordAck
+	// recordMark starts every segment and holds the next id to be
+	// assigned, so ids keep increasing across restarts even once every
+	// put has been acknowledged and its segment deleted.
+	recordMark
+)
+
+// A record is framed as a little endian uint32 body length, the CRC-32
+// of the body and then the body: a type byte, a uint64 id and, for
+// puts, the encoded item.  For marks, the id is the next id to assign.
+const (
+	recordHeaderSize = 8
+	recordBodySize   = 9
+)
+
+func appendRecord(buf []byte, kind byte, id uint64, payload []byte) []byte {
+	start := len(buf)
+	buf = append(buf, make([]byte, recordHeaderSize+recordBodySize)...)
+	buf = append(buf, payload...)
+
+	body := buf[start+recordHeaderSize:]
+	body[0] = kind
+	binary.LittleEndian.PutUint64(body[1:], id)
+
+	header := buf[start:]
+	binary.LittleEndian.PutUint32(header, uint32(len(body)))
+	binary.LittleEndian.PutUint32(header[4:], crc32.ChecksumIEEE(body))
+	return buf
+}
+
+type record struct {
+	kind    byte
+	id      uint64
+	payload []byte
+}
+
+// readRecords returns the records of a segment and the offset just past
+// the last intact one.
+func readRecords(data []byte) ([]record, int64) {
+	var (
+		records []record
+		offset  int
+	)
+	for len(data)-offset >= recordHeaderSize {
+		size := int(binary.LittleEndian.Uint32(data[offset:]))
+		sum := binary.LittleEndian.Uint32(data[offset+4:])
+		start := offset + recordHeaderSize
+		if size < recordBodySize || size > len(data)-start {
+			break
+		}
+
+		body := data[start : start+size]
+		if crc32.ChecksumIEEE(body) != sum || body[0] < recordPut || body[0] > recordMark {
+			break
+		}
+
+		records = append(records, record{
+			kind:    body[0],
+			id:      binary.LittleEndian.Uint64(body[1:]),
+			payload: body[recordBodySize:],
+		})
+		offset = start + size
+	}
+	return records, int64(offset)
+}
+
+// logFile is the active segment as written by a DurableQueue.  It is
+// satisfied by *os.File.
+type logFile interface {
+	Write(b []byte) (int, error)
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// segment is one file of the log.  live counts the puts in it that are
+// not yet acknowledged; once every segment up to and including this one
+// has none, they can all be deleted.
+type segment struct {
+	seq  uint64
+	path string
+	size int64
+	live int
+}
+
+const segmentExt = `.log`
+
+func segmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf(`%020d%s`, seq, segmentExt))
+}
+
+// listSegments returns the segments in dir in log order, removing any
+// temporary files left by an interrupted compaction.
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, `.tmp`) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, &segment{seq: seq, path: filepath.Join(dir, name)})
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].seq < segments[j].seq
+	})
+	return segments, nil
+}
+
+// recovered is an unacknowledged put found while replaying the log.
+type recovered struct {
+	payload []byte
+	segment *segment
+}
+
+// replay reads every segment in order and returns the puts that were
+// never acknowledged.  A torn write at the end of the last segment, as
+// left by a crash, is truncated away.
+func replay(segments []*segment) (map[uint64]recovered, uint64, error) {
+	var (
+		puts   = make(map[uint64]recovered)
+		nextID uint64
+	)
+	for i, seg := range segments {
+		data, err := os.ReadFile(seg.path)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		records, end := readRecords(data)
+		if end < int64(len(data)) {
+			if i != len(segments)-1 {
+				return nil, 0, fmt.Errorf(`%w: %s at offset %d`, ErrCorruptLog, seg.path, end)
+			}
+			if err := os.Truncate(seg.path, end); err != nil {
+				return nil, 0, err
+			}
+		}
+		seg.size = end
+
+		for _, r := range records {
+			if r.kind == recordMark {
+				if r.id > nextID {
+					nextID = r.id
+				}
+				continue
+			}
+			if r.id >= nextID {
+				nextID = r.id + 1
+			}
+			switch r.kind {
+			case recordPut:
+				// compaction copies puts forward, so one may be seen twice
+				if _, ok := puts[r.id]; !ok {
+					puts[r.id] = recovered{payload: r.payload, segment: seg}
+				}
+			case recordAck:
+				delete(puts, r.id)
+			}
+		}
+	}
+
+	for _, r := range puts {
+		r.segment.live++
+	}
+	return puts, nextID, nil
+}
+
+// syncDir makes renames and removals within dir durable.  Not every
+// platform supports syncing a directory, so failures are ignored.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
+
+// writeSegment atomically creates a segment holding the given records.
+func writeSegment(path string, buf []byte) error {
+	tmp := path + `.tmp`
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(buf); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	syncDir(filepath.Dir(path))
+	return nil
+}
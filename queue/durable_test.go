@@ -0,0 +1,418 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openDurable(t *testing.T, dir string, options DurableOptions) *DurableQueue {
+	dq, err := OpenDurableQueue(dir, options)
+	require.NoError(t, err)
+	return dq
+}
+
+func messageItems(messages []Message) []interface{} {
+	items := make([]interface{}, 0, len(messages))
+	for _, m := range messages {
+		items = append(items, string(m.Item.([]byte)))
+	}
+	return items
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, `*`+segmentExt))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestDurablePutGetAck(t *testing.T) {
+	assert := assert.New(t)
+	dq := openDurable(t, t.TempDir(), DurableOptions{})
+	defer dq.Dispose()
+
+	assert.Nil(dq.Put([]byte(`a`), []byte(`b`), []byte(`c`)))
+	assert.Equal(int64(3), dq.Len())
+
+	messages, err := dq.Get(2)
+	assert.Nil(err)
+	assert.Equal([]interface{}{`a`, `b`}, messageItems(messages))
+	assert.Equal(1, messages[0].Deliveries)
+	assert.Equal(int64(1), dq.Len())
+	assert.Equal(int64(2), dq.InFlight())
+
+	assert.Nil(dq.Ack(messages[0].ID, messages[1].ID))
+	assert.Equal(int64(0), dq.InFlight())
+
+	_, err = dq.Poll(1, time.Millisecond)
+	assert.Nil(err)
+	_, err = dq.Poll(1, time.Millisecond)
+	assert.Equal(ErrTimeout, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = dq.PollContext(ctx, 1, 0)
+	assert.Equal(context.Canceled, err)
+
+	assert.Error(dq.Put(`not bytes`))
+}
+
+func TestDurableRecovery(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{})
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(dq.Put([]byte(strconv.Itoa(i))))
+	}
+	messages, _ := dq.Get(3)
+	dq.Ack(messages[1].ID)
+	assert.Nil(dq.Dispose())
+
+	// in flight items are redelivered in their original order
+	dq = openDurable(t, dir, DurableOptions{})
+	messages, err := dq.Get(10)
+	assert.Nil(err)
+	assert.Equal([]interface{}{`0`, `2`, `3`, `4`}, messageItems(messages))
+
+	// new ids continue after the recovered ones
+	dq.Put([]byte(`5`))
+	next, _ := dq.Get(1)
+	assert.True(next[0].ID > messages[3].ID)
+	dq.Dispose()
+}
+
+func TestDurableIDsSurviveRestart(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{SegmentSize: 1})
+	assert.Nil(dq.Put([]byte(`a`), []byte(`b`)))
+	messages, _ := dq.Get(2)
+	assert.Nil(dq.Ack(messages[0].ID, messages[1].ID))
+	assert.Len(segmentFiles(t, dir), 1)
+	assert.Nil(dq.Dispose())
+
+	// a stale ack from before the restart must not match a new item
+	dq = openDurable(t, dir, DurableOptions{})
+	assert.Nil(dq.Put([]byte(`c`)))
+	assert.Nil(dq.Ack(messages[0].ID, messages[1].ID))
+	assert.Equal(int64(1), dq.Len())
+	assert.Nil(dq.Compact())
+	assert.Nil(dq.Dispose())
+
+	dq = openDurable(t, dir, DurableOptions{})
+	defer dq.Dispose()
+	messages, err := dq.Poll(1, time.Second)
+	require.NoError(t, err)
+	assert.Nil(dq.Ack(messages[0].ID))
+	assert.Nil(dq.Compact())
+	assert.Nil(dq.Put([]byte(`d`)))
+	next, _ := dq.Get(1)
+	assert.True(next[0].ID > messages[0].ID)
+}
+
+func TestDurableNack(t *testing.T) {
+	assert := assert.New(t)
+	dq := openDurable(t, t.TempDir(), DurableOptions{})
+	defer dq.Dispose()
+
+	dq.Put([]byte(`a`), []byte(`b`))
+	messages, _ := dq.Get(1)
+	dq.Put([]byte(`c`))
+
+	assert.Nil(dq.Nack(messages[0].ID))
+	messages, _ = dq.Get(10)
+	assert.Equal([]interface{}{`a`, `b`, `c`}, messageItems(messages))
+	assert.Equal(2, messages[0].Deliveries)
+	assert.Equal(1, messages[1].Deliveries)
+}
+
+func TestDurableRedeliveryTimeout(t *testing.T) {
+	assert := assert.New(t)
+	dq := openDurable(t, t.TempDir(), DurableOptions{RedeliveryTimeout: 10 * time.Millisecond})
+	defer dq.Dispose()
+
+	dq.Put([]byte(`a`))
+	first, _ := dq.Get(1)
+
+	// blocks until the unacknowledged item comes back
+	second, err := dq.Poll(1, time.Second)
+	assert.Nil(err)
+	assert.Equal(first[0].ID, second[0].ID)
+	assert.Equal(2, second[0].Deliveries)
+
+	// a late ack of the first delivery still counts
+	assert.Nil(dq.Ack(first[0].ID))
+	assert.Equal(int64(0), dq.InFlight())
+	_, err = dq.Poll(1, 30*time.Millisecond)
+	assert.Equal(ErrTimeout, err)
+}
+
+func TestDurableSegmentTrim(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{SegmentSize: 64})
+	defer dq.Dispose()
+
+	for i := 0; i < 20; i++ {
+		assert.Nil(dq.Put([]byte(`item ` + strconv.Itoa(i))))
+	}
+	assert.True(len(segmentFiles(t, dir)) > 5)
+
+	messages, _ := dq.Get(20)
+	// acknowledging everything but the first item keeps every segment
+	for _, m := range messages[1:] {
+		assert.Nil(dq.Ack(m.ID))
+	}
+	assert.True(len(segmentFiles(t, dir)) > 5)
+
+	assert.Nil(dq.Ack(messages[0].ID))
+	assert.Len(segmentFiles(t, dir), 1)
+}
+
+func TestDurableFailedRoll(t *testing.T) {
+	assert := assert.New(t)
+	dir := filepath.Join(t.TempDir(), `queue`)
+	dq := openDurable(t, dir, DurableOptions{SegmentSize: 1})
+
+	// swap a file in for the directory, which stops new segments from
+	// being created even when running as root
+	moved := dir + `.moved`
+	require.NoError(t, os.Rename(dir, moved))
+	require.NoError(t, os.WriteFile(dir, nil, 0o644))
+
+	assert.NotNil(dq.Put([]byte(`a`)))
+	assert.NotNil(dq.Put([]byte(`b`)))
+	assert.NotNil(dq.Compact())
+
+	require.NoError(t, os.Remove(dir))
+	require.NoError(t, os.Rename(moved, dir))
+	assert.Nil(dq.Put([]byte(`c`)))
+	assert.Nil(dq.Dispose())
+
+	dq = openDurable(t, dir, DurableOptions{})
+	defer dq.Dispose()
+	messages, _ := dq.Get(10)
+	assert.Equal([]interface{}{`a`, `b`, `c`}, messageItems(messages))
+}
+
+func TestDurableCompact(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{SegmentSize: 64})
+
+	for i := 0; i < 20; i++ {
+		dq.Put([]byte(strconv.Itoa(i)))
+	}
+	messages, _ := dq.Get(20)
+	for _, m := range messages {
+		if m.ID%5 != 0 {
+			dq.Ack(m.ID)
+		}
+	}
+	assert.True(len(segmentFiles(t, dir)) > 2)
+
+	assert.Nil(dq.Compact())
+	assert.Len(segmentFiles(t, dir), 2)
+	assert.Equal(int64(4), dq.InFlight())
+	dq.Dispose()
+
+	dq = openDurable(t, dir, DurableOptions{})
+	defer dq.Dispose()
+	messages, _ = dq.Get(20)
+	assert.Equal([]interface{}{`0`, `5`, `10`, `15`}, messageItems(messages))
+}
+
+func TestDurableTornWrite(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{})
+	dq.Put([]byte(`a`), []byte(`b`))
+	dq.Dispose()
+
+	// simulate a crash part way through appending a record
+	files := segmentFiles(t, dir)
+	last := files[len(files)-1]
+	data, _ := os.ReadFile(last)
+	torn := appendRecord(nil, recordPut, 99, []byte(`c`))
+	assert.Nil(os.WriteFile(last, append(data, torn[:len(torn)-1]...), 0o644))
+
+	dq = openDurable(t, dir, DurableOptions{})
+	messages, _ := dq.Get(10)
+	assert.Equal([]interface{}{`a`, `b`}, messageItems(messages))
+	dq.Put([]byte(`c`))
+	dq.Dispose()
+
+	dq = openDurable(t, dir, DurableOptions{})
+	defer dq.Dispose()
+	messages, _ = dq.Get(10)
+	assert.Equal([]interface{}{`a`, `b`, `c`}, messageItems(messages))
+}
+
+// tornFile fails the next write after writing only half of it, as a
+// full disk or a process killed part way through a write would.
+type tornFile struct {
+	logFile
+	tear        bool
+	truncateErr error
+}
+
+func (f *tornFile) Write(b []byte) (int, error) {
+	if !f.tear {
+		return f.logFile.Write(b)
+	}
+	f.tear = false
+	n, _ := f.logFile.Write(b[:len(b)/2])
+	return n, io.ErrShortWrite
+}
+
+func (f *tornFile) Truncate(size int64) error {
+	if f.truncateErr != nil {
+		return f.truncateErr
+	}
+	return f.logFile.Truncate(size)
+}
+
+func TestDurableFailedWrite(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{})
+	assert.Nil(dq.Put([]byte(`a`)))
+
+	file := &tornFile{logFile: dq.active, tear: true}
+	dq.active = file
+	assert.Equal(io.ErrShortWrite, dq.Put([]byte(`b`)))
+	assert.Nil(dq.Put([]byte(`c`)))
+	messages, _ := dq.Get(10)
+	assert.Equal([]interface{}{`a`, `c`}, messageItems(messages))
+	assert.Nil(dq.Ack(messages[0].ID))
+
+	// recover without a clean shutdown
+	recovered := openDurable(t, dir, DurableOptions{})
+	defer recovered.Dispose()
+	messages, _ = recovered.Get(10)
+	assert.Equal([]interface{}{`c`}, messageItems(messages))
+	dq.Dispose()
+}
+
+func TestDurableFailedTruncate(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{})
+	assert.Nil(dq.Put([]byte(`a`)))
+
+	truncateErr := errors.New(`truncate failed`)
+	dq.active = &tornFile{logFile: dq.active, tear: true, truncateErr: truncateErr}
+	assert.Equal(io.ErrShortWrite, dq.Put([]byte(`b`)))
+
+	// nothing may be appended after the torn record
+	err := dq.Put([]byte(`c`))
+	assert.True(errors.Is(err, truncateErr))
+	messages, _ := dq.Get(10)
+	assert.True(errors.Is(dq.Ack(messages[0].ID), truncateErr))
+
+	recovered := openDurable(t, dir, DurableOptions{})
+	defer recovered.Dispose()
+	messages, _ = recovered.Get(10)
+	assert.Equal([]interface{}{`a`}, messageItems(messages))
+	dq.Dispose()
+}
+
+func TestDurableCorruptLog(t *testing.T) {
+	dir := t.TempDir()
+	dq := openDurable(t, dir, DurableOptions{})
+	dq.Put([]byte(`a`))
+	dq.Dispose()
+
+	// reopening starts a new segment, so the first is no longer the last
+	dq = openDurable(t, dir, DurableOptions{})
+	dq.Dispose()
+
+	files := segmentFiles(t, dir)
+	data, _ := os.ReadFile(files[0])
+	data[len(data)-1] ^= 0xff
+	os.WriteFile(files[0], data, 0o644)
+
+	_, err := OpenDurableQueue(dir, DurableOptions{})
+	assert.True(t, errors.Is(err, ErrCorruptLog))
+}
+
+type durableTestItem struct {
+	Name  string
+	Count int
+}
+
+func TestDurableGobCodec(t *testing.T) {
+	assert := assert.New(t)
+	gob.Register(durableTestItem{})
+	dir := t.TempDir()
+
+	dq := openDurable(t, dir, DurableOptions{Codec: GobCodec{}, Sync: SyncBatch, SyncEvery: 2})
+	assert.Nil(dq.Put(durableTestItem{Name: `a`, Count: 1}, `plain string`))
+	dq.Dispose()
+
+	dq = openDurable(t, dir, DurableOptions{Codec: GobCodec{}, Sync: SyncInterval, SyncInterval: time.Millisecond})
+	defer dq.Dispose()
+	messages, err := dq.Get(2)
+	assert.Nil(err)
+	assert.Equal(durableTestItem{Name: `a`, Count: 1}, messages[0].Item)
+	assert.Equal(`plain string`, messages[1].Item)
+}
+
+func TestDurableDispose(t *testing.T) {
+	assert := assert.New(t)
+	dq := openDurable(t, t.TempDir(), DurableOptions{RedeliveryTimeout: time.Second})
+
+	done := make(chan error)
+	go func() {
+		_, err := dq.Get(1)
+		done <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(dq.Dispose())
+	assert.Equal(ErrDisposed, <-done)
+	assert.True(dq.Disposed())
+	assert.Equal(ErrDisposed, dq.Put([]byte(`a`)))
+	assert.Equal(ErrDisposed, dq.Ack(1))
+	assert.Nil(dq.Dispose())
+}
+
+func BenchmarkDurablePut(b *testing.B) {
+	dq, err := OpenDurableQueue(b.TempDir(), DurableOptions{Sync: SyncBatch})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer dq.Dispose()
+	item := []byte(`benchmark item`)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		dq.Put(item)
+	}
+}
@@ -29,4 +29,16 @@ var (
 	// ErrEmptyQueue is returned when an non-applicable queue operation was called
 	// due to the queue's empty item state
 	ErrEmptyQueue = errors.New(`queue: empty queue`)
+
+	// ErrExceedsCapacity is returned when more items are put to a bounded
+	// queue at once than it can ever hold.
+	ErrExceedsCapacity = errors.New(`queue: items exceed capacity`)
+
+	// ErrNotQueued is returned when a handle is used after its item has
+	// left the queue.
+	ErrNotQueued = errors.New(`queue: item not queued`)
+
+	// ErrFlowFull is returned when items are put to a flow of a fair
+	// queue that is at its length limit.
+	ErrFlowFull = errors.New(`queue: flow full`)
 )
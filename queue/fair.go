@@ -0,0 +1,465 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// FairPolicy is the algorithm a FairQueue uses to choose which flow to
+// dequeue from next.
+type FairPolicy int
+
+const (
+	// DeficitRoundRobin visits the flows in turn, letting each dequeue
+	// items costing up to its weight times the quantum per visit plus
+	// whatever it had left over from the previous one.  It is O(1) per
+	// item but only fair over whole rounds.
+	DeficitRoundRobin FairPolicy = iota
+	// WeightedFair stamps each item with the virtual time at which it
+	// would finish if every flow were served at a rate proportional to
+	// its weight, and always dequeues the earliest.  It interleaves
+	// flows more smoothly at O(log flows) per item.  Virtual time is
+	// self-clocked: it is the stamp of the last item dequeued.
+	WeightedFair
+)
+
+// FlowConfig configures a flow of a FairQueue.
+type FlowConfig struct {
+	// Weight is the flow's share of the queue relative to the others.
+	// Non-positive weights are treated as 1.
+	Weight float64
+	// Limit is the most items the flow may hold.  Zero means no limit.
+	Limit int
+}
+
+// FairQueueOptions configures a FairQueue.
+type FairQueueOptions struct {
+	Policy FairPolicy
+	// Default is the configuration of flows without one of their own.
+	Default FlowConfig
+	// Quantum is the cost a flow of weight 1 may dequeue per round with
+	// DeficitRoundRobin.  Defaults to 1.
+	Quantum float64
+	// Cost returns the cost of an item, such as its size in bytes.
+	// Defaults to 1 for every item.
+	Cost func(item interface{}) float64
+}
+
+type fairFlow struct {
+	key        interface{}
+	items      items
+	tags       []float64
+	config     FlowConfig
+	deficit    float64
+	visited    bool
+	lastFinish float64
+	index      int
+	seq        uint64
+}
+
+func (f *fairFlow) pop() interface{} {
+	item := f.items[0]
+	f.items[0] = nil
+	f.items = f.items[1:]
+	if len(f.tags) > 0 {
+		f.tags = f.tags[1:]
+	}
+	return item
+}
+
+// fairFlows is a min-heap of flows by the finish tag of their first
+// item, breaking ties by when the flow became active.
+type fairFlows []*fairFlow
+
+func (h fairFlows) Len() int { return len(h) }
+func (h fairFlows) Less(i, j int) bool {
+	if h[i].tags[0] != h[j].tags[0] {
+		return h[i].tags[0] < h[j].tags[0]
+	}
+	return h[i].seq < h[j].seq
+}
+func (h fairFlows) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *fairFlows) Push(x interface{}) {
+	f := x.(*fairFlow)
+	f.index = len(*h)
+	*h = append(*h, f)
+}
+func (h *fairFlows) Pop() interface{} {
+	old := *h
+	f := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return f
+}
+
+// FairQueue is a threadsafe queue that keeps a separate FIFO sub-queue,
+// or flow, per key and dequeues across the flows in proportion to their
+// weights, so that one busy flow cannot starve the others.  Apart from
+// Put taking the flow's key, it follows the contract of Queue: Get
+// blocks while the queue is empty and Dispose releases blocked
+// goroutines with ErrDisposed.
+type FairQueue struct {
+	waiters  waiters
+	lock     sync.Mutex
+	options  FairQueueOptions
+	configs  map[interface{}]FlowConfig
+	flows    map[interface{}]*fairFlow
+	active   []*fairFlow
+	heap     fairFlows
+	virtual  float64
+	seq      uint64
+	length   int64
+	disposed bool
+}
+
+// NewFairQueue is a constructor for a new fair queue.
+func NewFairQueue(options FairQueueOptions) *FairQueue {
+	if options.Quantum <= 0 {
+		options.Quantum = 1
+	}
+	if options.Cost == nil {
+		options.Cost = func(interface{}) float64 { return 1 }
+	}
+
+	return &FairQueue{
+		options: options,
+		configs: make(map[interface{}]FlowConfig),
+		flows:   make(map[interface{}]*fairFlow),
+	}
+}
+
+// SetFlow configures the flow with the given key, replacing the
+// default configuration.  It applies to items already queued in the
+// flow too, but a lowered limit never drops items.
+func (fq *FairQueue) SetFlow(key interface{}, config FlowConfig) error {
+	fq.lock.Lock()
+	defer fq.lock.Unlock()
+
+	if fq.disposed {
+		return ErrDisposed
+	}
+
+	fq.configs[key] = config
+	if f, ok := fq.flows[key]; ok {
+		f.config = config
+	}
+	return nil
+}
+
+// Put adds the provided items to the end of the flow with the given
+// key.  ErrFlowFull is returned, and none of the items are added, if
+// they would take the flow past its limit.
+func (fq *FairQueue) Put(key interface{}, items ...interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	fq.lock.Lock()
+
+	if fq.disposed {
+		fq.lock.Unlock()
+		return ErrDisposed
+	}
+
+	f, ok := fq.flows[key]
+	if !ok {
+		config, ok := fq.configs[key]
+		if !ok {
+			config = fq.options.Default
+		}
+		f = &fairFlow{key: key, config: config}
+	}
+
+	if f.config.Limit > 0 && len(f.items)+len(items) > f.config.Limit {
+		fq.lock.Unlock()
+		return ErrFlowFull
+	}
+
+	wasEmpty := len(f.items) == 0
+	f.items = append(f.items, items...)
+	if fq.options.Policy == WeightedFair {
+		start := f.lastFinish
+		if fq.virtual > start {
+			start = fq.virtual
+		}
+		for _, item := range items {
+			start += fq.options.Cost(item) / f.weight()
+			f.tags = append(f.tags, start)
+		}
+		f.lastFinish = start
+	}
+
+	if wasEmpty {
+		fq.flows[key] = f
+		f.seq = fq.seq
+		fq.seq++
+		if fq.options.Policy == WeightedFair {
+			heap.Push(&fq.heap, f)
+		} else {
+			fq.active = append(fq.active, f)
+		}
+	}
+	fq.length += int64(len(items))
+
+	for {
+		sema := fq.waiters.get()
+		if sema == nil {
+			break
+		}
+		sema.response.Add(1)
+		select {
+		case sema.ready <- true:
+			sema.response.Wait()
+		default:
+			// This semaphore timed out.
+		}
+		if fq.length == 0 {
+			break
+		}
+	}
+
+	fq.lock.Unlock()
+	return nil
+}
+
+func (f *fairFlow) weight() float64 {
+	if f.config.Weight <= 0 {
+		return 1
+	}
+	return f.config.Weight
+}
+
+// get dequeues up to number items according to the policy.  It must be
+// called with the lock held.
+func (fq *FairQueue) get(number int64) []interface{} {
+	if number > fq.length {
+		number = fq.length
+	}
+	result := make([]interface{}, 0, number)
+
+	for int64(len(result)) < number {
+		var f *fairFlow
+		if fq.options.Policy == WeightedFair {
+			f = fq.heap[0]
+			fq.virtual = f.tags[0]
+			result = append(result, f.pop())
+			if len(f.items) > 0 {
+				heap.Fix(&fq.heap, 0)
+			} else {
+				heap.Pop(&fq.heap)
+			}
+		} else {
+			f = fq.active[0]
+			if !f.visited {
+				f.deficit += fq.options.Quantum * f.weight()
+				f.visited = true
+			}
+
+			if cost := fq.options.Cost(f.items[0]); f.deficit >= cost {
+				f.deficit -= cost
+				result = append(result, f.pop())
+			} else {
+				// the flow's turn is over, move it to the back
+				f.visited = false
+				copy(fq.active, fq.active[1:])
+				fq.active[len(fq.active)-1] = f
+				continue
+			}
+
+			if len(f.items) == 0 {
+				f.deficit = 0
+				f.visited = false
+				copy(fq.active, fq.active[1:])
+				fq.active[len(fq.active)-1] = nil
+				fq.active = fq.active[:len(fq.active)-1]
+			}
+		}
+
+		if len(f.items) == 0 {
+			delete(fq.flows, f.key)
+		}
+	}
+
+	fq.length -= int64(len(result))
+	return result
+}
+
+// Get retrieves items from the queue.  If there are some items in the
+// queue, get will return a number UP TO the number passed in as a
+// parameter.  If no items are in the queue, this method will pause
+// until items are added to the queue.
+func (fq *FairQueue) Get(number int64) ([]interface{}, error) {
+	return fq.PollContext(context.Background(), number, 0)
+}
+
+// GetContext is like Get but also returns ctx.Err() if ctx is done
+// before any items are added to the queue.
+func (fq *FairQueue) GetContext(ctx context.Context, number int64) ([]interface{}, error) {
+	return fq.PollContext(ctx, number, 0)
+}
+
+// Poll retrieves items from the queue.  If there are some items in the queue,
+// Poll will return a number UP TO the number passed in as a parameter.  If no
+// items are in the queue, this method will pause until items are added to the
+// queue or the provided timeout is reached.  A non-positive timeout will block
+// until items are added.  If a timeout occurs, ErrTimeout is returned.
+func (fq *FairQueue) Poll(number int64, timeout time.Duration) ([]interface{}, error) {
+	return fq.PollContext(context.Background(), number, timeout)
+}
+
+// PollContext is like Poll but also returns ctx.Err() if ctx is done
+// before items are added to the queue or the timeout is reached.
+func (fq *FairQueue) PollContext(ctx context.Context, number int64, timeout time.Duration) ([]interface{}, error) {
+	if number < 1 {
+		return []interface{}{}, nil
+	}
+
+	fq.lock.Lock()
+
+	if fq.disposed {
+		fq.lock.Unlock()
+		return nil, ErrDisposed
+	}
+
+	if fq.length > 0 {
+		items := fq.get(number)
+		fq.lock.Unlock()
+		return items, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		fq.lock.Unlock()
+		return nil, err
+	}
+
+	sema := newSema()
+	fq.waiters.put(sema)
+	fq.lock.Unlock()
+
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	var err error
+	select {
+	case <-sema.ready:
+		// we are now inside the put's lock
+		if fq.disposed {
+			return nil, ErrDisposed
+		}
+		items := fq.get(number)
+		sema.response.Done()
+		return items, nil
+	case <-timeoutC:
+		err = ErrTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	// cleanup the sema that was added to waiters
+	select {
+	case sema.ready <- true:
+		// we called this before Put() could
+		// Remove sema from waiters.
+		fq.lock.Lock()
+		fq.waiters.remove(sema)
+		fq.lock.Unlock()
+	default:
+		// Put() got it already, we need to call Done() so Put() can move on
+		sema.response.Done()
+	}
+	return nil, err
+}
+
+// Len returns the number of items in the queue across every flow.
+func (fq *FairQueue) Len() int64 {
+	fq.lock.Lock()
+	defer fq.lock.Unlock()
+
+	return fq.length
+}
+
+// FlowLen returns the number of items in the flow with the given key.
+func (fq *FairQueue) FlowLen(key interface{}) int {
+	fq.lock.Lock()
+	defer fq.lock.Unlock()
+
+	if f, ok := fq.flows[key]; ok {
+		return len(f.items)
+	}
+	return 0
+}
+
+// Empty returns a bool indicating if this queue is empty.
+func (fq *FairQueue) Empty() bool {
+	return fq.Len() == 0
+}
+
+// Disposed returns a bool indicating if this queue
+// has had disposed called on it.
+func (fq *FairQueue) Disposed() bool {
+	fq.lock.Lock()
+	defer fq.lock.Unlock()
+
+	return fq.disposed
+}
+
+// Dispose will dispose of this queue and returns the items disposed,
+// flow by flow.  Any subsequent calls to Get or Put will return an
+// error.
+func (fq *FairQueue) Dispose() []interface{} {
+	fq.lock.Lock()
+	defer fq.lock.Unlock()
+
+	fq.disposed = true
+	for _, waiter := range fq.waiters {
+		waiter.response.Add(1)
+		select {
+		case waiter.ready <- true:
+			// release Poll immediately
+		default:
+			// ignore if it's a timeout or in the get
+		}
+	}
+
+	flows := fq.active
+	if fq.options.Policy == WeightedFair {
+		flows = fq.heap
+	}
+	disposedItems := make([]interface{}, 0, fq.length)
+	for _, f := range flows {
+		disposedItems = append(disposedItems, f.items...)
+	}
+
+	fq.flows = nil
+	fq.active = nil
+	fq.heap = nil
+	fq.waiters = nil
+	fq.length = 0
+	return disposedItems
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func putFlow(fq *FairQueue, key string, n int) {
+	for i := 0; i < n; i++ {
+		fq.Put(key, fmt.Sprintf(`%s%d`, key, i))
+	}
+}
+
+func flowOrder(items []interface{}) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteByte(item.(string)[0])
+	}
+	return b.String()
+}
+
+func TestFairRoundRobin(t *testing.T) {
+	for _, policy := range []FairPolicy{DeficitRoundRobin, WeightedFair} {
+		fq := NewFairQueue(FairQueueOptions{Policy: policy})
+		putFlow(fq, `a`, 6)
+		putFlow(fq, `b`, 2)
+		putFlow(fq, `c`, 1)
+		assert.Equal(t, int64(9), fq.Len())
+		assert.Equal(t, 6, fq.FlowLen(`a`))
+
+		items, err := fq.Get(9)
+		assert.Nil(t, err)
+		assert.Equal(t, `abcabaaaa`, flowOrder(items))
+		// items within a flow stay in order
+		assert.Equal(t, `a0`, items[0])
+		assert.Equal(t, `a1`, items[3])
+		assert.True(t, fq.Empty())
+		assert.Equal(t, 0, fq.FlowLen(`a`))
+	}
+}
+
+func TestFairWeights(t *testing.T) {
+	for _, policy := range []FairPolicy{DeficitRoundRobin, WeightedFair} {
+		fq := NewFairQueue(FairQueueOptions{Policy: policy})
+		fq.SetFlow(`a`, FlowConfig{Weight: 3})
+		putFlow(fq, `a`, 100)
+		putFlow(fq, `b`, 100)
+
+		items, err := fq.Get(40)
+		assert.Nil(t, err)
+		order := flowOrder(items)
+		assert.Equal(t, 30, strings.Count(order, `a`), order)
+		assert.Equal(t, 10, strings.Count(order, `b`), order)
+	}
+}
+
+func TestFairCost(t *testing.T) {
+	size := func(item interface{}) float64 {
+		return float64(len(item.(string)))
+	}
+
+	for _, policy := range []FairPolicy{DeficitRoundRobin, WeightedFair} {
+		fq := NewFairQueue(FairQueueOptions{Policy: policy, Quantum: 8, Cost: size})
+		for i := 0; i < 10; i++ {
+			fq.Put(`big`, `bbbbbbbb`)
+			fq.Put(`small`, `ss`, `ss`, `ss`, `ss`)
+		}
+
+		// equal bytes rather than equal items
+		items, _ := fq.Get(15)
+		order := flowOrder(items)
+		assert.Equal(t, 3, strings.Count(order, `b`), order)
+		assert.Equal(t, 12, strings.Count(order, `s`), order)
+	}
+}
+
+func TestFairLateFlow(t *testing.T) {
+	fq := NewFairQueue(FairQueueOptions{Policy: WeightedFair})
+	putFlow(fq, `a`, 10)
+	fq.Get(5)
+
+	// a flow arriving later gets no credit for the time it was idle
+	putFlow(fq, `b`, 10)
+	items, _ := fq.Get(6)
+	assert.Equal(t, `ababab`, flowOrder(items))
+}
+
+func TestFairLimit(t *testing.T) {
+	fq := NewFairQueue(FairQueueOptions{Default: FlowConfig{Limit: 2}})
+	fq.SetFlow(`big`, FlowConfig{Limit: 5})
+
+	assert.Nil(t, fq.Put(`a`, 1, 2))
+	assert.Equal(t, ErrFlowFull, fq.Put(`a`, 3))
+	assert.Equal(t, ErrFlowFull, fq.Put(`b`, 1, 2, 3))
+	assert.Equal(t, 0, fq.FlowLen(`b`))
+	assert.Nil(t, fq.Put(`big`, 1, 2, 3, 4, 5))
+
+	fq.Get(2)
+	assert.Nil(t, fq.Put(`a`, 3))
+}
+
+func TestFairBlocking(t *testing.T) {
+	fq := NewFairQueue(FairQueueOptions{})
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		fq.Put(`a`, 1)
+	}()
+	items, err := fq.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{1}, items)
+
+	_, err = fq.Poll(1, time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fq.GetContext(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, fq.waiters, 0)
+}
+
+func TestFairDispose(t *testing.T) {
+	fq := NewFairQueue(FairQueueOptions{})
+
+	done := make(chan error)
+	go func() {
+		_, err := fq.Get(1)
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	assert.Len(t, fq.Dispose(), 0)
+	assert.Equal(t, ErrDisposed, <-done)
+
+	assert.True(t, fq.Disposed())
+	assert.Equal(t, ErrDisposed, fq.Put(`a`, 1))
+	assert.Equal(t, ErrDisposed, fq.SetFlow(`a`, FlowConfig{}))
+	_, err := fq.Get(1)
+	assert.Equal(t, ErrDisposed, err)
+
+	fq = NewFairQueue(FairQueueOptions{Policy: WeightedFair})
+	fq.Put(`a`, 1, 2)
+	fq.Put(`b`, 3)
+	assert.ElementsMatch(t, []interface{}{1, 2, 3}, fq.Dispose())
+}
+
+func BenchmarkFairQueue(b *testing.B) {
+	for _, policy := range []FairPolicy{DeficitRoundRobin, WeightedFair} {
+		b.Run(fmt.Sprint(policy), func(b *testing.B) {
+			fq := NewFairQueue(FairQueueOptions{Policy: policy})
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				fq.Put(i%16, i)
+				fq.Get(1)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+)
+
+// Direction determines which end of an IndexedPriorityQueue items are
+// retrieved from.
+type Direction int
+
+const (
+	// Ascending retrieves the least item first, as with PriorityQueue.
+	Ascending Direction = iota
+	// Descending retrieves the greatest item first.
+	Descending
+)
+
+// Handle refers to an item put to an IndexedPriorityQueue for as long
+// as the item is queued, allowing it to be reprioritized or removed.
+type Handle struct {
+	pq    *IndexedPriorityQueue
+	item  Item
+	index int
+}
+
+// Item returns the item this handle refers to.
+func (h *Handle) Item() Item {
+	h.pq.lock.Lock()
+	defer h.pq.lock.Unlock()
+
+	return h.item
+}
+
+// Queued returns a bool indicating if the item is still in the queue.
+func (h *Handle) Queued() bool {
+	h.pq.lock.Lock()
+	defer h.pq.lock.Unlock()
+
+	return h.index >= 0
+}
+
+// Update replaces the item with the provided one and moves it to its
+// new position in the queue in O(log n).  ErrNotQueued is returned if
+// the item has already been retrieved or removed.
+func (h *Handle) Update(item Item) error {
+	pq := h.pq
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return ErrDisposed
+	}
+	if h.index < 0 {
+		return ErrNotQueued
+	}
+
+	h.item = item
+	pq.items.fix(h.index, pq.direction)
+	return nil
+}
+
+// Remove takes the item out of the queue in O(log n).  ErrNotQueued is
+// returned if the item has already been retrieved or removed.
+func (h *Handle) Remove() error {
+	pq := h.pq
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return ErrDisposed
+	}
+	if h.index < 0 {
+		return ErrNotQueued
+	}
+
+	pq.items.remove(h.index, pq.direction)
+	return nil
+}
+
+// indexedItems is a binary heap of handles, each of which tracks its
+// own position so it can be found again without a search.
+type indexedItems []*Handle
+
+func (items indexedItems) less(i, j int, direction Direction) bool {
+	c := items[i].item.Compare(items[j].item)
+	if direction == Descending {
+		return c > 0
+	}
+	return c < 0
+}
+
+func (items indexedItems) swap(i, j int) {
+	items[i], items[j] = items[j], items[i]
+	items[i].index = i
+	items[j].index = j
+}
+
+func (items indexedItems) up(index int, direction Direction) {
+	for index > 0 {
+		parent := (index - 1) / 2
+		if !items.less(index, parent, direction) {
+			break
+		}
+		items.swap(index, parent)
+		index = parent
+	}
+}
+
+func (items indexedItems) down(index int, direction Direction) bool {
+	start := index
+	for {
+		child := 2*index + 1
+		if child >= len(items) {
+			break
+		}
+		if right := child + 1; right < len(items) && items.less(right, child, direction) {
+			child = right
+		}
+		if !items.less(child, index, direction) {
+			break
+		}
+		items.swap(index, child)
+		index = child
+	}
+	return index > start
+}
+
+func (items indexedItems) fix(index int, direction Direction) {
+	if !items.down(index, direction) {
+		items.up(index, direction)
+	}
+}
+
+func (items *indexedItems) push(h *Handle, direction Direction) {
+	h.index = len(*items)
+	*items = append(*items, h)
+	items.up(h.index, direction)
+}
+
+func (items *indexedItems) remove(index int, direction Direction) *Handle {
+	last := len(*items) - 1
+	if index != last {
+		items.swap(index, last)
+	}
+
+	h := (*items)[last]
+	(*items)[last], *items = nil, (*items)[:last]
+	h.index = -1
+
+	if index != last {
+		items.fix(index, direction)
+	}
+	return h
+}
+
+func (items *indexedItems) get(number int, direction Direction) []Item {
+	returnItems := make([]Item, 0, number)
+	for i := 0; i < number && len(*items) > 0; i++ {
+		returnItems = append(returnItems, items.remove(0, direction).item)
+	}
+	return returnItems
+}
+
+// IndexedPriorityQueue is a priority queue whose Put returns a Handle
+// through which the item can later be updated or removed.  Unlike
+// PriorityQueue it does not deduplicate items, and it can be ordered in
+// either direction.
+type IndexedPriorityQueue struct {
+	waiters   waiters
+	items     indexedItems
+	lock      sync.Mutex
+	disposed  bool
+	direction Direction
+}
+
+// NewIndexedPriorityQueue is the constructor for an indexed priority
+// queue retrieving items in the given direction.
+func NewIndexedPriorityQueue(hint int, direction Direction) *IndexedPriorityQueue {
+	return &IndexedPriorityQueue{
+		items:     make(indexedItems, 0, hint),
+		direction: direction,
+	}
+}
+
+// Put adds an item to the queue and returns a handle to it.
+func (pq *IndexedPriorityQueue) Put(item Item) (*Handle, error) {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if pq.disposed {
+		return nil, ErrDisposed
+	}
+
+	h := &Handle{pq: pq, item: item}
+	pq.items.push(h, pq.direction)
+
+	for {
+		sema := pq.waiters.get()
+		if sema == nil {
+			break
+		}
+		sema.response.Add(1)
+		select {
+		case sema.ready <- true:
+			sema.response.Wait()
+		default:
+			// This semaphore was abandoned.
+		}
+		if len(pq.items) == 0 {
+			break
+		}
+	}
+
+	return h, nil
+}
+
+// Get retrieves items from the queue.  If the queue is empty,
+// this call blocks until the next item is added to the queue.  This
+// will attempt to retrieve number of items.
+func (pq *IndexedPriorityQueue) Get(number int) ([]Item, error) {
+	return pq.GetWithContext(context.Background(), number)
+}
+
+// GetWithContext is like Get but also returns ctx.Err() if ctx is done
+// before an item is added to the queue.
+func (pq *IndexedPriorityQueue) GetWithContext(ctx context.Context, number int) ([]Item, error) {
+	if number < 1 {
+		return nil, nil
+	}
+
+	pq.lock.Lock()
+
+	if pq.disposed {
+		pq.lock.Unlock()
+		return nil, ErrDisposed
+	}
+
+	if len(pq.items) > 0 {
+		items := pq.items.get(number, pq.direction)
+		pq.lock.Unlock()
+		return items, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		pq.lock.Unlock()
+		return nil, err
+	}
+
+	sema := newSema()
+	pq.waiters.put(sema)
+	pq.lock.Unlock()
+
+	select {
+	case <-sema.ready:
+		// we are now inside the put's lock
+		if pq.disposed {
+			return nil, ErrDisposed
+		}
+		items := pq.items.get(number, pq.direction)
+		sema.response.Done()
+		return items, nil
+	case <-ctx.Done():
+	}
+
+	select {
+	case sema.ready <- true:
+		// we got here before Put() could, so remove the sema from waiters
+		pq.lock.Lock()
+		pq.waiters.remove(sema)
+		pq.lock.Unlock()
+	default:
+		// Put() got it already, we need to call Done() so Put() can move on
+		sema.response.Done()
+	}
+	return nil, ctx.Err()
+}
+
+// Peek will look at the next item without removing it from the queue.
+func (pq *IndexedPriorityQueue) Peek() Item {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	if len(pq.items) > 0 {
+		return pq.items[0].item
+	}
+	return nil
+}
+
+// Empty returns a bool indicating if there are any items left
+// in the queue.
+func (pq *IndexedPriorityQueue) Empty() bool {
+	return pq.Len() == 0
+}
+
+// Len returns a number indicating how many items are in the queue.
+func (pq *IndexedPriorityQueue) Len() int {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	return len(pq.items)
+}
+
+// Disposed returns a bool indicating if this queue has been disposed.
+func (pq *IndexedPriorityQueue) Disposed() bool {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	return pq.disposed
+}
+
+// Dispose will prevent any further reads/writes to this queue, release
+// any blocked Gets with ErrDisposed and free available resources.
+func (pq *IndexedPriorityQueue) Dispose() {
+	pq.lock.Lock()
+	defer pq.lock.Unlock()
+
+	pq.disposed = true
+	for _, waiter := range pq.waiters {
+		waiter.response.Add(1)
+		select {
+		case waiter.ready <- true:
+		default:
+		}
+	}
+
+	for _, h := range pq.items {
+		h.index = -1
+	}
+	pq.items = nil
+	pq.waiters = nil
+}
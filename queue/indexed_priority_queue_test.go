@@ -0,0 +1,191 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexedPriorityGet(t *testing.T) {
+	q := NewIndexedPriorityQueue(4, Ascending)
+	for _, i := range []int{5, 2, 8, 1, 2} {
+		q.Put(mockItem(i))
+	}
+	assert.Equal(t, 5, q.Len())
+	assert.Equal(t, mockItem(1), q.Peek())
+
+	result, err := q.Get(3)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(1), mockItem(2), mockItem(2)}, result)
+
+	result, err = q.Get(10)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(5), mockItem(8)}, result)
+	assert.True(t, q.Empty())
+	assert.Nil(t, q.Peek())
+}
+
+func TestIndexedPriorityDescending(t *testing.T) {
+	q := NewIndexedPriorityQueue(4, Descending)
+	for _, i := range []int{5, 2, 8, 1} {
+		q.Put(mockItem(i))
+	}
+
+	result, err := q.Get(4)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(8), mockItem(5), mockItem(2), mockItem(1)}, result)
+}
+
+func TestIndexedPriorityUpdate(t *testing.T) {
+	q := NewIndexedPriorityQueue(4, Ascending)
+	q.Put(mockItem(1))
+	h, _ := q.Put(mockItem(5))
+	q.Put(mockItem(3))
+
+	assert.Nil(t, h.Update(mockItem(0)))
+	assert.Equal(t, mockItem(0), h.Item())
+	assert.Equal(t, mockItem(0), q.Peek())
+
+	assert.Nil(t, h.Update(mockItem(10)))
+	result, _ := q.Get(3)
+	assert.Equal(t, []Item{mockItem(1), mockItem(3), mockItem(10)}, result)
+
+	assert.False(t, h.Queued())
+	assert.Equal(t, ErrNotQueued, h.Update(mockItem(1)))
+	assert.Equal(t, ErrNotQueued, h.Remove())
+}
+
+func TestIndexedPriorityRemove(t *testing.T) {
+	q := NewIndexedPriorityQueue(4, Ascending)
+	handles := make([]*Handle, 0, 5)
+	for i := 0; i < 5; i++ {
+		h, err := q.Put(mockItem(i))
+		assert.Nil(t, err)
+		handles = append(handles, h)
+	}
+
+	assert.Nil(t, handles[0].Remove())
+	assert.Nil(t, handles[3].Remove())
+	assert.Equal(t, ErrNotQueued, handles[3].Remove())
+	assert.Equal(t, 3, q.Len())
+
+	result, _ := q.Get(5)
+	assert.Equal(t, []Item{mockItem(1), mockItem(2), mockItem(4)}, result)
+}
+
+func TestIndexedPriorityRandomized(t *testing.T) {
+	q := NewIndexedPriorityQueue(0, Ascending)
+	r := rand.New(rand.NewSource(42))
+	live := make(map[*Handle]int)
+
+	for i := 0; i < 2000; i++ {
+		switch op := r.Intn(3); {
+		case op == 0 || len(live) == 0:
+			v := r.Intn(1000)
+			h, _ := q.Put(mockItem(v))
+			live[h] = v
+		default:
+			for h := range live {
+				if op == 1 {
+					v := r.Intn(1000)
+					assert.Nil(t, h.Update(mockItem(v)))
+					live[h] = v
+				} else {
+					assert.Nil(t, h.Remove())
+					delete(live, h)
+				}
+				break
+			}
+		}
+	}
+
+	expected := make([]int, 0, len(live))
+	for _, v := range live {
+		expected = append(expected, v)
+	}
+	sort.Ints(expected)
+
+	result, _ := q.Get(len(live))
+	actual := make([]int, 0, len(result))
+	for _, item := range result {
+		actual = append(actual, int(item.(mockItem)))
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestIndexedPriorityGetWithContext(t *testing.T) {
+	q := NewIndexedPriorityQueue(1, Ascending)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.Put(mockItem(1))
+	}()
+	result, err := q.GetWithContext(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(1)}, result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = q.GetWithContext(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, q.waiters, 0)
+
+	// Put must not block on the abandoned waiter
+	q.Put(mockItem(2))
+	result, err = q.Get(1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Item{mockItem(2)}, result)
+}
+
+func TestIndexedPriorityDispose(t *testing.T) {
+	q := NewIndexedPriorityQueue(1, Ascending)
+
+	done := make(chan error)
+	go func() {
+		_, err := q.Get(1)
+		done <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	q.Dispose()
+	assert.Equal(t, ErrDisposed, <-done)
+	assert.True(t, q.Disposed())
+
+	_, err := q.Put(mockItem(1))
+	assert.Equal(t, ErrDisposed, err)
+	_, err = q.Get(1)
+	assert.Equal(t, ErrDisposed, err)
+}
+
+func BenchmarkIndexedPriorityUpdate(b *testing.B) {
+	q := NewIndexedPriorityQueue(1000, Ascending)
+	handles := make([]*Handle, 1000)
+	for i := range handles {
+		handles[i], _ = q.Put(mockItem(i))
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		handles[i%len(handles)].Update(mockItem(i % 997))
+	}
+}
@@ -34,6 +34,24 @@ the capability to release blocked threads.  This works for both puts
 and gets, either will return an error if they are blocked and the buffer
 is disposed.  This could serve as a signal to kill a goroutine.  All threadsafety
 is acheived using CAS operations, making this buffer pretty quick.
+PutMany and GetMany claim a run of slots with a single CAS, and
+SPSCRingBuffer drops the CAS entirely when there is exactly one producer
+and one consumer.
+
+BoundedQueue sits between the two: it holds at most a fixed number of
+items like the ring buffer, but blocks on a lock rather than spinning and
+accepts batches of items.  Blocked producers and consumers are each served
+in the order they arrived.
+
+DurableQueue persists its items to a segmented append-only log so that work
+survives a crash, delivering each item at least once until it is acknowledged.
+
+Deque is a growable double-ended queue with the same blocking and dispose
+semantics as Queue, while WorkStealingDeque is a lock-free Chase-Lev deque for
+schedulers where one owner works at the bottom and others steal from the top.
+
+FairQueue keeps a FIFO flow per key and dequeues across flows by deficit round
+robin or weighted fair queuing, so that no single producer can starve others.
 
 Benchmarks:
 BenchmarkPriorityQueue-8	 		2000000	       782 ns/op
@@ -52,6 +70,7 @@ TODO: Unify the types of queue to the same interface.
 package queue
 
 import (
+	"context"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -209,12 +228,24 @@ func (q *Queue) Get(number int64) ([]interface{}, error) {
 	return q.Poll(number, 0)
 }
 
+// GetContext is like Get but also returns ctx.Err() if ctx is done
+// before any items are added to the queue.
+func (q *Queue) GetContext(ctx context.Context, number int64) ([]interface{}, error) {
+	return q.PollContext(ctx, number, 0)
+}
+
 // Poll retrieves items from the queue.  If there are some items in the queue,
 // Poll will return a number UP TO the number passed in as a parameter.  If no
 // items are in the queue, this method will pause until items are added to the
 // queue or the provided timeout is reached.  A non-positive timeout will block
 // until items are added.  If a timeout occurs, ErrTimeout is returned.
 func (q *Queue) Poll(number int64, timeout time.Duration) ([]interface{}, error) {
+	return q.PollContext(context.Background(), number, timeout)
+}
+
+// PollContext is like Poll but also returns ctx.Err() if ctx is done
+// before items are added to the queue or the timeout is reached.
+func (q *Queue) PollContext(ctx context.Context, number int64, timeout time.Duration) ([]interface{}, error) {
 	if number < 1 {
 		// thanks again go
 		return []interface{}{}, nil
@@ -230,14 +261,23 @@ func (q *Queue) Poll(number int64, timeout time.Duration) ([]interface{}, error)
 	var items []interface{}
 
 	if len(q.items) == 0 {
+		if err := ctx.Err(); err != nil {
+			q.lock.Unlock()
+			return nil, err
+		}
+
 		sema := newSema()
 		q.waiters.put(sema)
 		q.lock.Unlock()
 
 		var timeoutC <-chan time.Time
 		if timeout > 0 {
-			timeoutC = time.After(timeout)
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			timeoutC = timer.C
 		}
+
+		var err error
 		select {
 		case <-sema.ready:
 			// we are now inside the put's lock
@@ -248,20 +288,24 @@ func (q *Queue) Poll(number int64, timeout time.Duration) ([]interface{}, error)
 			sema.response.Done()
 			return items, nil
 		case <-timeoutC:
-			// cleanup the sema that was added to waiters
-			select {
-			case sema.ready <- true:
-				// we called this before Put() could
-				// Remove sema from waiters.
-				q.lock.Lock()
-				q.waiters.remove(sema)
-				q.lock.Unlock()
-			default:
-				// Put() got it already, we need to call Done() so Put() can move on
-				sema.response.Done()
-			}
-			return nil, ErrTimeout
+			err = ErrTimeout
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+
+		// cleanup the sema that was added to waiters
+		select {
+		case sema.ready <- true:
+			// we called this before Put() could
+			// Remove sema from waiters.
+			q.lock.Lock()
+			q.waiters.remove(sema)
+			q.lock.Unlock()
+		default:
+			// Put() got it already, we need to call Done() so Put() can move on
+			sema.response.Done()
 		}
+		return nil, err
 	}
 
 	items = q.items.get(number)
@@ -17,6 +17,7 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -137,6 +138,107 @@ func TestPollNoMemoryLeak(t *testing.T) {
 	}
 }
 
+func TestGetContext(t *testing.T) {
+	q := New(10)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		q.Put(`test`)
+	}()
+
+	result, err := q.GetContext(context.Background(), 1)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, []interface{}{`test`}, result)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+
+	_, err = q.GetContext(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, q.waiters, 0)
+
+	// a done context is ignored while items are available
+	q.Put(`1`)
+	result, err = q.GetContext(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`1`}, result)
+
+	_, err = q.GetContext(ctx, 1)
+	assert.Equal(t, context.Canceled, err)
+	assert.Len(t, q.waiters, 0)
+}
+
+func TestPollContext(t *testing.T) {
+	q := New(10)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := q.PollContext(ctx, 1, time.Minute)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	_, err = q.PollContext(context.Background(), 1, time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+	assert.Len(t, q.waiters, 0)
+
+	// Put must still reach the queue after waiters have been abandoned
+	q.Put(`test`)
+	result, err := q.PollContext(context.Background(), 1, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{`test`}, result)
+}
+
+func TestGetContextRace(t *testing.T) {
+	q := New(0)
+
+	var wg sync.WaitGroup
+	var received int64
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				result, err := q.GetContext(ctx, 1)
+				cancel()
+				if err == ErrDisposed {
+					return
+				}
+				atomic.AddInt64(&received, int64(len(result)))
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		q.Put(i)
+	}
+	for q.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	q.Dispose()
+	wg.Wait()
+
+	assert.Equal(t, int64(1000), atomic.LoadInt64(&received))
+}
+
+func TestGetContextDispose(t *testing.T) {
+	q := New(0)
+
+	done := make(chan error)
+	go func() {
+		_, err := q.GetContext(context.Background(), 1)
+		done <- err
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	q.Dispose()
+	assert.Equal(t, ErrDisposed, <-done)
+}
+
 func TestAddEmptyPut(t *testing.T) {
 	q := New(10)
 
@@ -17,6 +17,7 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"runtime"
 	"sync/atomic"
 	"time"
@@ -73,7 +74,14 @@ func (rb *RingBuffer) init(size uint64) {
 // call will block until an item is added to the queue or Dispose is called
 // on the queue.  An error will be returned if the queue is disposed.
 func (rb *RingBuffer) Put(item interface{}) error {
-	_, err := rb.put(item, false)
+	_, err := rb.put(context.Background(), item, false)
+	return err
+}
+
+// PutContext is like Put but also returns ctx.Err() if ctx is done
+// before there is space in the queue.
+func (rb *RingBuffer) PutContext(ctx context.Context, item interface{}) error {
+	_, err := rb.put(ctx, item, false)
 	return err
 }
 
@@ -81,11 +89,14 @@ func (rb *RingBuffer) Put(item interface{}) error {
 // is full, this call will return false.  An error will be returned if the
 // queue is disposed.
 func (rb *RingBuffer) Offer(item interface{}) (bool, error) {
-	return rb.put(item, true)
+	return rb.put(context.Background(), item, true)
 }
 
-func (rb *RingBuffer) put(item interface{}, offer bool) (bool, error) {
-	var n *node
+func (rb *RingBuffer) put(ctx context.Context, item interface{}, offer bool) (bool, error) {
+	var (
+		n    *node
+		done = ctx.Done()
+	)
 	pos := atomic.LoadUint64(&rb.queue)
 L:
 	for {
@@ -110,6 +121,12 @@ L:
 			return false, nil
 		}
 
+		select {
+		case <-done:
+			return false, ctx.Err()
+		default:
+		}
+
 		runtime.Gosched() // free up the cpu before the next iteration
 	}
 
@@ -126,16 +143,27 @@ func (rb *RingBuffer) Get() (interface{}, error) {
 	return rb.Poll(0)
 }
 
+// GetContext is like Get but also returns ctx.Err() if ctx is done
+// before an item is added to the queue.
+func (rb *RingBuffer) GetContext(ctx context.Context) (interface{}, error) {
+	return rb.poll(ctx, 0)
+}
+
 // Poll will return the next item in the queue.  This call will block
 // if the queue is empty.  This call will unblock when an item is added
 // to the queue, Dispose is called on the queue, or the timeout is reached. An
 // error will be returned if the queue is disposed or a timeout occurs. A
 // non-positive timeout will block indefinitely.
 func (rb *RingBuffer) Poll(timeout time.Duration) (interface{}, error) {
+	return rb.poll(context.Background(), timeout)
+}
+
+func (rb *RingBuffer) poll(ctx context.Context, timeout time.Duration) (interface{}, error) {
 	var (
 		n     *node
 		pos   = atomic.LoadUint64(&rb.dequeue)
 		start time.Time
+		done  = ctx.Done()
 	)
 	if timeout > 0 {
 		start = time.Now()
@@ -159,6 +187,12 @@ L:
 			pos = atomic.LoadUint64(&rb.dequeue)
 		}
 
+		select {
+		case <-done:
+			return nil, ctx.Err()
+		default:
+		}
+
 		if timeout > 0 && time.Since(start) >= timeout {
 			return nil, ErrTimeout
 		}
@@ -171,6 +205,89 @@ L:
 	return data, nil
 }
 
+// PutMany adds the provided items to the queue in order.  Rather than
+// contending for each slot, it claims as many consecutive free slots as
+// it can in a single step, so a batch from one producer is only
+// interleaved with other producers' items where the queue was full.  If
+// the queue is full, this call will block until all of the items are
+// added or Dispose is called on the queue.  An error will be returned if
+// the queue is disposed, in which case only some items may have been
+// added.
+func (rb *RingBuffer) PutMany(items ...interface{}) error {
+	for len(items) > 0 {
+		pos, claimed, err := rb.claim(&rb.queue, uint64(len(items)), 0)
+		if err != nil {
+			return err
+		}
+
+		for i := uint64(0); i < claimed; i++ {
+			n := &rb.nodes[(pos+i)&rb.mask]
+			n.data = items[i]
+			atomic.StoreUint64(&n.position, pos+i+1)
+		}
+		items = items[claimed:]
+	}
+	return nil
+}
+
+// GetMany fills items with the next items in the queue and returns how
+// many were retrieved, claiming as many consecutive items as are ready
+// in a single step.  This call will block if the queue is empty until at
+// least one item is added or Dispose is called on the queue.  An error
+// will be returned if the queue is disposed.
+func (rb *RingBuffer) GetMany(items []interface{}) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	pos, claimed, err := rb.claim(&rb.dequeue, uint64(len(items)), 1)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := uint64(0); i < claimed; i++ {
+		n := &rb.nodes[(pos+i)&rb.mask]
+		items[i] = n.data
+		n.data = nil
+		atomic.StoreUint64(&n.position, pos+i+rb.mask+1)
+	}
+	return int(claimed), nil
+}
+
+// claim advances cursor, either the put or the get position, past up
+// to max consecutive slots whose sequence is offset past their own
+// position, meaning they are free to be written (offset 0) or ready to
+// be read (offset 1).  It blocks until at least one slot can be claimed.
+func (rb *RingBuffer) claim(cursor *uint64, max, offset uint64) (uint64, uint64, error) {
+	if max > rb.mask+1 {
+		max = rb.mask + 1
+	}
+
+	for {
+		if atomic.LoadUint64(&rb.disposed) == 1 {
+			return 0, 0, ErrDisposed
+		}
+
+		pos := atomic.LoadUint64(cursor)
+		available := uint64(0)
+		for available < max {
+			n := &rb.nodes[(pos+available)&rb.mask]
+			if atomic.LoadUint64(&n.position) != pos+available+offset {
+				break
+			}
+			available++
+		}
+
+		if available > 0 && atomic.CompareAndSwapUint64(cursor, pos, pos+available) {
+			return pos, available, nil
+		}
+
+		if available == 0 {
+			runtime.Gosched() // free up the cpu before the next iteration
+		}
+	}
+}
+
 // Len returns the number of items in the queue.
 func (rb *RingBuffer) Len() uint64 {
 	return atomic.LoadUint64(&rb.queue) - atomic.LoadUint64(&rb.dequeue)
@@ -17,6 +17,7 @@ limitations under the License.
 package queue
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -300,6 +301,105 @@ func TestDisposeOnPut(t *testing.T) {
 	assert.True(t, rb.IsDisposed())
 }
 
+func TestRingPutManyGetMany(t *testing.T) {
+	rb := NewRingBuffer(4)
+
+	assert.Nil(t, rb.PutMany(1, 2, 3))
+	assert.Equal(t, uint64(3), rb.Len())
+
+	buf := make([]interface{}, 2)
+	n, err := rb.GetMany(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []interface{}{1, 2}, buf)
+
+	// wraps around and interoperates with single item calls
+	assert.Nil(t, rb.PutMany(4, 5, 6))
+	result, err := rb.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, result)
+
+	buf = make([]interface{}, 10)
+	n, err = rb.GetMany(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{4, 5, 6}, buf[:n])
+
+	n, err = rb.GetMany(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestRingManyConcurrent(t *testing.T) {
+	rb := NewRingBuffer(16)
+	const producers, perProducer = 4, 2000
+
+	for p := 0; p < producers; p++ {
+		go func(p int) {
+			batch := make([]interface{}, 0, 7)
+			for i := 0; i < perProducer; i++ {
+				batch = append(batch, [2]int{p, i})
+				if len(batch) == cap(batch) || i == perProducer-1 {
+					assert.Nil(t, rb.PutMany(batch...))
+					batch = batch[:0]
+				}
+			}
+		}(p)
+	}
+
+	var (
+		lock     sync.Mutex
+		counts   = make([]int, producers)
+		received int64
+		wg       sync.WaitGroup
+	)
+	wg.Add(3)
+	for c := 0; c < 3; c++ {
+		go func() {
+			defer wg.Done()
+			buf := make([]interface{}, 5)
+			for {
+				n, err := rb.GetMany(buf)
+				if err == ErrDisposed {
+					return
+				}
+				lock.Lock()
+				for _, item := range buf[:n] {
+					counts[item.([2]int)[0]]++
+				}
+				lock.Unlock()
+				atomic.AddInt64(&received, int64(n))
+			}
+		}()
+	}
+
+	for atomic.LoadInt64(&received) < producers*perProducer {
+		time.Sleep(time.Millisecond)
+	}
+	rb.Dispose()
+	wg.Wait()
+
+	for p := 0; p < producers; p++ {
+		assert.Equal(t, perProducer, counts[p])
+	}
+}
+
+func TestRingPutManyDispose(t *testing.T) {
+	rb := NewRingBuffer(2)
+
+	done := make(chan error)
+	go func() {
+		done <- rb.PutMany(1, 2, 3)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, uint64(2), rb.Len())
+	rb.Dispose()
+	assert.Equal(t, ErrDisposed, <-done)
+
+	_, err := rb.GetMany(make([]interface{}, 1))
+	assert.Equal(t, ErrDisposed, err)
+}
+
 func BenchmarkRBLifeCycle(b *testing.B) {
 	rb := NewRingBuffer(64)
 
@@ -402,3 +502,53 @@ func BenchmarkRBAllocation(b *testing.B) {
 		NewRingBuffer(1024)
 	}
 }
+
+func TestRingGetContext(t *testing.T) {
+	rb := NewRingBuffer(2)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		rb.Put(1)
+	}()
+
+	result, err := rb.GetContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err = rb.GetContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// the abandoned get must not have claimed a slot
+	assert.Nil(t, rb.Put(2))
+	result, err = rb.Get()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, result)
+	assert.Equal(t, uint64(0), rb.Len())
+}
+
+func TestRingPutContext(t *testing.T) {
+	rb := NewRingBuffer(2)
+	assert.Nil(t, rb.PutContext(context.Background(), 1))
+	assert.Nil(t, rb.PutContext(context.Background(), 2))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	assert.Equal(t, context.Canceled, rb.PutContext(ctx, 3))
+	assert.Equal(t, uint64(2), rb.Len())
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		rb.Get()
+	}()
+	assert.Nil(t, rb.PutContext(context.Background(), 3))
+
+	rb.Dispose()
+	assert.Equal(t, ErrDisposed, rb.PutContext(context.Background(), 4))
+	_, err := rb.GetContext(context.Background())
+	assert.Equal(t, ErrDisposed, err)
+}
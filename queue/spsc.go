@@ -0,0 +1,278 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// SPSCRingBuffer is a bounded buffer for exactly one producer goroutine
+// and one consumer goroutine.  With only one goroutine on each side no
+// CAS operations are needed: the producer alone advances the tail and
+// the consumer alone advances the head, and each side caches the other's
+// cursor so it only touches the shared cache line when it appears to be
+// full or empty.  Calling Put methods from more than one goroutine, or
+// Get methods from more than one goroutine, corrupts the buffer; use
+// RingBuffer for that.  Like RingBuffer, a put on full or get on empty
+// call will block until Dispose is called on the buffer.
+type SPSCRingBuffer struct {
+	_padding0 [8]uint64
+	head      uint64
+	_padding1 [8]uint64
+	tail      uint64
+	_padding2 [8]uint64
+	// cachedHead is only accessed by the producer
+	cachedHead uint64
+	_padding3  [8]uint64
+	// cachedTail is only accessed by the consumer
+	cachedTail uint64
+	_padding4  [8]uint64
+	mask       uint64
+	disposed   uint64
+	items      []interface{}
+}
+
+// NewSPSCRingBuffer will allocate, initialize, and return a single
+// producer, single consumer ring buffer with the specified size.
+func NewSPSCRingBuffer(size uint64) *SPSCRingBuffer {
+	size = roundUp(size)
+	return &SPSCRingBuffer{
+		mask:  size - 1,
+		items: make([]interface{}, size),
+	}
+}
+
+// Put adds the provided item to the queue.  If the queue is full, this
+// call will block until an item is retrieved from the queue or Dispose
+// is called on the queue.  An error will be returned if the queue is
+// disposed.
+func (rb *SPSCRingBuffer) Put(item interface{}) error {
+	_, err := rb.put(context.Background(), item, false)
+	return err
+}
+
+// PutContext is like Put but also returns ctx.Err() if ctx is done
+// before there is space in the queue.
+func (rb *SPSCRingBuffer) PutContext(ctx context.Context, item interface{}) error {
+	_, err := rb.put(ctx, item, false)
+	return err
+}
+
+// Offer adds the provided item to the queue if there is space.  If the
+// queue is full, this call will return false.  An error will be returned
+// if the queue is disposed.
+func (rb *SPSCRingBuffer) Offer(item interface{}) (bool, error) {
+	return rb.put(context.Background(), item, true)
+}
+
+func (rb *SPSCRingBuffer) put(ctx context.Context, item interface{}, offer bool) (bool, error) {
+	free, err := rb.waitFree(ctx, offer)
+	if err != nil || free == 0 {
+		return false, err
+	}
+
+	tail := atomic.LoadUint64(&rb.tail)
+	rb.items[tail&rb.mask] = item
+	atomic.StoreUint64(&rb.tail, tail+1)
+	return true, nil
+}
+
+// PutMany adds the provided items to the queue in order, publishing as
+// many as there is space for at once.  If the queue is full, this call
+// will block until all of the items are added or Dispose is called on
+// the queue.  An error will be returned if the queue is disposed, in
+// which case only some items may have been added.
+func (rb *SPSCRingBuffer) PutMany(items ...interface{}) error {
+	for len(items) > 0 {
+		free, err := rb.waitFree(context.Background(), false)
+		if err != nil {
+			return err
+		}
+		if free > uint64(len(items)) {
+			free = uint64(len(items))
+		}
+
+		tail := atomic.LoadUint64(&rb.tail)
+		for i := uint64(0); i < free; i++ {
+			rb.items[(tail+i)&rb.mask] = items[i]
+		}
+		atomic.StoreUint64(&rb.tail, tail+free)
+		items = items[free:]
+	}
+	return nil
+}
+
+// waitFree returns the number of free slots, blocking until there is at
+// least one unless offer is set.
+func (rb *SPSCRingBuffer) waitFree(ctx context.Context, offer bool) (uint64, error) {
+	var (
+		tail = atomic.LoadUint64(&rb.tail)
+		size = rb.mask + 1
+		done = ctx.Done()
+	)
+	for {
+		if atomic.LoadUint64(&rb.disposed) == 1 {
+			return 0, ErrDisposed
+		}
+
+		if free := size - (tail - rb.cachedHead); free > 0 {
+			return free, nil
+		}
+		rb.cachedHead = atomic.LoadUint64(&rb.head)
+		if free := size - (tail - rb.cachedHead); free > 0 {
+			return free, nil
+		}
+
+		if offer {
+			return 0, nil
+		}
+
+		select {
+		case <-done:
+			return 0, ctx.Err()
+		default:
+		}
+
+		runtime.Gosched() // free up the cpu before the next iteration
+	}
+}
+
+// Get will return the next item in the queue.  This call will block
+// if the queue is empty.  This call will unblock when an item is added
+// to the queue or Dispose is called on the queue.  An error will be returned
+// if the queue is disposed.
+func (rb *SPSCRingBuffer) Get() (interface{}, error) {
+	return rb.get(context.Background(), 0)
+}
+
+// GetContext is like Get but also returns ctx.Err() if ctx is done
+// before an item is added to the queue.
+func (rb *SPSCRingBuffer) GetContext(ctx context.Context) (interface{}, error) {
+	return rb.get(ctx, 0)
+}
+
+// Poll will return the next item in the queue.  This call will block
+// if the queue is empty.  This call will unblock when an item is added
+// to the queue, Dispose is called on the queue, or the timeout is reached. An
+// error will be returned if the queue is disposed or a timeout occurs. A
+// non-positive timeout will block indefinitely.
+func (rb *SPSCRingBuffer) Poll(timeout time.Duration) (interface{}, error) {
+	return rb.get(context.Background(), timeout)
+}
+
+func (rb *SPSCRingBuffer) get(ctx context.Context, timeout time.Duration) (interface{}, error) {
+	if _, err := rb.waitReady(ctx, timeout); err != nil {
+		return nil, err
+	}
+
+	head := atomic.LoadUint64(&rb.head)
+	item := rb.items[head&rb.mask]
+	rb.items[head&rb.mask] = nil
+	atomic.StoreUint64(&rb.head, head+1)
+	return item, nil
+}
+
+// GetMany fills items with the next items in the queue and returns how
+// many were retrieved.  This call will block if the queue is empty until
+// at least one item is added or Dispose is called on the queue.  An
+// error will be returned if the queue is disposed.
+func (rb *SPSCRingBuffer) GetMany(items []interface{}) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	ready, err := rb.waitReady(context.Background(), 0)
+	if err != nil {
+		return 0, err
+	}
+	if ready > uint64(len(items)) {
+		ready = uint64(len(items))
+	}
+
+	head := atomic.LoadUint64(&rb.head)
+	for i := uint64(0); i < ready; i++ {
+		n := (head + i) & rb.mask
+		items[i] = rb.items[n]
+		rb.items[n] = nil
+	}
+	atomic.StoreUint64(&rb.head, head+ready)
+	return int(ready), nil
+}
+
+// waitReady returns the number of items ready to be retrieved, blocking
+// until there is at least one.
+func (rb *SPSCRingBuffer) waitReady(ctx context.Context, timeout time.Duration) (uint64, error) {
+	var (
+		head  = atomic.LoadUint64(&rb.head)
+		done  = ctx.Done()
+		start time.Time
+	)
+	if timeout > 0 {
+		start = time.Now()
+	}
+	for {
+		if atomic.LoadUint64(&rb.disposed) == 1 {
+			return 0, ErrDisposed
+		}
+
+		if ready := rb.cachedTail - head; ready > 0 {
+			return ready, nil
+		}
+		rb.cachedTail = atomic.LoadUint64(&rb.tail)
+		if ready := rb.cachedTail - head; ready > 0 {
+			return ready, nil
+		}
+
+		select {
+		case <-done:
+			return 0, ctx.Err()
+		default:
+		}
+
+		if timeout > 0 && time.Since(start) >= timeout {
+			return 0, ErrTimeout
+		}
+
+		runtime.Gosched() // free up the cpu before the next iteration
+	}
+}
+
+// Len returns the number of items in the queue.
+func (rb *SPSCRingBuffer) Len() uint64 {
+	return atomic.LoadUint64(&rb.tail) - atomic.LoadUint64(&rb.head)
+}
+
+// Cap returns the capacity of this ring buffer.
+func (rb *SPSCRingBuffer) Cap() uint64 {
+	return uint64(len(rb.items))
+}
+
+// Dispose will dispose of this queue and free any blocked threads
+// in the Put and/or Get methods.  Calling those methods on a disposed
+// queue will return an error.
+func (rb *SPSCRingBuffer) Dispose() {
+	atomic.CompareAndSwapUint64(&rb.disposed, 0, 1)
+}
+
+// IsDisposed will return a bool indicating if this queue has been
+// disposed.
+func (rb *SPSCRingBuffer) IsDisposed() bool {
+	return atomic.LoadUint64(&rb.disposed) == 1
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPSCPutGet(t *testing.T) {
+	rb := NewSPSCRingBuffer(3)
+	assert.Equal(t, uint64(4), rb.Cap())
+
+	// wrap around the buffer a few times
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, rb.Put(i))
+		assert.Nil(t, rb.Put(i+1))
+		assert.Equal(t, uint64(2), rb.Len())
+
+		result, err := rb.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, i, result)
+		result, err = rb.Get()
+		assert.Nil(t, err)
+		assert.Equal(t, i+1, result)
+	}
+	assert.Equal(t, uint64(0), rb.Len())
+}
+
+func TestSPSCOffer(t *testing.T) {
+	rb := NewSPSCRingBuffer(2)
+
+	for i := 0; i < 2; i++ {
+		ok, err := rb.Offer(i)
+		assert.True(t, ok)
+		assert.Nil(t, err)
+	}
+	ok, err := rb.Offer(2)
+	assert.False(t, ok)
+	assert.Nil(t, err)
+
+	rb.Get()
+	ok, _ = rb.Offer(2)
+	assert.True(t, ok)
+}
+
+func TestSPSCPollAndContext(t *testing.T) {
+	rb := NewSPSCRingBuffer(1)
+
+	_, err := rb.Poll(time.Millisecond)
+	assert.Equal(t, ErrTimeout, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = rb.GetContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	rb.Put(1)
+	assert.Equal(t, context.DeadlineExceeded, rb.PutContext(ctx, 2))
+}
+
+func TestSPSCMany(t *testing.T) {
+	rb := NewSPSCRingBuffer(8)
+	const total = 10000
+
+	go func() {
+		batch := make([]interface{}, 0, 13)
+		for i := 0; i < total; i++ {
+			batch = append(batch, i)
+			if len(batch) == cap(batch) || i == total-1 {
+				assert.Nil(t, rb.PutMany(batch...))
+				batch = batch[:0]
+			}
+		}
+	}()
+
+	buf := make([]interface{}, 5)
+	for expected := 0; expected < total; {
+		n, err := rb.GetMany(buf)
+		if !assert.Nil(t, err) {
+			return
+		}
+		for _, item := range buf[:n] {
+			if !assert.Equal(t, expected, item) {
+				return
+			}
+			expected++
+		}
+	}
+}
+
+func TestSPSCDispose(t *testing.T) {
+	rb := NewSPSCRingBuffer(1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, err := rb.Get()
+		assert.Equal(t, ErrDisposed, err)
+	}()
+
+	full := NewSPSCRingBuffer(1)
+	full.Put(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, ErrDisposed, full.PutMany(2, 3))
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	rb.Dispose()
+	full.Dispose()
+	wg.Wait()
+
+	assert.True(t, rb.IsDisposed())
+	assert.Equal(t, ErrDisposed, rb.Put(1))
+	_, err := rb.GetMany(make([]interface{}, 1))
+	assert.Equal(t, ErrDisposed, err)
+}
+
+const benchmarkBatch = 64
+
+func BenchmarkSPSCLifeCycle(b *testing.B) {
+	rb := NewSPSCRingBuffer(1024)
+	done := make(chan struct{})
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			rb.Get()
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb.Put(i)
+	}
+	<-done
+}
+
+func BenchmarkSPSCLifeCycleMany(b *testing.B) {
+	rb := NewSPSCRingBuffer(1024)
+	done := make(chan struct{})
+
+	go func() {
+		buf := make([]interface{}, benchmarkBatch)
+		for received := 0; received < b.N; {
+			n, _ := rb.GetMany(buf)
+			received += n
+		}
+		close(done)
+	}()
+
+	batch := make([]interface{}, benchmarkBatch)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += benchmarkBatch {
+		if b.N-i < benchmarkBatch {
+			batch = batch[:b.N-i]
+		}
+		rb.PutMany(batch...)
+	}
+	<-done
+}
+
+func BenchmarkRBSingleProducerLifeCycle(b *testing.B) {
+	rb := NewRingBuffer(1024)
+	done := make(chan struct{})
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			rb.Get()
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb.Put(i)
+	}
+	<-done
+}
+
+func BenchmarkRBSingleProducerLifeCycleMany(b *testing.B) {
+	rb := NewRingBuffer(1024)
+	done := make(chan struct{})
+
+	go func() {
+		buf := make([]interface{}, benchmarkBatch)
+		for received := 0; received < b.N; {
+			n, _ := rb.GetMany(buf)
+			received += n
+		}
+		close(done)
+	}()
+
+	batch := make([]interface{}, benchmarkBatch)
+	b.ResetTimer()
+	for i := 0; i < b.N; i += benchmarkBatch {
+		if b.N-i < benchmarkBatch {
+			batch = batch[:b.N-i]
+		}
+		rb.PutMany(batch...)
+	}
+	<-done
+}
+
+func BenchmarkChannelSingleProducerLifeCycle(b *testing.B) {
+	ch := make(chan interface{}, 1024)
+	done := make(chan struct{})
+
+	go func() {
+		for i := 0; i < b.N; i++ {
+			<-ch
+		}
+		close(done)
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ch <- i
+	}
+	<-done
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// wsSlot boxes an item so that slots can be read and written with
+// atomic pointer operations.
+type wsSlot struct {
+	item interface{}
+}
+
+// wsArray is the circular array backing a WorkStealingDeque.  It is
+// never resized in place; the owner replaces it with a larger copy.
+type wsArray struct {
+	mask  int64
+	slots []unsafe.Pointer
+}
+
+func newWSArray(size int64) *wsArray {
+	return &wsArray{mask: size - 1, slots: make([]unsafe.Pointer, size)}
+}
+
+func (a *wsArray) get(i int64) interface{} {
+	slot := (*wsSlot)(atomic.LoadPointer(&a.slots[i&a.mask]))
+	if slot == nil {
+		// only seen by a thief whose CAS is bound to fail
+		return nil
+	}
+	return slot.item
+}
+
+func (a *wsArray) put(i int64, item interface{}) {
+	atomic.StorePointer(&a.slots[i&a.mask], unsafe.Pointer(&wsSlot{item: item}))
+}
+
+func (a *wsArray) grow(bottom, top int64) *wsArray {
+	grown := newWSArray(2 * (a.mask + 1))
+	for i := top; i < bottom; i++ {
+		grown.slots[i&grown.mask] = atomic.LoadPointer(&a.slots[i&a.mask])
+	}
+	return grown
+}
+
+// WorkStealingDeque is the Chase-Lev work-stealing deque described in
+// "Dynamic Circular Work-Stealing Deque" (Chase and Lev, 2005).  A
+// single owner goroutine pushes and pops items at the bottom, LIFO,
+// without contention, while any number of other goroutines steal from
+// the top, FIFO, with a single CAS.  The backing array grows as needed
+// and never shrinks.  Calling Push or Pop from any goroutine other than
+// the owner corrupts the deque.
+type WorkStealingDeque struct {
+	_padding0 [8]uint64
+	top       int64
+	_padding1 [8]uint64
+	bottom    int64
+	_padding2 [8]uint64
+	array     unsafe.Pointer // *wsArray
+}
+
+// NewWorkStealingDeque returns an empty work-stealing deque with room
+// for hint items before it has to grow.
+func NewWorkStealingDeque(hint uint64) *WorkStealingDeque {
+	if hint < 2 {
+		hint = 2
+	}
+
+	return &WorkStealingDeque{
+		array: unsafe.Pointer(newWSArray(int64(roundUp(hint)))),
+	}
+}
+
+func (d *WorkStealingDeque) loadArray() *wsArray {
+	return (*wsArray)(atomic.LoadPointer(&d.array))
+}
+
+// Push adds an item to the bottom of the deque.  It may only be called
+// by the owner.
+func (d *WorkStealingDeque) Push(item interface{}) {
+	b := atomic.LoadInt64(&d.bottom)
+	t := atomic.LoadInt64(&d.top)
+	a := d.loadArray()
+
+	if b-t > a.mask {
+		a = a.grow(b, t)
+		atomic.StorePointer(&d.array, unsafe.Pointer(a))
+	}
+
+	a.put(b, item)
+	atomic.StoreInt64(&d.bottom, b+1)
+}
+
+// Pop removes and returns the item at the bottom of the deque, the one
+// most recently pushed.  It returns false if the deque is empty or the
+// last item was stolen concurrently.  It may only be called by the
+// owner.
+func (d *WorkStealingDeque) Pop() (interface{}, bool) {
+	b := atomic.LoadInt64(&d.bottom) - 1
+	a := d.loadArray()
+	atomic.StoreInt64(&d.bottom, b)
+	t := atomic.LoadInt64(&d.top)
+
+	if t > b {
+		// empty, restore the bottom
+		atomic.StoreInt64(&d.bottom, b+1)
+		return nil, false
+	}
+
+	item := a.get(b)
+	if t == b {
+		// this is the last item, so race any thieves for it
+		won := atomic.CompareAndSwapInt64(&d.top, t, t+1)
+		atomic.StoreInt64(&d.bottom, b+1)
+		if !won {
+			return nil, false
+		}
+		return item, true
+	}
+
+	// no thief can reach this slot anymore, so drop the reference
+	atomic.StorePointer(&a.slots[b&a.mask], nil)
+	return item, true
+}
+
+// Steal removes and returns the item at the top of the deque, the
+// oldest one.  It returns false if the deque is empty.  It may be
+// called from any goroutine.
+func (d *WorkStealingDeque) Steal() (interface{}, bool) {
+	for {
+		t := atomic.LoadInt64(&d.top)
+		b := atomic.LoadInt64(&d.bottom)
+		if t >= b {
+			return nil, false
+		}
+
+		item := d.loadArray().get(t)
+		if atomic.CompareAndSwapInt64(&d.top, t, t+1) {
+			return item, true
+		}
+		// lost to another thief or the owner, try the next item
+	}
+}
+
+// Len returns the number of items in the deque.  The result is only
+// approximate while other goroutines are using the deque.
+func (d *WorkStealingDeque) Len() int {
+	b := atomic.LoadInt64(&d.bottom)
+	t := atomic.LoadInt64(&d.top)
+	if b <= t {
+		return 0
+	}
+	return int(b - t)
+}
+
+// Empty returns a bool indicating if the deque is empty.
+func (d *WorkStealingDeque) Empty() bool {
+	return d.Len() == 0
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkStealingPushPop(t *testing.T) {
+	d := NewWorkStealingDeque(2)
+
+	for i := 0; i < 10; i++ {
+		d.Push(i)
+	}
+	assert.Equal(t, 10, d.Len())
+
+	// the owner pops LIFO and thieves steal FIFO
+	item, ok := d.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, 9, item)
+	item, ok = d.Steal()
+	assert.True(t, ok)
+	assert.Equal(t, 0, item)
+
+	for i := 8; i > 0; i-- {
+		item, ok = d.Pop()
+		assert.True(t, ok)
+		assert.Equal(t, i, item)
+	}
+
+	assert.True(t, d.Empty())
+	_, ok = d.Pop()
+	assert.False(t, ok)
+	_, ok = d.Steal()
+	assert.False(t, ok)
+	assert.Equal(t, 0, d.Len())
+}
+
+func TestWorkStealingConcurrent(t *testing.T) {
+	d := NewWorkStealingDeque(4)
+	const total, thieves = 100000, 4
+
+	var (
+		seen  = make([]int32, total)
+		taken int64
+		wg    sync.WaitGroup
+		stop  = make(chan struct{})
+	)
+	take := func(item interface{}) {
+		atomic.AddInt32(&seen[item.(int)], 1)
+		atomic.AddInt64(&taken, 1)
+	}
+
+	wg.Add(thieves)
+	for i := 0; i < thieves; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if item, ok := d.Steal(); ok {
+					take(item)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < total; i++ {
+		d.Push(i)
+		if i%3 == 0 {
+			if item, ok := d.Pop(); ok {
+				take(item)
+			}
+		}
+	}
+	for {
+		item, ok := d.Pop()
+		if !ok {
+			break
+		}
+		take(item)
+	}
+	// thieves may still be holding the last items they stole
+	for atomic.LoadInt64(&taken) < total {
+		runtime.Gosched()
+	}
+	close(stop)
+	wg.Wait()
+
+	for i, count := range seen {
+		if !assert.Equal(t, int32(1), count, "item %d", i) {
+			return
+		}
+	}
+}
+
+func BenchmarkWorkStealingPushPop(b *testing.B) {
+	d := NewWorkStealingDeque(64)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		d.Push(i)
+		d.Pop()
+	}
+}
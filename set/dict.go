@@ -18,6 +18,11 @@ limitations under the License.
 Package set is a simple unordered set implemented with a map.  This set
 is threadsafe which decreases performance.
 
+HashSet and SyncSet are generic sets of comparable items that also
+offer set algebra: union, intersection, difference, symmetric
+difference and subset tests.  HashSet is unsynchronized for use within
+a single goroutine, while SyncSet guards a HashSet with a lock.
+
 TODO: Actually write custom hashmap using the hash/fnv hasher.
 
 TODO: Our Set implementation Could be further optimized by getting the uintptr
@@ -0,0 +1,182 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+// HashSet is a generic set of comparable items built on the builtin map
+// type.  HashSet is not threadsafe; use SyncSet when a set is shared
+// between goroutines.  The zero value is not ready for use, create
+// sets with NewHashSet.
+type HashSet[T comparable] struct {
+	items map[T]struct{}
+}
+
+// NewHashSet is the constructor for HashSets.  Takes a list of items to
+// initialize the set with.
+func NewHashSet[T comparable](items ...T) *HashSet[T] {
+	set := &HashSet[T]{items: make(map[T]struct{}, len(items))}
+	set.Add(items...)
+	return set
+}
+
+// Add will add the provided items to the set.
+func (set *HashSet[T]) Add(items ...T) {
+	for _, item := range items {
+		set.items[item] = struct{}{}
+	}
+}
+
+// Remove will remove the given items from the set.
+func (set *HashSet[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(set.items, item)
+	}
+}
+
+// Exists returns a bool indicating if the given item exists in the set.
+func (set *HashSet[T]) Exists(item T) bool {
+	_, ok := set.items[item]
+	return ok
+}
+
+// All returns a bool indicating if all of the supplied items exist in the set.
+func (set *HashSet[T]) All(items ...T) bool {
+	for _, item := range items {
+		if _, ok := set.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of items in the set.
+func (set *HashSet[T]) Len() int {
+	return len(set.items)
+}
+
+// Clear will remove all items from the set.
+func (set *HashSet[T]) Clear() {
+	set.items = map[T]struct{}{}
+}
+
+// Flatten will return a list of the items in the set in no particular
+// order.
+func (set *HashSet[T]) Flatten() []T {
+	items := make([]T, 0, len(set.items))
+	for item := range set.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Range calls fn for each item in the set, in no particular order,
+// until fn returns false.  The set must not be modified by fn.
+func (set *HashSet[T]) Range(fn func(item T) bool) {
+	for item := range set.items {
+		if !fn(item) {
+			return
+		}
+	}
+}
+
+// Copy returns a new set holding the same items.
+func (set *HashSet[T]) Copy() *HashSet[T] {
+	cp := &HashSet[T]{items: make(map[T]struct{}, len(set.items))}
+	for item := range set.items {
+		cp.items[item] = struct{}{}
+	}
+	return cp
+}
+
+// Union returns a new set holding the items that are in either set.
+func (set *HashSet[T]) Union(other *HashSet[T]) *HashSet[T] {
+	big, small := set, other
+	if len(small.items) > len(big.items) {
+		big, small = small, big
+	}
+
+	result := big.Copy()
+	for item := range small.items {
+		result.items[item] = struct{}{}
+	}
+	return result
+}
+
+// Intersection returns a new set holding the items that are in both
+// sets.
+func (set *HashSet[T]) Intersection(other *HashSet[T]) *HashSet[T] {
+	big, small := set, other
+	if len(small.items) > len(big.items) {
+		big, small = small, big
+	}
+
+	result := NewHashSet[T]()
+	for item := range small.items {
+		if _, ok := big.items[item]; ok {
+			result.items[item] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Difference returns a new set holding the items of this set that are
+// not in other.
+func (set *HashSet[T]) Difference(other *HashSet[T]) *HashSet[T] {
+	result := NewHashSet[T]()
+	for item := range set.items {
+		if _, ok := other.items[item]; !ok {
+			result.items[item] = struct{}{}
+		}
+	}
+	return result
+}
+
+// SymmetricDifference returns a new set holding the items that are in
+// exactly one of the sets.
+func (set *HashSet[T]) SymmetricDifference(other *HashSet[T]) *HashSet[T] {
+	result := set.Difference(other)
+	for item := range other.items {
+		if _, ok := set.items[item]; !ok {
+			result.items[item] = struct{}{}
+		}
+	}
+	return result
+}
+
+// IsSubset returns a bool indicating if every item of this set is in
+// other.
+func (set *HashSet[T]) IsSubset(other *HashSet[T]) bool {
+	if len(set.items) > len(other.items) {
+		return false
+	}
+	for item := range set.items {
+		if _, ok := other.items[item]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSuperset returns a bool indicating if every item of other is in
+// this set.
+func (set *HashSet[T]) IsSuperset(other *HashSet[T]) bool {
+	return other.IsSubset(set)
+}
+
+// Equal returns a bool indicating if both sets hold the same items.
+func (set *HashSet[T]) Equal(other *HashSet[T]) bool {
+	return len(set.items) == len(other.items) && set.IsSubset(other)
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func sorted(items []int) []int {
+	sort.Ints(items)
+	return items
+}
+
+func TestHashSetAddRemove(t *testing.T) {
+	set := NewHashSet(1, 2, 2, 3)
+	if set.Len() != 3 {
+		t.Errorf(`Expected len: %d, received: %d`, 3, set.Len())
+	}
+
+	set.Remove(2, 4)
+	if set.Exists(2) || !set.Exists(1) || !set.All(1, 3) || set.All(1, 2) {
+		t.Errorf(`Incorrect items: %+v`, set.Flatten())
+	}
+
+	set.Clear()
+	if set.Len() != 0 {
+		t.Errorf(`Expected empty set, received: %+v`, set.Flatten())
+	}
+	set.Add(5)
+	if !reflect.DeepEqual([]int{5}, set.Flatten()) {
+		t.Errorf(`Incorrect result returned: %+v`, set.Flatten())
+	}
+}
+
+func TestHashSetAlgebra(t *testing.T) {
+	a := NewHashSet(1, 2, 3, 4)
+	b := NewHashSet(3, 4, 5)
+
+	tests := []struct {
+		name     string
+		result   *HashSet[int]
+		expected []int
+	}{
+		{`union`, a.Union(b), []int{1, 2, 3, 4, 5}},
+		{`intersection`, a.Intersection(b), []int{3, 4}},
+		{`difference`, a.Difference(b), []int{1, 2}},
+		{`reverse difference`, b.Difference(a), []int{5}},
+		{`symmetric difference`, a.SymmetricDifference(b), []int{1, 2, 5}},
+		{`empty intersection`, a.Intersection(NewHashSet[int]()), []int{}},
+	}
+	for _, tt := range tests {
+		if result := sorted(tt.result.Flatten()); !reflect.DeepEqual(tt.expected, result) {
+			t.Errorf(`%s: expected: %+v, received: %+v`, tt.name, tt.expected, result)
+		}
+	}
+
+	// operands are left untouched
+	if !reflect.DeepEqual([]int{1, 2, 3, 4}, sorted(a.Flatten())) {
+		t.Errorf(`Operand modified: %+v`, a.Flatten())
+	}
+}
+
+func TestHashSetCompare(t *testing.T) {
+	a := NewHashSet(1, 2)
+	b := NewHashSet(1, 2, 3)
+
+	if !a.IsSubset(b) || b.IsSubset(a) || !a.IsSubset(a) {
+		t.Errorf(`Incorrect subset result`)
+	}
+	if !b.IsSuperset(a) || a.IsSuperset(b) {
+		t.Errorf(`Incorrect superset result`)
+	}
+	if a.Equal(b) || !a.Equal(NewHashSet(2, 1)) || !NewHashSet[int]().Equal(NewHashSet[int]()) {
+		t.Errorf(`Incorrect equal result`)
+	}
+	if NewHashSet(1, 4).IsSubset(b) {
+		t.Errorf(`Incorrect subset result for disjoint item`)
+	}
+}
+
+func TestHashSetRange(t *testing.T) {
+	set := NewHashSet(1, 2, 3, 4)
+
+	var seen []int
+	set.Range(func(item int) bool {
+		seen = append(seen, item)
+		return true
+	})
+	if !reflect.DeepEqual([]int{1, 2, 3, 4}, sorted(seen)) {
+		t.Errorf(`Incorrect items visited: %+v`, seen)
+	}
+
+	count := 0
+	set.Range(func(item int) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Errorf(`Expected range to stop after %d items, visited: %d`, 2, count)
+	}
+}
+
+func TestSyncSet(t *testing.T) {
+	a := NewSyncSet(1, 2, 3)
+	b := NewSyncSet(2, 3, 4)
+
+	if result := sorted(a.Union(b).Flatten()); !reflect.DeepEqual([]int{1, 2, 3, 4}, result) {
+		t.Errorf(`Incorrect union: %+v`, result)
+	}
+	if result := sorted(a.Intersection(b).Flatten()); !reflect.DeepEqual([]int{2, 3}, result) {
+		t.Errorf(`Incorrect intersection: %+v`, result)
+	}
+	if result := sorted(a.Difference(b).Flatten()); !reflect.DeepEqual([]int{1}, result) {
+		t.Errorf(`Incorrect difference: %+v`, result)
+	}
+	if result := sorted(a.SymmetricDifference(b).Flatten()); !reflect.DeepEqual([]int{1, 4}, result) {
+		t.Errorf(`Incorrect symmetric difference: %+v`, result)
+	}
+	if !a.Equal(a) || a.Equal(b) || !a.IsSubset(a.Union(b)) || !a.IsSuperset(NewSyncSet(1)) {
+		t.Errorf(`Incorrect comparison result`)
+	}
+
+	snapshot := a.Snapshot()
+	a.Remove(1)
+	if !snapshot.Exists(1) || a.Exists(1) || a.Len() != 2 {
+		t.Errorf(`Snapshot shares state with set`)
+	}
+}
+
+func TestSyncSetConcurrent(t *testing.T) {
+	a, b := NewSyncSet[int](), NewSyncSet[int]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Add(i*100 + j)
+				b.Add(j)
+				a.Union(b)
+				b.Intersection(a)
+				a.Equal(b)
+				a.Range(func(int) bool { return true })
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if a.Len() != 400 || b.Len() != 100 || !b.IsSubset(a) {
+		t.Errorf(`Incorrect lengths: %d, %d`, a.Len(), b.Len())
+	}
+}
+
+func BenchmarkHashSetExists(b *testing.B) {
+	set := NewHashSet[string]()
+	for i := 0; i < 100; i++ {
+		set.Add(strconv.Itoa(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Exists(`50`)
+	}
+}
+
+func BenchmarkSyncSetExists(b *testing.B) {
+	set := NewSyncSet[string]()
+	for i := 0; i < 100; i++ {
+		set.Add(strconv.Itoa(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Exists(`50`)
+	}
+}
+
+func BenchmarkHashSetIntersection(b *testing.B) {
+	x, y := NewHashSet[int](), NewHashSet[int]()
+	for i := 0; i < 1000; i++ {
+		x.Add(i)
+		y.Add(i * 2)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.Intersection(y)
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package set
+
+import "sync"
+
+// SyncSet is a HashSet guarded by a sync.RWMutex, making it threadsafe.
+// Operations combining two SyncSets never hold both locks at once: the
+// other set is copied under its own lock first, so sets may be combined
+// with each other, or with themselves, from any goroutine without
+// deadlocking.
+type SyncSet[T comparable] struct {
+	set  *HashSet[T]
+	lock sync.RWMutex
+}
+
+// NewSyncSet is the constructor for SyncSets.  Takes a list of items to
+// initialize the set with.
+func NewSyncSet[T comparable](items ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: NewHashSet(items...)}
+}
+
+// Add will add the provided items to the set.
+func (set *SyncSet[T]) Add(items ...T) {
+	set.lock.Lock()
+	set.set.Add(items...)
+	set.lock.Unlock()
+}
+
+// Remove will remove the given items from the set.
+func (set *SyncSet[T]) Remove(items ...T) {
+	set.lock.Lock()
+	set.set.Remove(items...)
+	set.lock.Unlock()
+}
+
+// Exists returns a bool indicating if the given item exists in the set.
+func (set *SyncSet[T]) Exists(item T) bool {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return set.set.Exists(item)
+}
+
+// All returns a bool indicating if all of the supplied items exist in the set.
+func (set *SyncSet[T]) All(items ...T) bool {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return set.set.All(items...)
+}
+
+// Len returns the number of items in the set.
+func (set *SyncSet[T]) Len() int {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return set.set.Len()
+}
+
+// Clear will remove all items from the set.
+func (set *SyncSet[T]) Clear() {
+	set.lock.Lock()
+	set.set.Clear()
+	set.lock.Unlock()
+}
+
+// Flatten will return a list of the items in the set in no particular
+// order.
+func (set *SyncSet[T]) Flatten() []T {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return set.set.Flatten()
+}
+
+// Range calls fn for each item in the set, in no particular order,
+// until fn returns false.  The set is read locked for the duration, so
+// fn must not modify it.
+func (set *SyncSet[T]) Range(fn func(item T) bool) {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	set.set.Range(fn)
+}
+
+// Snapshot returns an unsynchronized copy of the set.
+func (set *SyncSet[T]) Snapshot() *HashSet[T] {
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return set.set.Copy()
+}
+
+// Union returns a new set holding the items that are in either set.
+func (set *SyncSet[T]) Union(other *SyncSet[T]) *SyncSet[T] {
+	return set.combine(other, (*HashSet[T]).Union)
+}
+
+// Intersection returns a new set holding the items that are in both
+// sets.
+func (set *SyncSet[T]) Intersection(other *SyncSet[T]) *SyncSet[T] {
+	return set.combine(other, (*HashSet[T]).Intersection)
+}
+
+// Difference returns a new set holding the items of this set that are
+// not in other.
+func (set *SyncSet[T]) Difference(other *SyncSet[T]) *SyncSet[T] {
+	return set.combine(other, (*HashSet[T]).Difference)
+}
+
+// SymmetricDifference returns a new set holding the items that are in
+// exactly one of the sets.
+func (set *SyncSet[T]) SymmetricDifference(other *SyncSet[T]) *SyncSet[T] {
+	return set.combine(other, (*HashSet[T]).SymmetricDifference)
+}
+
+// IsSubset returns a bool indicating if every item of this set is in
+// other.
+func (set *SyncSet[T]) IsSubset(other *SyncSet[T]) bool {
+	return set.compare(other, (*HashSet[T]).IsSubset)
+}
+
+// IsSuperset returns a bool indicating if every item of other is in
+// this set.
+func (set *SyncSet[T]) IsSuperset(other *SyncSet[T]) bool {
+	return set.compare(other, (*HashSet[T]).IsSuperset)
+}
+
+// Equal returns a bool indicating if both sets hold the same items.
+func (set *SyncSet[T]) Equal(other *SyncSet[T]) bool {
+	return set.compare(other, (*HashSet[T]).Equal)
+}
+
+func (set *SyncSet[T]) combine(other *SyncSet[T], op func(a, b *HashSet[T]) *HashSet[T]) *SyncSet[T] {
+	o := other.Snapshot()
+
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return &SyncSet[T]{set: op(set.set, o)}
+}
+
+func (set *SyncSet[T]) compare(other *SyncSet[T], op func(a, b *HashSet[T]) bool) bool {
+	o := other.Snapshot()
+
+	set.lock.RLock()
+	defer set.lock.RUnlock()
+
+	return op(set.set, o)
+}
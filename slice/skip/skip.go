@@ -40,6 +40,9 @@ InsertByPosition: O(log n)
 
 More information here: http://cglab.ca/~morin/teaching/5408/refs/p90b.pdf
 
+SortedSet builds a Redis style sorted set (ZSET) on top of the skiplist,
+using the position capability for O(log n) rank queries.
+
 Benchmarks:
 BenchmarkInsert-8	 		 2000000	       930 ns/op
 BenchmarkGet-8	 			 2000000	       989 ns/op
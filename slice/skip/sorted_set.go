@@ -0,0 +1,263 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Workiva/go-datastructures/common"
+)
+
+// ErrNaNScore is returned when a sorted set operation would give a
+// member a score that is not a number.
+var ErrNaNScore = errors.New(`skip: score is not a number`)
+
+// Z is a member of a SortedSet together with its score.
+type Z struct {
+	Score  float64
+	Member string
+}
+
+// zentry is the Comparator stored in the skiplist backing a SortedSet.
+// Entries are ordered by score and then lexicographically by member,
+// like a Redis ZSET.  A bound entry sorts after every member with the
+// same score, which lets a search land just past a score.
+type zentry struct {
+	score  float64
+	member string
+	bound  bool
+}
+
+// Compare implements common.Comparator.
+func (z zentry) Compare(other common.Comparator) int {
+	o := other.(zentry)
+	switch {
+	case z.score < o.score:
+		return -1
+	case z.score > o.score:
+		return 1
+	case z.bound != o.bound:
+		if z.bound {
+			return 1
+		}
+		return -1
+	case z.member < o.member:
+		return -1
+	case z.member > o.member:
+		return 1
+	}
+	return 0
+}
+
+// SortedSet is a set of unique string members ordered by a float64
+// score, with the semantics of a Redis ZSET: members with equal scores
+// are ordered lexicographically and ranks are zero based.  Membership
+// is tracked in a map and order in a SkipList, so lookups by member are
+// O(1) and rank and range operations are O(log n) plus the size of the
+// result.  SortedSet is not threadsafe.
+type SortedSet struct {
+	scores map[string]float64
+	list   *SkipList
+}
+
+// NewSortedSet returns a new, empty sorted set.
+func NewSortedSet() *SortedSet {
+	return &SortedSet{
+		scores: map[string]float64{},
+		list:   New(uint64(0)),
+	}
+}
+
+// ZAdd sets the scores of the provided members, adding those that are
+// not yet in the set, and returns the number of members added.  If any
+// score is NaN, ErrNaNScore is returned and the set is unchanged.
+func (ss *SortedSet) ZAdd(members ...Z) (int, error) {
+	for _, z := range members {
+		if math.IsNaN(z.Score) {
+			return 0, ErrNaNScore
+		}
+	}
+
+	added := 0
+	for _, z := range members {
+		if score, ok := ss.scores[z.Member]; ok {
+			if score == z.Score {
+				continue
+			}
+			ss.list.Delete(zentry{score: score, member: z.Member})
+		} else {
+			added++
+		}
+
+		ss.scores[z.Member] = z.Score
+		ss.list.Insert(zentry{score: z.Score, member: z.Member})
+	}
+
+	return added, nil
+}
+
+// ZRem removes the provided members from the set and returns the
+// number of members that were removed.
+func (ss *SortedSet) ZRem(members ...string) int {
+	removed := 0
+	for _, member := range members {
+		score, ok := ss.scores[member]
+		if !ok {
+			continue
+		}
+
+		delete(ss.scores, member)
+		ss.list.Delete(zentry{score: score, member: member})
+		removed++
+	}
+
+	return removed
+}
+
+// ZIncrBy adds increment to the score of member, adding the member with
+// a score of increment if it is not in the set, and returns the new
+// score.  ErrNaNScore is returned if the new score would be NaN.
+func (ss *SortedSet) ZIncrBy(increment float64, member string) (float64, error) {
+	score := ss.scores[member] + increment
+	if math.IsNaN(score) {
+		return 0, ErrNaNScore
+	}
+
+	ss.ZAdd(Z{Score: score, Member: member})
+	return score, nil
+}
+
+// ZScore returns the score of member and a bool indicating if the
+// member is in the set.
+func (ss *SortedSet) ZScore(member string) (float64, bool) {
+	score, ok := ss.scores[member]
+	return score, ok
+}
+
+// ZCard returns the number of members in the set.
+func (ss *SortedSet) ZCard() uint64 {
+	return ss.list.Len()
+}
+
+// ZRank returns the rank of member, ordered from the lowest score, and
+// a bool indicating if the member is in the set.  This is an O(log n)
+// operation.
+func (ss *SortedSet) ZRank(member string) (uint64, bool) {
+	score, ok := ss.scores[member]
+	if !ok {
+		return 0, false
+	}
+
+	_, rank := ss.list.GetWithPosition(zentry{score: score, member: member})
+	return rank, true
+}
+
+// ZRevRank returns the rank of member, ordered from the highest score,
+// and a bool indicating if the member is in the set.  This is an
+// O(log n) operation.
+func (ss *SortedSet) ZRevRank(member string) (uint64, bool) {
+	rank, ok := ss.ZRank(member)
+	if !ok {
+		return 0, false
+	}
+
+	return ss.list.Len() - 1 - rank, true
+}
+
+// ZRangeByRank returns the members ranked from start to stop, both
+// inclusive, ordered from the lowest score.  As in Redis, negative
+// ranks count back from the highest score, so -1 is the last member,
+// and out of range ranks are clamped.
+func (ss *SortedSet) ZRangeByRank(start, stop int64) []Z {
+	length := int64(ss.list.Len())
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop {
+		return nil
+	}
+
+	return ss.collect(uint64(start), int(stop-start+1), math.Inf(1))
+}
+
+// ZRangeByScore returns the members with scores between min and max,
+// both inclusive, ordered from the lowest score.  Like the LIMIT clause
+// of Redis, offset matching members are skipped and at most count are
+// returned; a negative count returns all remaining members.  Use
+// math.Inf for unbounded ranges.
+func (ss *SortedSet) ZRangeByScore(min, max float64, offset uint64, count int) []Z {
+	if min > max {
+		return nil
+	}
+
+	return ss.collect(ss.rankOf(min, false)+offset, count, max)
+}
+
+// ZCount returns the number of members with scores between min and
+// max, both inclusive.  This is an O(log n) operation.
+func (ss *SortedSet) ZCount(min, max float64) uint64 {
+	if min > max {
+		return 0
+	}
+
+	return ss.rankOf(max, true) - ss.rankOf(min, false)
+}
+
+// rankOf returns the rank of the first member with a score of at least
+// score or, if after is set, greater than score.
+func (ss *SortedSet) rankOf(score float64, after bool) uint64 {
+	entry, rank := ss.list.GetWithPosition(zentry{score: score, bound: after})
+	if entry == nil {
+		return ss.list.Len()
+	}
+
+	return rank
+}
+
+// collect returns up to count members, or all of them if count is
+// negative, starting at rank start and stopping at the first member
+// scored above max.
+func (ss *SortedSet) collect(start uint64, count int, max float64) []Z {
+	if start >= ss.list.Len() || count == 0 {
+		return nil
+	}
+
+	var result []Z
+	for iter := ss.list.IterAtPosition(start); iter.Next(); {
+		entry := iter.Value().(zentry)
+		if entry.score > max {
+			break
+		}
+
+		result = append(result, Z{Score: entry.score, Member: entry.member})
+		if len(result) == count {
+			break
+		}
+	}
+
+	return result
+}
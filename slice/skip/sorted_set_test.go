@@ -0,0 +1,206 @@
+/*
+Copyright 2014 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package skip
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLeaderboard() *SortedSet {
+	ss := NewSortedSet()
+	ss.ZAdd(
+		Z{Score: 10, Member: `carol`},
+		Z{Score: 5, Member: `alice`},
+		Z{Score: 10, Member: `bob`},
+		Z{Score: 20, Member: `dave`},
+		Z{Score: 1, Member: `erin`},
+	)
+	return ss
+}
+
+func TestSortedSetAddRem(t *testing.T) {
+	ss := newLeaderboard()
+	assert.Equal(t, uint64(5), ss.ZCard())
+
+	added, err := ss.ZAdd(Z{Score: 2, Member: `alice`}, Z{Score: 3, Member: `frank`})
+	require.NoError(t, err)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, uint64(6), ss.ZCard())
+	score, ok := ss.ZScore(`alice`)
+	assert.True(t, ok)
+	assert.Equal(t, float64(2), score)
+
+	assert.Equal(t, 2, ss.ZRem(`alice`, `alice`, `nobody`, `dave`))
+	assert.Equal(t, uint64(4), ss.ZCard())
+	_, ok = ss.ZScore(`alice`)
+	assert.False(t, ok)
+	_, ok = ss.ZRank(`dave`)
+	assert.False(t, ok)
+
+	_, err = ss.ZAdd(Z{Score: 1, Member: `gina`}, Z{Score: math.NaN(), Member: `harry`})
+	assert.Equal(t, ErrNaNScore, err)
+	_, ok = ss.ZScore(`gina`)
+	assert.False(t, ok)
+}
+
+func TestSortedSetIncrBy(t *testing.T) {
+	ss := newLeaderboard()
+
+	score, err := ss.ZIncrBy(15, `erin`)
+	require.NoError(t, err)
+	assert.Equal(t, float64(16), score)
+	rank, _ := ss.ZRank(`erin`)
+	assert.Equal(t, uint64(3), rank)
+
+	score, err = ss.ZIncrBy(7, `new`)
+	require.NoError(t, err)
+	assert.Equal(t, float64(7), score)
+	assert.Equal(t, uint64(6), ss.ZCard())
+
+	ss.ZAdd(Z{Score: math.Inf(1), Member: `inf`})
+	_, err = ss.ZIncrBy(math.Inf(-1), `inf`)
+	assert.Equal(t, ErrNaNScore, err)
+}
+
+func TestSortedSetRank(t *testing.T) {
+	ss := newLeaderboard()
+
+	// ties are broken by member
+	for i, member := range []string{`erin`, `alice`, `bob`, `carol`, `dave`} {
+		rank, ok := ss.ZRank(member)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(i), rank, member)
+		rank, ok = ss.ZRevRank(member)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(4-i), rank, member)
+	}
+
+	_, ok := ss.ZRevRank(`nobody`)
+	assert.False(t, ok)
+}
+
+func TestSortedSetRangeByRank(t *testing.T) {
+	ss := newLeaderboard()
+
+	assert.Equal(t, []Z{{5, `alice`}, {10, `bob`}}, ss.ZRangeByRank(1, 2))
+	assert.Equal(t, []Z{{10, `carol`}, {20, `dave`}}, ss.ZRangeByRank(-2, -1))
+	assert.Len(t, ss.ZRangeByRank(0, -1), 5)
+	assert.Len(t, ss.ZRangeByRank(-100, 100), 5)
+	assert.Nil(t, ss.ZRangeByRank(3, 2))
+	assert.Nil(t, ss.ZRangeByRank(5, 10))
+	assert.Nil(t, NewSortedSet().ZRangeByRank(0, -1))
+}
+
+func TestSortedSetRangeByScore(t *testing.T) {
+	ss := newLeaderboard()
+
+	assert.Equal(t, []Z{{5, `alice`}, {10, `bob`}, {10, `carol`}}, ss.ZRangeByScore(5, 10, 0, -1))
+	assert.Equal(t, []Z{{10, `carol`}}, ss.ZRangeByScore(5, 10, 2, -1))
+	assert.Equal(t, []Z{{10, `bob`}}, ss.ZRangeByScore(5, 10, 1, 1))
+	assert.Len(t, ss.ZRangeByScore(math.Inf(-1), math.Inf(1), 0, -1), 5)
+	assert.Nil(t, ss.ZRangeByScore(6, 9, 0, -1))
+	assert.Nil(t, ss.ZRangeByScore(21, 30, 0, -1))
+	assert.Nil(t, ss.ZRangeByScore(10, 5, 0, -1))
+	assert.Nil(t, ss.ZRangeByScore(5, 10, 5, -1))
+	assert.Nil(t, ss.ZRangeByScore(5, 10, 0, 0))
+
+	assert.Equal(t, uint64(3), ss.ZCount(5, 10))
+	assert.Equal(t, uint64(2), ss.ZCount(10, 10))
+	assert.Equal(t, uint64(0), ss.ZCount(11, 19))
+	assert.Equal(t, uint64(5), ss.ZCount(math.Inf(-1), math.Inf(1)))
+	assert.Equal(t, uint64(0), ss.ZCount(10, 5))
+}
+
+func TestSortedSetRandomized(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	ss := NewSortedSet()
+	reference := map[string]float64{}
+
+	for i := 0; i < 2000; i++ {
+		member := strconv.Itoa(r.Intn(300))
+		switch r.Intn(3) {
+		case 0:
+			ss.ZRem(member)
+			delete(reference, member)
+		default:
+			score := float64(r.Intn(50))
+			ss.ZAdd(Z{Score: score, Member: member})
+			reference[member] = score
+		}
+	}
+
+	expected := make([]Z, 0, len(reference))
+	for member, score := range reference {
+		expected = append(expected, Z{Score: score, Member: member})
+	}
+	sort.Slice(expected, func(i, j int) bool {
+		if expected[i].Score != expected[j].Score {
+			return expected[i].Score < expected[j].Score
+		}
+		return expected[i].Member < expected[j].Member
+	})
+
+	require.Equal(t, uint64(len(expected)), ss.ZCard())
+	assert.Equal(t, expected, ss.ZRangeByRank(0, -1))
+	for i, z := range expected {
+		rank, ok := ss.ZRank(z.Member)
+		require.True(t, ok)
+		require.Equal(t, uint64(i), rank)
+	}
+
+	count := uint64(0)
+	for _, z := range expected {
+		if z.Score >= 10 && z.Score <= 20 {
+			count++
+		}
+	}
+	assert.Equal(t, count, ss.ZCount(10, 20))
+	assert.Len(t, ss.ZRangeByScore(10, 20, 0, -1), int(count))
+}
+
+func BenchmarkSortedSetZAdd(b *testing.B) {
+	ss := NewSortedSet()
+	members := make([]string, b.N)
+	for i := range members {
+		members[i] = strconv.Itoa(i)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ss.ZAdd(Z{Score: rand.Float64(), Member: members[i]})
+	}
+}
+
+func BenchmarkSortedSetZRank(b *testing.B) {
+	numItems := 1000
+	ss := NewSortedSet()
+	for i := 0; i < numItems; i++ {
+		ss.ZAdd(Z{Score: rand.Float64(), Member: strconv.Itoa(i)})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ss.ZRank(strconv.Itoa(i % numItems))
+	}
+}
@@ -25,7 +25,6 @@ package ctrie
 
 import (
 	"bytes"
-	"errors"
 	"hash"
 	"hash/fnv"
 	"sync/atomic"
@@ -357,17 +356,35 @@ func (c *Ctrie) Clear() {
 // Iterator returns a channel which yields the Entries of the Ctrie. If a
 // cancel channel is provided, closing it will terminate and close the iterator
 // channel. Note that if a cancel channel is not used and not every entry is
-// read from the iterator, a goroutine will leak.
+// read from the iterator, a goroutine will leak. Range avoids both the
+// goroutine and the channel.
 func (c *Ctrie) Iterator(cancel <-chan struct{}) <-chan *Entry {
 	ch := make(chan *Entry)
 	snapshot := c.ReadOnlySnapshot()
 	go func() {
-		snapshot.traverse(snapshot.readRoot(), ch, cancel)
+		snapshot.walk(snapshot.readRoot(), func(e *Entry) bool {
+			select {
+			case ch <- e:
+				return true
+			case <-cancel:
+				return false
+			}
+		})
 		close(ch)
 	}()
 	return ch
 }
 
+// Range calls fn for each key-value pair of a read-only snapshot of the
+// Ctrie, in no particular order, until fn returns false. Because a snapshot
+// is iterated, fn sees a consistent view of the Ctrie and may modify it.
+func (c *Ctrie) Range(fn func(key []byte, value interface{}) bool) {
+	snapshot := c.ReadOnlySnapshot()
+	snapshot.walk(snapshot.readRoot(), func(e *Entry) bool {
+		return fn(e.Key, e.Value)
+	})
+}
+
 // Size returns the number of keys in the Ctrie.
 func (c *Ctrie) Size() uint {
 	// TODO: The size operation can be optimized further by caching the size
@@ -376,50 +393,41 @@ func (c *Ctrie) Size() uint {
 	// computation is amortized across the update operations that occurred
 	// since the last snapshot.
 	size := uint(0)
-	for _ = range c.Iterator(nil) {
+	c.Range(func([]byte, interface{}) bool {
 		size++
-	}
+		return true
+	})
 	return size
 }
 
-var errCanceled = errors.New("canceled")
-
-func (c *Ctrie) traverse(i *iNode, ch chan<- *Entry, cancel <-chan struct{}) error {
+// walk calls fn for each Entry under the iNode until fn returns false, and
+// reports whether every Entry was visited.
+func (c *Ctrie) walk(i *iNode, fn func(*Entry) bool) bool {
 	main := gcasRead(i, c)
 	switch {
 	case main.cNode != nil:
 		for _, br := range main.cNode.array {
 			switch b := br.(type) {
 			case *iNode:
-				if err := c.traverse(b, ch, cancel); err != nil {
-					return err
+				if !c.walk(b, fn) {
+					return false
 				}
 			case *sNode:
-				select {
-				case ch <- b.Entry:
-				case <-cancel:
-					return errCanceled
+				if !fn(b.Entry) {
+					return false
 				}
 			}
 		}
 	case main.lNode != nil:
-		for _, e := range main.lNode.Map(func(sn interface{}) interface{} {
-			return sn.(*sNode).Entry
-		}) {
-			select {
-			case ch <- e.(*Entry):
-			case <-cancel:
-				return errCanceled
+		for _, sn := range main.lNode.ToSlice() {
+			if !fn(sn.(*sNode).Entry) {
+				return false
 			}
 		}
 	case main.tNode != nil:
-		select {
-		case ch <- main.tNode.Entry:
-		case <-cancel:
-			return errCanceled
-		}
+		return fn(main.tNode.Entry)
 	}
-	return nil
+	return true
 }
 
 func (c *Ctrie) assertReadWrite() {
@@ -406,6 +406,32 @@ func TestIteratorCoversTNodes(t *testing.T) {
 	assert.Len(seenKeys, 1)
 }
 
+func TestRange(t *testing.T) {
+	assert := assert.New(t)
+	ctrie := New(nil)
+	for i := 0; i < 10; i++ {
+		ctrie.Insert([]byte(strconv.Itoa(i)), i)
+	}
+
+	seen := map[string]interface{}{}
+	ctrie.Range(func(key []byte, value interface{}) bool {
+		seen[string(key)] = value
+		// modifying the Ctrie does not affect the snapshot being iterated
+		ctrie.Insert([]byte("new"+string(key)), value)
+		return true
+	})
+	assert.Len(seen, 10)
+	assert.Equal(5, seen["5"])
+	assert.Equal(uint(20), ctrie.Size())
+
+	count := 0
+	ctrie.Range(func([]byte, interface{}) bool {
+		count++
+		return count < 3
+	})
+	assert.Equal(3, count)
+}
+
 func TestSize(t *testing.T) {
 	ctrie := New(nil)
 	for i := 0; i < 10; i++ {
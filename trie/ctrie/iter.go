@@ -0,0 +1,30 @@
+//go:build go1.23
+
+/*
+Copyright 2015 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctrie
+
+import "iter"
+
+// All returns an iterator over the key-value pairs of a read-only snapshot
+// of the Ctrie, taken each time iteration starts. Unlike Iterator, it needs
+// no goroutine and stopping early leaks nothing.
+func (c *Ctrie) All() iter.Seq2[[]byte, interface{}] {
+	return func(yield func([]byte, interface{}) bool) {
+		c.Range(yield)
+	}
+}
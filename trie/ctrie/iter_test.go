@@ -0,0 +1,53 @@
+//go:build go1.23
+
+/*
+Copyright 2015 Workiva, LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ctrie
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	assert := assert.New(t)
+	ctrie := New(nil)
+	for i := 0; i < 100; i++ {
+		ctrie.Insert([]byte(strconv.Itoa(i)), i)
+	}
+
+	seen := map[string]interface{}{}
+	for key, value := range ctrie.All() {
+		seen[string(key)] = value
+		// the snapshot being iterated is unaffected
+		ctrie.Remove(key)
+	}
+	assert.Len(seen, 100)
+	assert.Equal(42, seen["42"])
+	assert.Equal(uint(0), ctrie.Size())
+
+	ctrie.Insert([]byte("a"), 1)
+	ctrie.Insert([]byte("b"), 2)
+	count := 0
+	for range ctrie.All() {
+		count++
+		break
+	}
+	assert.Equal(1, count)
+}
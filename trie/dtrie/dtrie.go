@@ -30,6 +30,12 @@ OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 // Ideal Hash Trees by Phil Bagwell and Optimizing Hash-Array Mapped Tries for
 // Fast and Lean Immutable JVM Collections by Michael J. Steindorfer and
 // Jurgen J. Vinju
+//
+// Every modification copies only the path to the changed entry, so versions
+// of a Dtrie share all untouched sub-tries. Equal, Diff and Merge take
+// advantage of this by skipping shared sub-tries, which makes comparing
+// closely related versions cheap. Transient builds a Dtrie in place for bulk
+// loading, and Set is a persistent set on top of a Dtrie.
 package dtrie
 
 // Dtrie is a persistent hash trie that dynamically expands or shrinks
@@ -71,9 +77,10 @@ func New(hasher func(v interface{}) uint32) *Dtrie {
 
 // Size returns the number of entries in the Dtrie.
 func (d *Dtrie) Size() (size int) {
-	for _ = range iterate(d.root, nil) {
+	walk(d.root, func(Entry) bool {
 		size++
-	}
+		return true
+	})
 	return size
 }
 
@@ -104,7 +111,233 @@ func (d *Dtrie) Remove(key interface{}) *Dtrie {
 // Iterator returns a read-only channel of Entries from the Dtrie. If a stop
 // channel is provided, closing it will terminate and close the iterator
 // channel. Note that if a cancel channel is not used and not every entry is
-// read from the iterator, a goroutine will leak.
+// read from the iterator, a goroutine will leak. Range avoids both the
+// goroutine and the channel.
 func (d *Dtrie) Iterator(stop <-chan struct{}) <-chan Entry {
 	return iterate(d.root, stop)
 }
+
+// Range calls fn for each key value pair in the Dtrie, in no particular
+// order, until fn returns false.
+func (d *Dtrie) Range(fn func(key, value interface{}) bool) {
+	walk(d.root, func(e Entry) bool {
+		return fn(e.Key(), e.Value())
+	})
+}
+
+// Transient returns a Transient initialized with the entries of the Dtrie.
+// The Dtrie itself is not affected by changes to the Transient.
+func (d *Dtrie) Transient() *Transient {
+	return &Transient{root: d.root, hasher: d.hasher, edit: &edit{}}
+}
+
+// Equal reports whether both Dtries hold the same keys with values that are
+// equal according to eq, or according to == if eq is nil. Sub-tries shared
+// by both Dtries are not visited. Both Dtries must use the same hasher.
+func (d *Dtrie) Equal(other *Dtrie, eq func(a, b interface{}) bool) bool {
+	return diff(d.root, other.root, valuesEqual(eq), func(_, _ Entry) bool {
+		return false
+	})
+}
+
+// Delta holds the differences between two Dtries.
+type Delta struct {
+	// Added holds the entries whose keys are only in the newer Dtrie.
+	Added []Entry
+	// Removed holds the entries whose keys are only in the older Dtrie.
+	Removed []Entry
+	// Changed holds the entries whose keys are in both Dtries with
+	// different values, the older entry first.
+	Changed [][2]Entry
+}
+
+// Diff returns the differences between the Dtrie and a newer version of it.
+// Values are compared with eq, or with == if eq is nil. Sub-tries shared by
+// both Dtries are not visited, so the cost is proportional to the size of
+// the changes rather than the size of the Dtries. Both Dtries must use the
+// same hasher.
+func (d *Dtrie) Diff(other *Dtrie, eq func(a, b interface{}) bool) *Delta {
+	delta := &Delta{}
+	diff(d.root, other.root, valuesEqual(eq), func(old, new Entry) bool {
+		switch {
+		case old == nil:
+			delta.Added = append(delta.Added, new)
+		case new == nil:
+			delta.Removed = append(delta.Removed, old)
+		default:
+			delta.Changed = append(delta.Changed, [2]Entry{old, new})
+		}
+		return true
+	})
+	return delta
+}
+
+// Merge returns a Dtrie holding the entries of both Dtries. For keys in
+// both whose entries are not shared, resolveConflict is called with the key
+// and both values, this Dtrie's first, and its result is stored. Entries and
+// sub-tries the Dtries share, as related versions do for everything neither
+// has changed, are kept without calling resolveConflict, so it must return v
+// for (key, v, v) to give the same result as merging unrelated Dtries. If
+// resolveConflict is nil, the other Dtrie's value wins. Sub-tries only
+// present in one of the Dtries are shared with the result. Both Dtries must
+// use the same hasher.
+func (d *Dtrie) Merge(other *Dtrie, resolveConflict func(key, a, b interface{}) interface{}) *Dtrie {
+	if resolveConflict == nil {
+		resolveConflict = func(_, _, b interface{}) interface{} { return b }
+	}
+	root := merge(d.root, other.root, resolveConflict, &edit{})
+	return &Dtrie{root, d.hasher}
+}
+
+// Transient is a mutable builder for a Dtrie. It modifies the nodes it has
+// already copied in place instead of copying them again, which makes
+// inserting or removing many entries much cheaper than doing so one at a time
+// on a Dtrie. Transient is not threadsafe.
+type Transient struct {
+	root   *node
+	hasher func(v interface{}) uint32
+	edit   *edit
+}
+
+// Get returns the value for the associated key or returns nil if the
+// key does not exist.
+func (t *Transient) Get(key interface{}) interface{} {
+	if e := get(t.root, t.hasher(key), key); e != nil {
+		return e.Value()
+	}
+	return nil
+}
+
+// Insert adds a key value pair, replacing the existing value if the key
+// already exists, and returns the Transient for chaining.
+func (t *Transient) Insert(key, value interface{}) *Transient {
+	t.root = insertEdit(t.root, &entry{t.hasher(key), key, value}, t.edit)
+	return t
+}
+
+// Remove deletes the value for the associated key if it exists and returns
+// the Transient for chaining.
+func (t *Transient) Remove(key interface{}) *Transient {
+	t.root = removeEdit(t.root, t.hasher(key), key, t.edit)
+	return t
+}
+
+// Persistent returns a Dtrie holding the current entries. The Transient
+// remains usable afterwards, but later changes to it copy nodes again rather
+// than modify those now shared with the returned Dtrie.
+func (t *Transient) Persistent() *Dtrie {
+	t.edit = &edit{}
+	return &Dtrie{t.root, t.hasher}
+}
+
+func valuesEqual(eq func(a, b interface{}) bool) func(a, b interface{}) bool {
+	if eq != nil {
+		return eq
+	}
+	return func(a, b interface{}) bool { return a == b }
+}
+
+// diff calls visit for each difference between two nodes at the same level
+// until visit returns false, and reports whether every difference was
+// visited. Added entries are passed as new with a nil old and removed
+// entries as old with a nil new.
+func diff(a, b *node, eq func(a, b interface{}) bool, visit func(old, new Entry) bool) bool {
+	if a == b {
+		return true
+	}
+	for i := range a.entries {
+		ea, eb := a.entries[i], b.entries[i]
+		if ea == eb {
+			continue
+		}
+		index := uint(i)
+		if a.nodeMap.GetBit(index) && b.nodeMap.GetBit(index) {
+			if !diff(ea.(*node), eb.(*node), eq, visit) {
+				return false
+			}
+			continue
+		}
+		// the slots are shaped differently, so compare their entries by key
+		if !diffEntries(slotEntries(a, index), slotEntries(b, index), eq, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+func diffEntries(as, bs []Entry, eq func(a, b interface{}) bool, visit func(old, new Entry) bool) bool {
+	olds := make(map[interface{}]Entry, len(as))
+	for _, e := range as {
+		olds[e.Key()] = e
+	}
+	for _, e := range bs {
+		old, ok := olds[e.Key()]
+		if !ok {
+			if !visit(nil, e) {
+				return false
+			}
+			continue
+		}
+		delete(olds, e.Key())
+		if old != e && !eq(old.Value(), e.Value()) && !visit(old, e) {
+			return false
+		}
+	}
+	for _, e := range as {
+		if _, ok := olds[e.Key()]; ok && !visit(e, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// slotEntries returns every entry held under a slot of the node.
+func slotEntries(n *node, index uint) []Entry {
+	var entries []Entry
+	walkSlot(n, index, n.entries[index], func(e Entry) bool {
+		entries = append(entries, e)
+		return true
+	})
+	return entries
+}
+
+// merge returns a node holding the entries of both nodes, which are at the
+// same level, resolving the values of keys in both. Slots only set in b are
+// shared with the result.
+func merge(a, b *node, resolve func(key, a, b interface{}) interface{}, e *edit) *node {
+	if a == b {
+		return a
+	}
+	result := a
+	for i := range a.entries {
+		ea, eb := a.entries[i], b.entries[i]
+		if eb == nil || ea == eb {
+			continue
+		}
+		index := uint(i)
+		switch {
+		case ea == nil:
+			result = result.editable(e)
+			result.entries[i] = eb
+			if b.dataMap.GetBit(index) {
+				result.dataMap = result.dataMap.SetBit(index)
+			}
+			if b.nodeMap.GetBit(index) {
+				result.nodeMap = result.nodeMap.SetBit(index)
+			}
+		case a.nodeMap.GetBit(index) && b.nodeMap.GetBit(index):
+			if sub := merge(ea.(*node), eb.(*node), resolve, e); sub != ea {
+				result = result.editable(e)
+				result.entries[i] = sub
+			}
+		default:
+			for _, be := range slotEntries(b, index) {
+				if ae := get(result, be.KeyHash(), be.Key()); ae != nil {
+					be = &entry{be.KeyHash(), be.Key(), resolve(be.Key(), ae.Value(), be.Value())}
+				}
+				result = insertEdit(result, be, e)
+			}
+		}
+	}
+	return result
+}
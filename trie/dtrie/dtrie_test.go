@@ -128,12 +128,260 @@ func TestIterate(t *testing.T) {
 	assert.Equal(t, 1000, c)
 }
 
+func TestRange(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		d := New(hasher)
+		for i := 0; i < 1000; i++ {
+			d = d.Insert(i, -i)
+		}
+
+		seen := map[interface{}]interface{}{}
+		d.Range(func(key, value interface{}) bool {
+			seen[key] = value
+			return true
+		})
+		assert.Len(t, seen, 1000)
+		assert.Equal(t, -7, seen[7])
+
+		count := 0
+		d.Range(func(key, value interface{}) bool {
+			count++
+			return count < 10
+		})
+		assert.Equal(t, 10, count)
+	}
+}
+
 func TestSize(t *testing.T) {
 	n := insertTest(t, defaultHasher, 10000)
 	d := &Dtrie{n, defaultHasher}
 	assert.Equal(t, 10000, d.Size())
 }
 
+func TestPersistence(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		d1 := New(hasher)
+		for i := 0; i < 200; i++ {
+			d1 = d1.Insert(i, i)
+		}
+		d2 := d1.Insert(5, -5).Insert(500, 500).Remove(7)
+		d3 := d1.Remove(1000)
+
+		assert.Equal(t, 200, d1.Size())
+		assert.Equal(t, 5, d1.Get(5))
+		assert.Equal(t, 7, d1.Get(7))
+		assert.Nil(t, d1.Get(500))
+		assert.Equal(t, 200, d2.Size())
+		assert.Equal(t, -5, d2.Get(5))
+		assert.Nil(t, d2.Get(7))
+		assert.Equal(t, 500, d2.Get(500))
+		// removing a missing key shares the whole trie
+		assert.True(t, d1.root == d3.root)
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	d := New(nil).Insert(1, 1)
+	// 33 shares the slot of 1 on the first level
+	assert.Nil(t, d.Get(33))
+	assert.Equal(t, d.root, d.Remove(33).root)
+}
+
+func TestTransient(t *testing.T) {
+	base := New(nil).Insert(-1, -1)
+	tr := base.Transient()
+	for i := 0; i < 10000; i++ {
+		tr.Insert(i, i)
+	}
+	tr.Remove(-1).Remove(0)
+	assert.Equal(t, 5, tr.Get(5))
+	assert.Nil(t, tr.Get(0))
+
+	d := tr.Persistent()
+	assert.Equal(t, 9999, d.Size())
+	for i := 1; i < 10000; i++ {
+		assert.Equal(t, i, d.Get(i))
+	}
+	assert.Equal(t, 1, base.Size())
+	assert.Equal(t, -1, base.Get(-1))
+
+	// later changes to the transient do not leak into d
+	tr.Insert(1, -1).Remove(2)
+	assert.Equal(t, 1, d.Get(1))
+	assert.Equal(t, 2, d.Get(2))
+	assert.Equal(t, 9998, tr.Persistent().Size())
+}
+
+func TestTransientRemoveShape(t *testing.T) {
+	assert := assert.New(t)
+	// 1 and 33 share a slot on the first level
+	tr := New(nil).Transient()
+	tr.Insert(1, 1).Insert(33, 33).Remove(1)
+	d := tr.Persistent()
+	assert.Zero(d.root.nodeMap)
+	assert.True(d.root.dataMap.GetBit(1))
+	assert.Equal(33, d.root.entries[1].Key())
+
+	tr = New(nil).Transient()
+	tr.Insert(1, 1).Insert(33, 33).Remove(1).Remove(33)
+	d = tr.Persistent()
+	assert.Zero(d.root.nodeMap)
+	assert.Zero(d.root.dataMap)
+	assert.True(d.root.empty())
+
+	// removals through a transient leave the same trie as persistent ones
+	tr = New(nil).Transient()
+	p := New(nil)
+	for i := 0; i < 5000; i++ {
+		tr.Insert(i, i)
+		p = p.Insert(i, i)
+	}
+	for i := 0; i < 5000; i += 3 {
+		tr.Remove(i)
+		p = p.Remove(i)
+	}
+	assertSameShape(t, p.root, tr.Persistent().root)
+}
+
+func assertSameShape(t *testing.T, expected, actual *node) {
+	assert.Equal(t, expected.nodeMap, actual.nodeMap)
+	assert.Equal(t, expected.dataMap, actual.dataMap)
+	for i, e := range expected.entries {
+		if sub, ok := e.(*node); ok {
+			assertSameShape(t, sub, actual.entries[i].(*node))
+			continue
+		}
+		assert.Equal(t, e, actual.entries[i])
+	}
+}
+
+func TestEqual(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		forward, backward := New(hasher).Transient(), New(hasher).Transient()
+		for i := 0; i < 500; i++ {
+			forward.Insert(i, i)
+			backward.Insert(499-i, 499-i)
+		}
+		d1, d2 := forward.Persistent(), backward.Persistent()
+
+		assert.True(t, d1.Equal(d2, nil))
+		assert.True(t, d1.Equal(d1, nil))
+		assert.False(t, d1.Equal(d2.Insert(3, -3), nil))
+		assert.False(t, d1.Equal(d2.Remove(3), nil))
+		assert.False(t, d1.Remove(3).Equal(d2, nil))
+		assert.True(t, d1.Equal(d2.Insert(3, -3), func(a, b interface{}) bool {
+			return a.(int)*a.(int) == b.(int)*b.(int)
+		}))
+	}
+}
+
+func TestEqualSkipsSharedSubtries(t *testing.T) {
+	tr := New(nil).Transient()
+	for i := 0; i < 10000; i++ {
+		tr.Insert(i, i)
+	}
+	d1 := tr.Persistent()
+	d2 := d1.Insert(42, 42)
+
+	compared := 0
+	assert.True(t, d1.Equal(d2, func(a, b interface{}) bool {
+		compared++
+		return a == b
+	}))
+	assert.Equal(t, 1, compared)
+}
+
+func TestDiff(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		tr := New(hasher).Transient()
+		for i := 0; i < 1000; i++ {
+			tr.Insert(i, i)
+		}
+		d1 := tr.Persistent()
+		d2 := d1.Insert(1000, 1000).Insert(1001, 1001).Remove(10).Insert(20, -20).Insert(30, 30)
+
+		delta := d1.Diff(d2, nil)
+		added := map[interface{}]interface{}{}
+		for _, e := range delta.Added {
+			added[e.Key()] = e.Value()
+		}
+		assert.Equal(t, map[interface{}]interface{}{1000: 1000, 1001: 1001}, added)
+		assert.Len(t, delta.Removed, 1)
+		assert.Equal(t, 10, delta.Removed[0].Key())
+		assert.Len(t, delta.Changed, 1)
+		assert.Equal(t, 20, delta.Changed[0][0].Value())
+		assert.Equal(t, -20, delta.Changed[0][1].Value())
+
+		reverse := d2.Diff(d1, nil)
+		assert.Len(t, reverse.Added, 1)
+		assert.Len(t, reverse.Removed, 2)
+		assert.Len(t, reverse.Changed, 1)
+
+		assert.Equal(t, &Delta{}, d1.Diff(d1, nil))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		left, right := New(hasher).Transient(), New(hasher).Transient()
+		for i := 0; i < 300; i++ {
+			left.Insert(i, i)
+			right.Insert(i+200, -(i + 200))
+		}
+		d1, d2 := left.Persistent(), right.Persistent()
+
+		merged := d1.Merge(d2, func(key, a, b interface{}) interface{} {
+			return a.(int) + b.(int)
+		})
+		assert.Equal(t, 500, merged.Size())
+		for i := 0; i < 500; i++ {
+			switch {
+			case i < 200:
+				assert.Equal(t, i, merged.Get(i))
+			case i < 300:
+				assert.Equal(t, 0, merged.Get(i))
+			default:
+				assert.Equal(t, -i, merged.Get(i))
+			}
+		}
+		assert.Equal(t, 300, d1.Size())
+		assert.Equal(t, 299, d1.Get(299))
+		assert.Equal(t, 300, d2.Size())
+
+		// without a resolver the other Dtrie wins
+		assert.Equal(t, -250, d1.Merge(d2, nil).Get(250))
+		assert.True(t, d1.Merge(New(hasher), nil).Equal(d1, nil))
+		assert.True(t, New(hasher).Merge(d1, nil).Equal(d1, nil))
+	}
+}
+
+func TestMergeShared(t *testing.T) {
+	tr := New(nil).Transient()
+	for i := 0; i < 1000; i++ {
+		tr.Insert(i, i)
+	}
+	d := tr.Persistent()
+
+	var conflicts []interface{}
+	sum := func(key, a, b interface{}) interface{} {
+		conflicts = append(conflicts, key)
+		return a.(int) + b.(int)
+	}
+
+	// shared entries are kept as is, even by a resolver that would change
+	// them
+	assert.True(t, d.Merge(d, sum).root == d.root)
+	assert.Empty(t, conflicts)
+
+	derived := d.Insert(2, 20).Insert(1000, 1000)
+	merged := d.Merge(derived, sum)
+	assert.Equal(t, []interface{}{2}, conflicts)
+	assert.Equal(t, 22, merged.Get(2))
+	assert.Equal(t, 3, merged.Get(3))
+	assert.Equal(t, 1000, merged.Get(1000))
+	assert.Equal(t, 1001, merged.Size())
+}
+
 func BenchmarkInsert(b *testing.B) {
 	b.ReportAllocs()
 	n := emptyNode(0, 32)
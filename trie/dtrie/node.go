@@ -28,7 +28,6 @@ package dtrie
 
 import (
 	"fmt"
-	"sync"
 
 	"github.com/Workiva/go-datastructures/bitarray"
 )
@@ -38,6 +37,7 @@ type node struct {
 	nodeMap bitarray.Bitmap32
 	dataMap bitarray.Bitmap32
 	level   uint8 // level starts at 0
+	edit    *edit // the transient allowed to modify this node in place
 }
 
 func (n *node) KeyHash() uint32    { return 0 }
@@ -67,57 +67,99 @@ type Entry interface {
 	Value() interface{}
 }
 
+// edit identifies a Transient. It is not zero sized so that distinct edits
+// never compare equal.
+type edit struct{ _ byte }
+
 func emptyNode(level uint8, capacity int) *node {
 	return &node{entries: make([]Entry, capacity), level: level}
 }
 
+// editable returns the node if it may be modified in place by the given
+// edit, or a copy that may be otherwise. A nil edit always copies, which
+// keeps every Dtrie sharing the node unchanged.
+func (n *node) editable(e *edit) *node {
+	if e != nil && n.edit == e {
+		return n
+	}
+	cp := *n
+	cp.entries = make([]Entry, len(n.entries))
+	copy(cp.entries, n.entries)
+	cp.edit = e
+	return &cp
+}
+
 func insert(n *node, entry Entry) *node {
+	return insertEdit(n, entry, nil)
+}
+
+// insertEdit returns a node holding the entries of n and the given entry,
+// replacing any entry with the same key. Nodes along the path are copied
+// unless they belong to the edit.
+func insertEdit(n *node, entry Entry, e *edit) *node {
 	index := uint(mask(entry.KeyHash(), n.level))
-	newNode := n
-	if newNode.level == 6 { // handle hash collisions on 6th level
-		if newNode.entries[index] == nil {
+	if n.level == 6 { // handle hash collisions on 6th level
+		newNode := n.editable(e)
+		switch {
+		case n.entries[index] == nil:
 			newNode.entries[index] = entry
 			newNode.dataMap = newNode.dataMap.SetBit(index)
-			return newNode
-		}
-		if newNode.dataMap.GetBit(index) {
-			if newNode.entries[index].Key() == entry.Key() {
+		case n.dataMap.GetBit(index):
+			if n.entries[index].Key() == entry.Key() {
 				newNode.entries[index] = entry
-				return newNode
+				break
 			}
-			cNode := &collisionNode{entries: make([]Entry, 2)}
-			cNode.entries[0] = newNode.entries[index]
-			cNode.entries[1] = entry
-			newNode.entries[index] = cNode
+			newNode.entries[index] = &collisionNode{entries: []Entry{n.entries[index], entry}}
 			newNode.dataMap = newNode.dataMap.ClearBit(index)
-			return newNode
+		default:
+			collisions := n.entries[index].(*collisionNode).entries
+			entries := make([]Entry, len(collisions), len(collisions)+1)
+			copy(entries, collisions)
+			replaced := false
+			for i, ce := range entries {
+				if ce.Key() == entry.Key() {
+					entries[i] = entry
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				entries = append(entries, entry)
+			}
+			newNode.entries[index] = &collisionNode{entries: entries}
 		}
-		cNode := newNode.entries[index].(*collisionNode)
-		cNode.entries = append(cNode.entries, entry)
 		return newNode
 	}
-	if !newNode.dataMap.GetBit(index) && !newNode.nodeMap.GetBit(index) { // insert directly
+	if !n.dataMap.GetBit(index) && !n.nodeMap.GetBit(index) { // insert directly
+		newNode := n.editable(e)
 		newNode.entries[index] = entry
 		newNode.dataMap = newNode.dataMap.SetBit(index)
 		return newNode
 	}
-	if newNode.nodeMap.GetBit(index) { // insert into sub-node
-		newNode.entries[index] = insert(newNode.entries[index].(*node), entry)
+	if n.nodeMap.GetBit(index) { // insert into sub-node
+		subNode := insertEdit(n.entries[index].(*node), entry, e)
+		if subNode == n.entries[index] {
+			return n
+		}
+		newNode := n.editable(e)
+		newNode.entries[index] = subNode
 		return newNode
 	}
-	if newNode.entries[index].Key() == entry.Key() {
+	newNode := n.editable(e)
+	if n.entries[index].Key() == entry.Key() {
 		newNode.entries[index] = entry
 		return newNode
 	}
 	// create new node with the new and existing entries
 	var subNode *node
-	if newNode.level == 5 { // only 2 bits left at level 6 (4 possible indices)
-		subNode = emptyNode(newNode.level+1, 4)
+	if n.level == 5 { // only 2 bits left at level 6 (4 possible indices)
+		subNode = emptyNode(n.level+1, 4)
 	} else {
-		subNode = emptyNode(newNode.level+1, 32)
+		subNode = emptyNode(n.level+1, 32)
 	}
-	subNode = insert(subNode, newNode.entries[index])
-	subNode = insert(subNode, entry)
+	subNode.edit = e
+	subNode = insertEdit(subNode, n.entries[index], e)
+	subNode = insertEdit(subNode, entry, e)
 	newNode.dataMap = newNode.dataMap.ClearBit(index)
 	newNode.nodeMap = newNode.nodeMap.SetBit(index)
 	newNode.entries[index] = subNode
@@ -128,7 +170,10 @@ func insert(n *node, entry Entry) *node {
 func get(n *node, keyHash uint32, key interface{}) Entry {
 	index := uint(mask(keyHash, n.level))
 	if n.dataMap.GetBit(index) {
-		return n.entries[index]
+		if n.entries[index].Key() == key {
+			return n.entries[index]
+		}
+		return nil
 	}
 	if n.nodeMap.GetBit(index) {
 		return get(n.entries[index].(*node), keyHash, key)
@@ -148,87 +193,133 @@ func get(n *node, keyHash uint32, key interface{}) Entry {
 }
 
 func remove(n *node, keyHash uint32, key interface{}) *node {
+	return removeEdit(n, keyHash, key, nil)
+}
+
+// removeEdit returns a node holding the entries of n without the entry for
+// the given key. If there is no such entry, n itself is returned. Nodes
+// along the path are copied unless they belong to the edit.
+func removeEdit(n *node, keyHash uint32, key interface{}, e *edit) *node {
 	index := uint(mask(keyHash, n.level))
-	newNode := n
 	if n.dataMap.GetBit(index) {
+		if n.entries[index].Key() != key {
+			return n
+		}
+		newNode := n.editable(e)
 		newNode.entries[index] = nil
 		newNode.dataMap = newNode.dataMap.ClearBit(index)
 		return newNode
 	}
 	if n.nodeMap.GetBit(index) {
-		subNode := newNode.entries[index].(*node)
-		subNode = remove(subNode, keyHash, key)
-		// compress if only 1 entry exists in sub-node
-		if subNode.nodeMap.PopCount() == 0 && subNode.dataMap.PopCount() == 1 {
-			var e Entry
-			for i := uint(0); i < 32; i++ {
-				if subNode.dataMap.GetBit(i) {
-					e = subNode.entries[i]
-					break
-				}
-			}
-			newNode.entries[index] = e
+		subNode := removeEdit(n.entries[index].(*node), keyHash, key, e)
+		// a transient edits the sub-node in place, so it has to be checked
+		// for compression even when it is the same node
+		single, empty := subNode.single(), subNode.empty()
+		if subNode == n.entries[index] && single == nil && !empty {
+			return n
+		}
+		newNode := n.editable(e)
+		switch {
+		case empty:
+			newNode.entries[index] = nil
+			newNode.nodeMap = newNode.nodeMap.ClearBit(index)
+		case single != nil:
+			// compress if only 1 entry exists in sub-node
+			newNode.entries[index] = single
 			newNode.nodeMap = newNode.nodeMap.ClearBit(index)
 			newNode.dataMap = newNode.dataMap.SetBit(index)
+		default:
+			newNode.entries[index] = subNode
 		}
-		newNode.entries[index] = subNode
 		return newNode
 	}
-	if n.level == 6 { // delete from collisionNode
-		cNode := newNode.entries[index].(*collisionNode)
-		for i, e := range cNode.entries {
-			if e.Key() == key {
-				cNode.entries = append(cNode.entries[:i], cNode.entries[i+1:]...)
-				break
+	if n.level == 6 && n.entries[index] != nil { // delete from collisionNode
+		collisions := n.entries[index].(*collisionNode).entries
+		for i, ce := range collisions {
+			if ce.Key() != key {
+				continue
 			}
+			newNode := n.editable(e)
+			// compress if only 1 entry remains in collisionNode
+			if len(collisions) == 2 {
+				newNode.entries[index] = collisions[1-i]
+				newNode.dataMap = newNode.dataMap.SetBit(index)
+				return newNode
+			}
+			entries := make([]Entry, 0, len(collisions)-1)
+			entries = append(append(entries, collisions[:i]...), collisions[i+1:]...)
+			newNode.entries[index] = &collisionNode{entries: entries}
+			return newNode
 		}
-		// compress if only 1 entry exists in collisionNode
-		if len(cNode.entries) == 1 {
-			newNode.entries[index] = cNode.entries[0]
-			newNode.dataMap = newNode.dataMap.SetBit(index)
-		}
-		return newNode
 	}
 	return n
 }
 
-func iterate(n *node, stop <-chan struct{}) <-chan Entry {
-	out := make(chan Entry)
-	go func() {
-		defer close(out)
-		pushEntries(n, stop, out)
-	}()
-	return out
+// single returns the only entry held by the node if it holds exactly one
+// entry directly and no sub-nodes or collisions, and nil otherwise.
+func (n *node) single() Entry {
+	var found Entry
+	for i, e := range n.entries {
+		if e == nil {
+			continue
+		}
+		if found != nil || !n.dataMap.GetBit(uint(i)) {
+			return nil
+		}
+		found = e
+	}
+	return found
 }
 
-func pushEntries(n *node, stop <-chan struct{}, out chan Entry) {
-	var wg sync.WaitGroup
+// empty reports whether the node holds no entries at all.
+func (n *node) empty() bool {
+	for _, e := range n.entries {
+		if e != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// walk calls fn for each entry under the node until fn returns false, and
+// reports whether every entry was visited.
+func walk(n *node, fn func(Entry) bool) bool {
 	for i, e := range n.entries {
-		select {
-		case <-stop:
-			return
-		default:
-			index := uint(i)
-			switch {
-			case n.dataMap.GetBit(index):
-				out <- e
-			case n.nodeMap.GetBit(index):
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					pushEntries(e.(*node), stop, out)
-				}()
-				wg.Wait()
-			case n.level == 6 && e != nil:
-				for _, ce := range n.entries[index].(*collisionNode).entries {
-					select {
-					case <-stop:
-						return
-					default:
-						out <- ce
-					}
-				}
+		if !walkSlot(n, uint(i), e, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+func walkSlot(n *node, index uint, e Entry, fn func(Entry) bool) bool {
+	switch {
+	case n.dataMap.GetBit(index):
+		return fn(e)
+	case n.nodeMap.GetBit(index):
+		return walk(e.(*node), fn)
+	case n.level == 6 && e != nil:
+		for _, ce := range e.(*collisionNode).entries {
+			if !fn(ce) {
+				return false
 			}
 		}
 	}
+	return true
+}
+
+func iterate(n *node, stop <-chan struct{}) <-chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		walk(n, func(e Entry) bool {
+			select {
+			case out <- e:
+				return true
+			case <-stop:
+				return false
+			}
+		})
+	}()
+	return out
 }
@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2016, Theodore Butler
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+  list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+  this list of conditions and the following disclaimer in the documentation
+  and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package dtrie
+
+// Set is a persistent set built on a Dtrie, holding its items as keys.
+// Every operation returns a new Set and leaves the receiver unchanged, and
+// related Sets share structure, which makes comparing them cheap.
+type Set struct {
+	trie *Dtrie
+}
+
+// NewSet creates a Set holding the given items, using the given hashing
+// function. If nil is passed in, the default hashing function will be used.
+func NewSet(hasher func(v interface{}) uint32, items ...interface{}) *Set {
+	return (&Set{New(hasher)}).Add(items...)
+}
+
+// Size returns the number of items in the Set.
+func (s *Set) Size() int {
+	return s.trie.Size()
+}
+
+// Contains reports whether the item is in the Set.
+func (s *Set) Contains(item interface{}) bool {
+	return get(s.trie.root, s.trie.hasher(item), item) != nil
+}
+
+// Add returns a Set holding the items of this Set and the given items.
+func (s *Set) Add(items ...interface{}) *Set {
+	if len(items) == 0 {
+		return s
+	}
+	t := s.trie.Transient()
+	for _, item := range items {
+		t.Insert(item, nil)
+	}
+	return &Set{t.Persistent()}
+}
+
+// Remove returns a Set holding the items of this Set except the given items.
+func (s *Set) Remove(items ...interface{}) *Set {
+	if len(items) == 0 {
+		return s
+	}
+	t := s.trie.Transient()
+	for _, item := range items {
+		t.Remove(item)
+	}
+	return &Set{t.Persistent()}
+}
+
+// Items returns the items of the Set in no particular order.
+func (s *Set) Items() []interface{} {
+	var items []interface{}
+	walk(s.trie.root, func(e Entry) bool {
+		items = append(items, e.Key())
+		return true
+	})
+	return items
+}
+
+// Equal reports whether both Sets hold the same items. Both Sets must use
+// the same hasher.
+func (s *Set) Equal(other *Set) bool {
+	return s.trie.Equal(other.trie, nil)
+}
+
+// Diff returns the items only in other and the items only in this Set. Like
+// Dtrie.Diff, its cost is proportional to the differences between related
+// Sets. Both Sets must use the same hasher.
+func (s *Set) Diff(other *Set) (added, removed []interface{}) {
+	delta := s.trie.Diff(other.trie, nil)
+	return keys(delta.Added), keys(delta.Removed)
+}
+
+// Union returns a Set holding the items in either Set. Both Sets must use
+// the same hasher.
+func (s *Set) Union(other *Set) *Set {
+	return &Set{s.trie.Merge(other.trie, nil)}
+}
+
+// Intersection returns a Set holding the items in both Sets. Both Sets must
+// use the same hasher.
+func (s *Set) Intersection(other *Set) *Set {
+	_, removed := s.Diff(other)
+	return s.Remove(removed...)
+}
+
+// Difference returns a Set holding the items of this Set that are not in
+// other. Both Sets must use the same hasher.
+func (s *Set) Difference(other *Set) *Set {
+	_, removed := s.Diff(other)
+	return (&Set{New(s.trie.hasher)}).Add(removed...)
+}
+
+func keys(entries []Entry) []interface{} {
+	items := make([]interface{}, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, e.Key())
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2016, Theodore Butler
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+* Redistributions of source code must retain the above copyright notice, this
+  list of conditions and the following disclaimer.
+
+* Redistributions in binary form must reproduce the above copyright notice,
+  this list of conditions and the following disclaimer in the documentation
+  and/or other materials provided with the distribution.
+
+THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE
+DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE
+FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL
+DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR
+SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER
+CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY,
+OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE
+OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package dtrie
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedInts(items []interface{}) []int {
+	ints := make([]int, 0, len(items))
+	for _, item := range items {
+		ints = append(ints, item.(int))
+	}
+	sort.Ints(ints)
+	return ints
+}
+
+func TestSet(t *testing.T) {
+	s := NewSet(nil, 1, 2, 3)
+	assert.Equal(t, 3, s.Size())
+	assert.True(t, s.Contains(2))
+	assert.False(t, s.Contains(4))
+	assert.Equal(t, []int{1, 2, 3}, sortedInts(s.Items()))
+
+	s2 := s.Add(4, 1).Remove(2)
+	assert.Equal(t, []int{1, 3, 4}, sortedInts(s2.Items()))
+	assert.Equal(t, []int{1, 2, 3}, sortedInts(s.Items()))
+	assert.True(t, s.Add() == s)
+	assert.True(t, s.Remove() == s)
+}
+
+func TestSetAlgebra(t *testing.T) {
+	for _, hasher := range []func(interface{}) uint32{defaultHasher, collisionHash} {
+		a := NewSet(hasher, 1, 2, 3, 4)
+		b := NewSet(hasher, 3, 4, 5)
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, sortedInts(a.Union(b).Items()))
+		assert.Equal(t, []int{3, 4}, sortedInts(a.Intersection(b).Items()))
+		assert.Equal(t, []int{1, 2}, sortedInts(a.Difference(b).Items()))
+		assert.Equal(t, []int{5}, sortedInts(b.Difference(a).Items()))
+
+		added, removed := a.Diff(b)
+		assert.Equal(t, []int{5}, sortedInts(added))
+		assert.Equal(t, []int{1, 2}, sortedInts(removed))
+
+		assert.True(t, a.Equal(NewSet(hasher, 4, 3, 2, 1)))
+		assert.False(t, a.Equal(b))
+	}
+}